@@ -1,6 +1,14 @@
 package notion
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Block represents content on the Notion platform.
 // See: https://developers.notion.com/reference/block
@@ -11,16 +19,42 @@ type Block struct {
 	CreatedTime    *time.Time `json:"created_time,omitempty"`
 	LastEditedTime *time.Time `json:"last_edited_time,omitempty"`
 	HasChildren    bool       `json:"has_children,omitempty"`
+	Archived       bool       `json:"archived,omitempty"`
+
+	Paragraph        *RichTextBlock   `json:"paragraph,omitempty"`
+	Heading1         *Heading         `json:"heading_1,omitempty"`
+	Heading2         *Heading         `json:"heading_2,omitempty"`
+	Heading3         *Heading         `json:"heading_3,omitempty"`
+	BulletedListItem *RichTextBlock   `json:"bulleted_list_item,omitempty"`
+	NumberedListItem *RichTextBlock   `json:"numbered_list_item,omitempty"`
+	ToDo             *ToDo            `json:"to_do,omitempty"`
+	Toggle           *RichTextBlock   `json:"toggle,omitempty"`
+	ChildPage        *ChildPage       `json:"child_page,omitempty"`
+	ChildDatabase    *ChildDatabase   `json:"child_database,omitempty"`
+	Callout          *Callout         `json:"callout,omitempty"`
+	Quote            *RichTextBlock   `json:"quote,omitempty"`
+	Code             *Code            `json:"code,omitempty"`
+	Equation         *Equation        `json:"equation,omitempty"`
+	Divider          *Divider         `json:"divider,omitempty"`
+	Bookmark         *Bookmark        `json:"bookmark,omitempty"`
+	Embed            *Embed           `json:"embed,omitempty"`
+	Image            *FileObject      `json:"image,omitempty"`
+	Video            *FileObject      `json:"video,omitempty"`
+	File             *FileObject      `json:"file,omitempty"`
+	PDF              *FileObject      `json:"pdf,omitempty"`
+	TableOfContents  *TableOfContents `json:"table_of_contents,omitempty"`
+	Breadcrumb       *Breadcrumb      `json:"breadcrumb,omitempty"`
+	ColumnList       *ColumnList      `json:"column_list,omitempty"`
+	Column           *Column          `json:"column,omitempty"`
+	LinkToPage       *LinkToPage      `json:"link_to_page,omitempty"`
+	SyncedBlock      *SyncedBlock     `json:"synced_block,omitempty"`
+	Template         *RichTextBlock   `json:"template,omitempty"`
+	Table            *Table           `json:"table,omitempty"`
+	TableRow         *TableRow        `json:"table_row,omitempty"`
 
-	Paragraph        *RichTextBlock `json:"paragraph,omitempty"`
-	Heading1         *Heading       `json:"heading_1,omitempty"`
-	Heading2         *Heading       `json:"heading_2,omitempty"`
-	Heading3         *Heading       `json:"heading_3,omitempty"`
-	BulletedListItem *RichTextBlock `json:"bulleted_list_item,omitempty"`
-	NumberedListItem *RichTextBlock `json:"numbered_list_item,omitempty"`
-	ToDo             *ToDo          `json:"to_do,omitempty"`
-	Toggle           *RichTextBlock `json:"toggle,omitempty"`
-	ChildPage        *ChildPage     `json:"rich_text,omitempty"`
+	// Children are populated by GetBlockTree. They're not part of JSON
+	// because in the API children are nested in the type-specific object.
+	Children []Block `json:"-"`
 
 	RawJSON []byte `json:"-"`
 }
@@ -39,11 +73,215 @@ type ToDo struct {
 	Checked *bool `json:"checked,omitempty"`
 }
 
+// ChildPage is a sub-page. Its ID is the ID of the block.
 type ChildPage struct {
 	Title string `json:"title"`
 }
 
-type BlockType string
+// ChildDatabase is a database inside a page. Its ID is the ID of the block.
+type ChildDatabase struct {
+	Title string `json:"title"`
+}
+
+type Callout struct {
+	RichTextBlock
+	Icon *Icon `json:"icon,omitempty"`
+}
+
+type Code struct {
+	Text     []RichText `json:"text"`
+	Caption  []RichText `json:"caption,omitempty"`
+	Language string     `json:"language"`
+}
+
+// Since Notion-Version 2022-02-22 text of blocks is in "rich_text" instead
+// of "text". Blocks with text decode both, so that responses of newer
+// versions can be used, and richTextBody renames "text" in request bodies
+// sent with newer versions.
+
+// richTextVersion is the first Notion-Version with "rich_text" in blocks.
+const richTextVersion = "2022-02-22"
+
+// richTextBody returns params encoded as JSON, with "text" of blocks
+// renamed to "rich_text" if version is richTextVersion or later. Text of
+// blocks is the only "text" that is an array, rich text objects and
+// filters have an object.
+func richTextBody(version string, params interface{}) (json.RawMessage, error) {
+	d, err := json.Marshal(params)
+	if err != nil || version < richTextVersion {
+		return d, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(d))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renameText(v))
+}
+
+func renameText(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = renameText(e)
+		}
+		if text, ok := v["text"].([]interface{}); ok {
+			delete(v, "text")
+			v["rich_text"] = text
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = renameText(e)
+		}
+	}
+	return v
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *RichTextBlock) UnmarshalJSON(d []byte) error {
+	type richTextBlock RichTextBlock
+	var dto struct {
+		richTextBlock
+		RichText []RichText `json:"rich_text"`
+	}
+	if err := json.Unmarshal(d, &dto); err != nil {
+		return err
+	}
+	*b = RichTextBlock(dto.richTextBlock)
+	if b.Text == nil {
+		b.Text = dto.RichText
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (h *Heading) UnmarshalJSON(d []byte) error {
+	type heading Heading
+	var dto struct {
+		heading
+		RichText []RichText `json:"rich_text"`
+	}
+	if err := json.Unmarshal(d, &dto); err != nil {
+		return err
+	}
+	*h = Heading(dto.heading)
+	if h.Text == nil {
+		h.Text = dto.RichText
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Code) UnmarshalJSON(d []byte) error {
+	type code Code
+	var dto struct {
+		code
+		RichText []RichText `json:"rich_text"`
+	}
+	if err := json.Unmarshal(d, &dto); err != nil {
+		return err
+	}
+	*c = Code(dto.code)
+	if c.Text == nil {
+		c.Text = dto.RichText
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It's needed because
+// RichTextBlock.UnmarshalJSON would be used for the whole ToDo.
+func (t *ToDo) UnmarshalJSON(d []byte) error {
+	var dto struct {
+		Checked *bool `json:"checked"`
+	}
+	if err := json.Unmarshal(d, &dto); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(d, &t.RichTextBlock); err != nil {
+		return err
+	}
+	t.Checked = dto.Checked
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It's needed because
+// RichTextBlock.UnmarshalJSON would be used for the whole Callout.
+func (c *Callout) UnmarshalJSON(d []byte) error {
+	var dto struct {
+		Icon *Icon `json:"icon"`
+	}
+	if err := json.Unmarshal(d, &dto); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(d, &c.RichTextBlock); err != nil {
+		return err
+	}
+	c.Icon = dto.Icon
+	return nil
+}
+
+type Divider struct{}
+
+type Bookmark struct {
+	URL     string     `json:"url"`
+	Caption []RichText `json:"caption,omitempty"`
+}
+
+type Embed struct {
+	URL     string     `json:"url"`
+	Caption []RichText `json:"caption,omitempty"`
+}
+
+type TableOfContents struct{}
+
+type Breadcrumb struct{}
+
+// ColumnList contains Column blocks as children.
+type ColumnList struct {
+	Children []Block `json:"children,omitempty"`
+}
+
+type Column struct {
+	Children []Block `json:"children,omitempty"`
+}
+
+type LinkToPage struct {
+	Type LinkToPageType `json:"type"`
+
+	// one of those depending on Type
+	PageID     string `json:"page_id,omitempty"`
+	DatabaseID string `json:"database_id,omitempty"`
+}
+
+// SyncedBlock is either an original synced block, in which case SyncedFrom
+// is nil, or a reference to the original block.
+type SyncedBlock struct {
+	SyncedFrom *SyncedFrom `json:"synced_from"`
+	Children   []Block     `json:"children,omitempty"`
+}
+
+type SyncedFrom struct {
+	Type    string `json:"type"`
+	BlockID string `json:"block_id"`
+}
+
+// Table contains TableRow blocks as children.
+type Table struct {
+	TableWidth      int     `json:"table_width"`
+	HasColumnHeader bool    `json:"has_column_header"`
+	HasRowHeader    bool    `json:"has_row_header"`
+	Children        []Block `json:"children,omitempty"`
+}
+
+type TableRow struct {
+	Cells [][]RichText `json:"cells"`
+}
+
+type (
+	BlockType      string
+	LinkToPageType string
+)
 
 const (
 	BlockTypeParagraph        BlockType = "paragraph"
@@ -55,9 +293,216 @@ const (
 	BlockTypeToDo             BlockType = "to_do"
 	BlockTypeToggle           BlockType = "toggle"
 	BlockTypeChildPage        BlockType = "child_page"
+	BlockTypeChildDatabase    BlockType = "child_database"
+	BlockTypeCallout          BlockType = "callout"
+	BlockTypeQuote            BlockType = "quote"
+	BlockTypeCode             BlockType = "code"
+	BlockTypeEquation         BlockType = "equation"
+	BlockTypeDivider          BlockType = "divider"
+	BlockTypeBookmark         BlockType = "bookmark"
+	BlockTypeEmbed            BlockType = "embed"
+	BlockTypeImage            BlockType = "image"
+	BlockTypeVideo            BlockType = "video"
+	BlockTypeFile             BlockType = "file"
+	BlockTypePDF              BlockType = "pdf"
+	BlockTypeTableOfContents  BlockType = "table_of_contents"
+	BlockTypeBreadcrumb       BlockType = "breadcrumb"
+	BlockTypeColumnList       BlockType = "column_list"
+	BlockTypeColumn           BlockType = "column"
+	BlockTypeLinkToPage       BlockType = "link_to_page"
+	BlockTypeSyncedBlock      BlockType = "synced_block"
+	BlockTypeTemplate         BlockType = "template"
+	BlockTypeTable            BlockType = "table"
+	BlockTypeTableRow         BlockType = "table_row"
 	BlockTypeUnsupported      BlockType = "unsupported"
 )
 
+const (
+	LinkToPageTypePage     LinkToPageType = "page_id"
+	LinkToPageTypeDatabase LinkToPageType = "database_id"
+)
+
+// PageLink is a page or a database linked from a block.
+type PageLink struct {
+	ID string
+	// Title is only known for child_page and child_database blocks.
+	Title string
+	// BlockType is the type of the block with the link i.e. child_page,
+	// child_database or link_to_page.
+	BlockType BlockType
+}
+
+// SubPages returns pages linked from the block and its Children, recursively:
+// child_page blocks and link_to_page blocks pointing to a page.
+// Children are populated by GetBlockTree.
+func (b *Block) SubPages() []PageLink {
+	var res []PageLink
+	b.collectLinks(false, map[string]bool{}, &res)
+	return res
+}
+
+// SubDatabases returns databases linked from the block and its Children,
+// recursively: child_database blocks and link_to_page blocks pointing to
+// a database.
+// Children are populated by GetBlockTree.
+func (b *Block) SubDatabases() []PageLink {
+	var res []PageLink
+	b.collectLinks(true, map[string]bool{}, &res)
+	return res
+}
+
+func (b *Block) collectLinks(databases bool, seen map[string]bool, res *[]PageLink) {
+	link := PageLink{BlockType: b.Type}
+	switch b.Type {
+	case BlockTypeChildPage:
+		if !databases {
+			link.ID = b.ID
+			if b.ChildPage != nil {
+				link.Title = b.ChildPage.Title
+			}
+		}
+	case BlockTypeChildDatabase:
+		if databases {
+			link.ID = b.ID
+			if b.ChildDatabase != nil {
+				link.Title = b.ChildDatabase.Title
+			}
+		}
+	case BlockTypeLinkToPage:
+		if b.LinkToPage != nil {
+			link.ID = b.LinkToPage.PageID
+			if databases {
+				link.ID = b.LinkToPage.DatabaseID
+			}
+		}
+	}
+	if link.ID != "" && !seen[link.ID] {
+		seen[link.ID] = true
+		*res = append(*res, link)
+	}
+
+	for i := range b.Children {
+		b.Children[i].collectLinks(databases, seen, res)
+	}
+}
+
+// ChildBlocks returns children of the block: Children, populated by
+// GetBlockTree, or children nested in the type-specific object, which are
+// used when creating blocks.
+func (b *Block) ChildBlocks() []Block {
+	if len(b.Children) > 0 {
+		return b.Children
+	}
+	switch {
+	case b.Paragraph != nil:
+		return b.Paragraph.Children
+	case b.BulletedListItem != nil:
+		return b.BulletedListItem.Children
+	case b.NumberedListItem != nil:
+		return b.NumberedListItem.Children
+	case b.ToDo != nil:
+		return b.ToDo.Children
+	case b.Toggle != nil:
+		return b.Toggle.Children
+	case b.Callout != nil:
+		return b.Callout.Children
+	case b.Quote != nil:
+		return b.Quote.Children
+	case b.Template != nil:
+		return b.Template.Children
+	case b.ColumnList != nil:
+		return b.ColumnList.Children
+	case b.Column != nil:
+		return b.Column.Children
+	case b.SyncedBlock != nil:
+		return b.SyncedBlock.Children
+	case b.Table != nil:
+		return b.Table.Children
+	}
+	return nil
+}
+
+// typedContents returns type-specific objects that are set in the block.
+func (b *Block) typedContents() map[BlockType]interface{} {
+	res := map[BlockType]interface{}{}
+	add := func(typ BlockType, v interface{}) {
+		if !isNil(v) {
+			res[typ] = v
+		}
+	}
+	add(BlockTypeParagraph, b.Paragraph)
+	add(BlockTypeHeading1, b.Heading1)
+	add(BlockTypeHeading2, b.Heading2)
+	add(BlockTypeHeading3, b.Heading3)
+	add(BlockTypeBulletedListItem, b.BulletedListItem)
+	add(BlockTypeNumberedListItem, b.NumberedListItem)
+	add(BlockTypeToDo, b.ToDo)
+	add(BlockTypeToggle, b.Toggle)
+	add(BlockTypeChildPage, b.ChildPage)
+	add(BlockTypeChildDatabase, b.ChildDatabase)
+	add(BlockTypeCallout, b.Callout)
+	add(BlockTypeQuote, b.Quote)
+	add(BlockTypeCode, b.Code)
+	add(BlockTypeEquation, b.Equation)
+	add(BlockTypeDivider, b.Divider)
+	add(BlockTypeBookmark, b.Bookmark)
+	add(BlockTypeEmbed, b.Embed)
+	add(BlockTypeImage, b.Image)
+	add(BlockTypeVideo, b.Video)
+	add(BlockTypeFile, b.File)
+	add(BlockTypePDF, b.PDF)
+	add(BlockTypeTableOfContents, b.TableOfContents)
+	add(BlockTypeBreadcrumb, b.Breadcrumb)
+	add(BlockTypeColumnList, b.ColumnList)
+	add(BlockTypeColumn, b.Column)
+	add(BlockTypeLinkToPage, b.LinkToPage)
+	add(BlockTypeSyncedBlock, b.SyncedBlock)
+	add(BlockTypeTemplate, b.Template)
+	add(BlockTypeTable, b.Table)
+	add(BlockTypeTableRow, b.TableRow)
+	return res
+}
+
+// updateParams returns the body of update block request. Only the
+// object for the block's Type is sent, so the block must have Type set and
+// no objects of other types.
+func (b *Block) updateParams() (map[string]interface{}, error) {
+	if b.Type == "" {
+		return nil, errors.New("block type is required")
+	}
+	contents := b.typedContents()
+	content, ok := contents[b.Type]
+	if !ok {
+		return nil, fmt.Errorf("%s is required for block of type %s", b.Type, b.Type)
+	}
+	if len(contents) > 1 {
+		var others []string
+		for typ := range contents {
+			if typ != b.Type {
+				others = append(others, string(typ))
+			}
+		}
+		sort.Strings(others)
+		return nil, fmt.Errorf("block of type %s can't have %s set", b.Type, strings.Join(others, ", "))
+	}
+
+	// children can only be added with AppendBlockChildren
+	d, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	if json.Unmarshal(d, &m) == nil {
+		if _, ok := m["children"]; ok {
+			return nil, errors.New("children can't be updated, use AppendBlockChildren")
+		}
+	}
+
+	return map[string]interface{}{
+		string(b.Type): content,
+	}, nil
+}
+
 type PaginationQuery struct {
 	StartCursor string
 	PageSize    int
@@ -0,0 +1,211 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+func TestBlockSubPages(t *testing.T) {
+	t.Parallel()
+
+	root := notion.Block{
+		ID:   "root",
+		Type: notion.BlockTypeToggle,
+		Children: []notion.Block{
+			{
+				ID:        "page-1",
+				Type:      notion.BlockTypeChildPage,
+				ChildPage: &notion.ChildPage{Title: "Page 1"},
+			},
+			{
+				ID:            "db-1",
+				Type:          notion.BlockTypeChildDatabase,
+				ChildDatabase: &notion.ChildDatabase{Title: "Database 1"},
+			},
+			{
+				ID:   "column-list",
+				Type: notion.BlockTypeColumnList,
+				Children: []notion.Block{
+					{
+						ID:   "link-1",
+						Type: notion.BlockTypeLinkToPage,
+						LinkToPage: &notion.LinkToPage{
+							Type:   notion.LinkToPageTypePage,
+							PageID: "page-2",
+						},
+					},
+					{
+						ID:   "link-2",
+						Type: notion.BlockTypeLinkToPage,
+						LinkToPage: &notion.LinkToPage{
+							Type:       notion.LinkToPageTypeDatabase,
+							DatabaseID: "db-2",
+						},
+					},
+					{
+						ID:   "link-3",
+						Type: notion.BlockTypeLinkToPage,
+						LinkToPage: &notion.LinkToPage{
+							Type:   notion.LinkToPageTypePage,
+							PageID: "page-1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expPages := []notion.PageLink{
+		{ID: "page-1", Title: "Page 1", BlockType: notion.BlockTypeChildPage},
+		{ID: "page-2", BlockType: notion.BlockTypeLinkToPage},
+	}
+	if diff := cmp.Diff(expPages, root.SubPages()); diff != "" {
+		t.Fatalf("sub-pages not equal (-exp, +got):\n%v", diff)
+	}
+
+	expDatabases := []notion.PageLink{
+		{ID: "db-1", Title: "Database 1", BlockType: notion.BlockTypeChildDatabase},
+		{ID: "db-2", BlockType: notion.BlockTypeLinkToPage},
+	}
+	if diff := cmp.Diff(expDatabases, root.SubDatabases()); diff != "" {
+		t.Fatalf("sub-databases not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestBlockUnmarshalRichText(t *testing.T) {
+	t.Parallel()
+
+	checked := true
+	text := []notion.RichText{{Type: notion.RichTextTypeText, PlainText: "Hi", Text: &notion.Text{Content: "Hi"}}}
+	tests := []struct {
+		name     string
+		json     string
+		expBlock notion.Block
+	}{
+		{
+			name:     "paragraph with text",
+			json:     `{"type": "paragraph", "paragraph": {"text": [{"type": "text", "plain_text": "Hi", "text": {"content": "Hi"}}]}}`,
+			expBlock: notion.Block{Type: notion.BlockTypeParagraph, Paragraph: &notion.RichTextBlock{Text: text}},
+		},
+		{
+			name:     "paragraph with rich_text",
+			json:     `{"type": "paragraph", "paragraph": {"rich_text": [{"type": "text", "plain_text": "Hi", "text": {"content": "Hi"}}]}}`,
+			expBlock: notion.Block{Type: notion.BlockTypeParagraph, Paragraph: &notion.RichTextBlock{Text: text}},
+		},
+		{
+			name:     "heading",
+			json:     `{"type": "heading_1", "heading_1": {"rich_text": [{"type": "text", "plain_text": "Hi", "text": {"content": "Hi"}}]}}`,
+			expBlock: notion.Block{Type: notion.BlockTypeHeading1, Heading1: &notion.Heading{Text: text}},
+		},
+		{
+			name: "to do",
+			json: `{"type": "to_do", "to_do": {"rich_text": [{"type": "text", "plain_text": "Hi", "text": {"content": "Hi"}}], "checked": true}}`,
+			expBlock: notion.Block{Type: notion.BlockTypeToDo, ToDo: &notion.ToDo{
+				RichTextBlock: notion.RichTextBlock{Text: text},
+				Checked:       &checked,
+			}},
+		},
+		{
+			name: "callout",
+			json: `{"type": "callout", "callout": {"rich_text": [{"type": "text", "plain_text": "Hi", "text": {"content": "Hi"}}], "icon": {"type": "emoji", "emoji": "💡"}}}`,
+			expBlock: notion.Block{Type: notion.BlockTypeCallout, Callout: &notion.Callout{
+				RichTextBlock: notion.RichTextBlock{Text: text},
+				Icon:          &notion.Icon{Type: notion.IconTypeEmoji, Emoji: "💡"},
+			}},
+		},
+		{
+			name:     "code",
+			json:     `{"type": "code", "code": {"rich_text": [{"type": "text", "plain_text": "Hi", "text": {"content": "Hi"}}], "language": "go"}}`,
+			expBlock: notion.Block{Type: notion.BlockTypeCode, Code: &notion.Code{Text: text, Language: "go"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var block notion.Block
+			if err := json.Unmarshal([]byte(tt.json), &block); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expBlock, block); diff != "" {
+				t.Fatalf("block not equal (-exp, +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestAppendBlockChildrenRichText(t *testing.T) {
+	t.Parallel()
+
+	var postBody string
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			postBody = string(b)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"object": "block", "id": "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113"}`)),
+			}, nil
+		}},
+	}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{HTTPClient: httpClient})
+
+	text := []notion.RichText{{Type: notion.RichTextTypeText, Text: &notion.Text{Content: "Hi"}}}
+	children := []notion.Block{
+		{
+			Type: notion.BlockTypeToggle,
+			Toggle: &notion.RichTextBlock{
+				Text: text,
+				Children: []notion.Block{
+					{Type: notion.BlockTypeCode, Code: &notion.Code{Text: text, Language: "go"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		version     string
+		expPostBody string
+	}{
+		{
+			name:        "default version",
+			expPostBody: `{"children":[{"object":"","type":"toggle","toggle":{"text":[{"type":"text","text":{"content":"Hi"}}],"children":[{"object":"","type":"code","code":{"text":[{"type":"text","text":{"content":"Hi"}}],"language":"go"}}]}}]}`,
+		},
+		{
+			name:        "2022-02-22",
+			version:     "2022-02-22",
+			expPostBody: `{"children":[{"object":"","type":"toggle","toggle":{"rich_text":[{"type":"text","text":{"content":"Hi"}}],"children":[{"object":"","type":"code","code":{"rich_text":[{"type":"text","text":{"content":"Hi"}}],"language":"go"}}]}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := notion.WithNotionVersion(context.Background(), tt.version)
+		if _, err := client.AppendBlockChildren(ctx, "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113", children); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		var exp, got interface{}
+		if err := json.Unmarshal([]byte(tt.expPostBody), &exp); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(postBody), &got); err != nil {
+			t.Fatalf("%s: invalid post body: %v", tt.name, err)
+		}
+		if diff := cmp.Diff(exp, got); diff != "" {
+			t.Errorf("%s: post body not equal (-exp, +got):\n%v", tt.name, diff)
+		}
+	}
+}
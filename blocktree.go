@@ -0,0 +1,101 @@
+package notion
+
+import (
+	"context"
+	"sync"
+)
+
+// BlockTreeOptions describes options for GetBlockTree.
+type BlockTreeOptions struct {
+	// MaxDepth limits how many levels of children are fetched. 1 means only
+	// direct children of the block. 0 means no limit.
+	MaxDepth int
+	// StopAtChildPages doesn't descend into child_page blocks i.e. only
+	// fetches the content of this page, not its sub-pages.
+	StopAtChildPages bool
+	// Concurrency is the maximum number of blocks whose children are
+	// fetched at the same time. Default is 1.
+	Concurrency int
+}
+
+// GetBlockTree returns children of a block, recursively. All pages of
+// children are fetched and Block.Children is populated for every block
+// that has children.
+func (c *Client) GetBlockTree(ctx context.Context, blockID string, opts *BlockTreeOptions) ([]Block, error) {
+	var o BlockTreeOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+	f := &blockTreeFetcher{
+		c:    c,
+		opts: o,
+		sem:  make(chan struct{}, o.Concurrency),
+	}
+	return f.fetch(ctx, blockID, 1)
+}
+
+type blockTreeFetcher struct {
+	c    *Client
+	opts BlockTreeOptions
+	// sem limits the number of concurrent block children requests
+	sem chan struct{}
+}
+
+func (f *blockTreeFetcher) fetch(ctx context.Context, blockID string, depth int) ([]Block, error) {
+	select {
+	case f.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	blocks, err := f.c.GetBlockChildrenIterator(blockID, nil).All(ctx)
+	<-f.sem
+	if err != nil {
+		return nil, err
+	}
+
+	if f.opts.MaxDepth > 0 && depth >= f.opts.MaxDepth {
+		return blocks, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := range blocks {
+		b := &blocks[i]
+		if !b.HasChildren {
+			continue
+		}
+		if f.opts.StopAtChildPages && b.Type == BlockTypeChildPage {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			children, err := f.fetch(ctx, b.ID, depth+1)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			b.Children = children
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return blocks, nil
+}
@@ -0,0 +1,118 @@
+package notion_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+// blockTreeClient serves a tree of blocks, where tree maps a block ID to
+// "<type>:<id>" of its children. A block has children if it's in tree.
+func blockTreeClient(t *testing.T, tree map[string][]string, mu *sync.Mutex, fetched *[]string) *http.Client {
+	return &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/blocks/"), "/children")
+			mu.Lock()
+			*fetched = append(*fetched, id)
+			mu.Unlock()
+
+			children, ok := tree[id]
+			if !ok {
+				t.Errorf("unexpected request for children of %s", id)
+			}
+			var blocks []string
+			for _, child := range children {
+				parts := strings.SplitN(child, ":", 2)
+				_, hasChildren := tree[parts[1]]
+				blocks = append(blocks, fmt.Sprintf(`{"object": "block", "id": %q, "type": %q, "has_children": %v}`, parts[1], parts[0], hasChildren))
+			}
+			body := fmt.Sprintf(`{"object": "list", "results": [%s], "next_cursor": null, "has_more": false}`, strings.Join(blocks, ","))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		}},
+	}
+}
+
+// treeShape returns a compact representation of a block tree e.g. "a(a1(a11)) b".
+func treeShape(blocks []notion.Block) string {
+	var parts []string
+	for _, b := range blocks {
+		s := b.ID
+		if len(b.Children) > 0 {
+			s += "(" + treeShape(b.Children) + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestGetBlockTree(t *testing.T) {
+	t.Parallel()
+
+	tree := map[string][]string{
+		"root": {"toggle:a", "paragraph:b", "child_page:p"},
+		"a":    {"bulleted_list_item:a1"},
+		"a1":   {"paragraph:a11"},
+		"p":    {"paragraph:p1"},
+	}
+
+	tests := []struct {
+		name       string
+		opts       *notion.BlockTreeOptions
+		expShape   string
+		expFetched int
+	}{
+		{
+			name:       "whole tree",
+			opts:       nil,
+			expShape:   "a(a1(a11)) b p(p1)",
+			expFetched: 4,
+		},
+		{
+			name:       "max depth",
+			opts:       &notion.BlockTreeOptions{MaxDepth: 2},
+			expShape:   "a(a1) b p(p1)",
+			expFetched: 3,
+		},
+		{
+			name:       "stop at child pages",
+			opts:       &notion.BlockTreeOptions{StopAtChildPages: true, Concurrency: 4},
+			expShape:   "a(a1(a11)) b p",
+			expFetched: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var fetched []string
+			opts := notion.ClientOptions{
+				HTTPClient: blockTreeClient(t, tree, &mu, &fetched),
+			}
+			client := notion.NewClient("secret-api-key", &opts)
+			blocks, err := client.GetBlockTree(context.Background(), "root", tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expShape, treeShape(blocks)); diff != "" {
+				t.Fatalf("tree not equal (-exp, +got):\n%v", diff)
+			}
+			if len(fetched) != tt.expFetched {
+				t.Fatalf("expected %d requests, got %d: %v", tt.expFetched, len(fetched), fetched)
+			}
+		})
+	}
+}
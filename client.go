@@ -11,41 +11,103 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
-	baseURL       = "https://api.notion.com/v1"
-	apiVersion    = "2021-05-13"
+	// DefaultBaseURL is the URL of the Notion API.
+	DefaultBaseURL = "https://api.notion.com/v1"
+	// DefaultNotionVersion is the version of the API the response models
+	// are written for.
+	DefaultNotionVersion = "2021-05-13"
+
 	clientVersion = "0.0.0"
 )
 
 // Client is used for HTTP requests to the Notion API.
 type Client struct {
-	apiKey     string
-	httpClient *http.Client
+	apiKey        string
+	httpClient    *http.Client
+	retry         *RetryPolicy
+	limiter       *RateLimiter
+	baseURL       string
+	notionVersion string
+	userAgent     string
+	hooks         []Hooks
+	metrics       Metrics
 }
 
 // ClientOptions describes options when creating client
 type ClientOptions struct {
 	HTTPClient *http.Client
+	// Retry enables retrying requests that failed with a transient error.
+	// When nil, requests are not retried. See DefaultRetryPolicy.
+	Retry *RetryPolicy
+	// RateLimiter, when set, throttles requests. Share the same RateLimiter
+	// between Clients that use the same integration token.
+	RateLimiter *RateLimiter
+	// BaseURL is the URL of the API, including the version path, e.g.
+	// the URL of a proxy or a fake server. Defaults to DefaultBaseURL.
+	BaseURL string
+	// NotionVersion is sent in Notion-Version header. Defaults to
+	// DefaultNotionVersion. Responses of newer versions are decoded too.
+	// Request bodies are in the format of DefaultNotionVersion, except that
+	// text of blocks is sent as "rich_text" since 2022-02-22.
+	// See WithNotionVersion to use a different version for a request.
+	NotionVersion string
+	// UserAgent is appended to the User-Agent header e.g. "my-app/1.0".
+	UserAgent string
+	// Hooks are called for every request, in order.
+	Hooks []Hooks
+	// Metrics receives measurements of requests, retries and rate limit
+	// waits. Defaults to NopMetrics.
+	Metrics Metrics
 }
 
 // NewClient returns a new Client.
 func NewClient(apiKey string, opts *ClientOptions) *Client {
 	c := &Client{
-		apiKey:     apiKey,
-		httpClient: http.DefaultClient,
+		apiKey:        apiKey,
+		httpClient:    http.DefaultClient,
+		baseURL:       DefaultBaseURL,
+		notionVersion: DefaultNotionVersion,
+		userAgent:     "go-notion/" + clientVersion,
+		metrics:       NopMetrics{},
 	}
 
 	if opts != nil {
 		if opts.HTTPClient != nil {
 			c.httpClient = opts.HTTPClient
 		}
+		c.retry = opts.Retry
+		c.limiter = opts.RateLimiter
+		c.hooks = opts.Hooks
+		if opts.Metrics != nil {
+			c.metrics = opts.Metrics
+		}
+		if opts.BaseURL != "" {
+			c.baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+		}
+		if opts.NotionVersion != "" {
+			c.notionVersion = opts.NotionVersion
+		}
+		if opts.UserAgent != "" {
+			c.userAgent += " " + opts.UserAgent
+		}
 	}
 
 	return c
 }
 
+type notionVersionKey struct{}
+
+// WithNotionVersion returns a context that makes requests of a Client
+// send a given Notion-Version header, overriding ClientOptions.NotionVersion.
+func WithNotionVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, notionVersionKey{}, version)
+}
+
 func isNil(v interface{}) bool {
 	if v == nil {
 		return true
@@ -65,15 +127,33 @@ func (c *Client) newRequestJSON(ctx context.Context, method, url string, params
 	return c.newRequest(ctx, method, url, body)
 }
 
+// version returns Notion-Version of requests made with ctx.
+func (c *Client) version(ctx context.Context) string {
+	if v, ok := ctx.Value(notionVersionKey{}).(string); ok && v != "" {
+		return v
+	}
+	return c.notionVersion
+}
+
+// newRequestBlocks is newRequestJSON for params with blocks, which are
+// encoded in the format of the Notion-Version of the request.
+func (c *Client) newRequestBlocks(ctx context.Context, method, url string, params interface{}) (*http.Request, error) {
+	body, err := richTextBody(c.version(ctx), params)
+	if err != nil {
+		return nil, fmt.Errorf("notion: failed to encode body params to JSON: %w", err)
+	}
+	return c.newRequest(ctx, method, url, bytes.NewReader(body))
+}
+
 func (c *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, method, baseURL+url, body)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+url, body)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.apiKey))
-	req.Header.Set("Notion-Version", apiVersion)
-	req.Header.Set("User-Agent", "go-notion/"+clientVersion)
+	req.Header.Set("Notion-Version", c.version(ctx))
+	req.Header.Set("User-Agent", c.userAgent)
 
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -83,26 +163,76 @@ func (c *Client) newRequest(ctx context.Context, method, url string, body io.Rea
 }
 
 func (c *Client) doHTTPAndUnmarshalResponse(req *http.Request, val interface{}, op string) ([]byte, error) {
+	ctx := req.Context()
+	var d []byte
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retryAfter time.Duration
+		d, retryAfter, err = c.doHTTP(req, op, attempt)
+		if err == nil {
+			break
+		}
+		if !c.retry.shouldRetry(err, attempt) {
+			return d, err
+		}
+		delay := c.retry.delay(attempt, retryAfter)
+		c.metrics.ObserveRetry(op, errorCode(err), delay)
+		if werr := sleepCtx(ctx, delay); werr != nil {
+			return d, &retryCanceledError{err: err, ctxErr: werr}
+		}
+		req, err = rewindRequest(req)
+		if err != nil {
+			return d, fmt.Errorf("notion: failed to retry HTTP request: %w", err)
+		}
+	}
+
+	err = json.Unmarshal(d, val)
+	if err != nil {
+		return d, fmt.Errorf("notion: failed to parse HTTP response: %w", err)
+	}
+	return d, nil
+}
+
+// doHTTP makes a single HTTP request. For failed requests it also returns
+// the delay requested by the server in Retry-After header.
+func (c *Client) doHTTP(req *http.Request, op string, attempt int) ([]byte, time.Duration, error) {
+	wait, err := c.limiter.wait(req.Context())
+	if err != nil {
+		return nil, 0, fmt.Errorf("notion: failed to %s: %w", op, err)
+	}
+	if wait > 0 {
+		c.metrics.ObserveRateLimitWait(op, wait)
+	}
+
+	info := &RequestInfo{Op: op, ResourceID: resourceID(req.URL.Path), Attempt: attempt, Request: req}
+	if err := c.beforeRequest(info); err != nil {
+		return nil, 0, fmt.Errorf("notion: failed to %s: %w", op, err)
+	}
+	start := time.Now()
+	resp, d, retryAfter, err := c.send(info.Request, op)
+	latency := time.Since(start)
+	c.metrics.ObserveRequest(op, errorCode(err), latency)
+	c.afterResponse(&ResponseInfo{RequestInfo: *info, Response: resp, Duration: latency, Err: err})
+	return d, retryAfter, err
+}
+
+// send sends a request and reads its response.
+func (c *Client) send(req *http.Request, op string) (*http.Response, []byte, time.Duration, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("notion: failed to make HTTP request: %w", err)
+		return nil, nil, 0, fmt.Errorf("notion: failed to make HTTP request: %w", err)
 	}
 
 	d, err := ioutil.ReadAll(resp.Body)
 	resp.Body.Close()
 	if err != nil {
-		return d, err
+		return resp, d, 0, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return d, fmt.Errorf("notion: failed to %s: %w", op, parseErrorResponseJSON(d))
+		return resp, d, parseRetryAfter(resp.Header), fmt.Errorf("notion: failed to %s: %w", op, parseErrorResponseJSON(d))
 	}
-
-	err = json.Unmarshal(d, val)
-	if err != nil {
-		return d, fmt.Errorf("notion: failed to parse HTTP response: %w", err)
-	}
-	return d, nil
+	return resp, d, 0, nil
 }
 
 // GetDatabase fetches information about a database given its ID.
@@ -123,6 +253,12 @@ func (c *Client) GetDatabase(ctx context.Context, id string) (*Database, error)
 // QueryDatabase returns database contents, with optional filters, sorts and pagination.
 // See: https://developers.notion.com/reference/post-database-query
 func (c *Client) QueryDatabase(ctx context.Context, id string, query *DatabaseQuery) (*DatabaseQueryResponse, error) {
+	if query != nil && query.Filter != nil {
+		if err := query.Filter.Validate(); err != nil {
+			return nil, fmt.Errorf("notion: invalid filter: %w", err)
+		}
+	}
+
 	uri := "/databases/" + id + "/query"
 	req, err := c.newRequestJSON(ctx, http.MethodPost, uri, query)
 	if err != nil {
@@ -134,6 +270,42 @@ func (c *Client) QueryDatabase(ctx context.Context, id string, query *DatabaseQu
 	return &res, err
 }
 
+// CreateDatabase creates a database as a child of a page.
+// See: https://developers.notion.com/reference/create-a-database
+func (c *Client) CreateDatabase(ctx context.Context, params CreateDatabaseParams) (*Database, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("notion: invalid database params: %w", err)
+	}
+
+	uri := "/databases"
+	req, err := c.newRequestJSON(ctx, http.MethodPost, uri, params)
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid request: %w", err)
+	}
+
+	var res Database
+	res.RawJSON, err = c.doHTTPAndUnmarshalResponse(req, &res, "create database")
+	return &res, err
+}
+
+// UpdateDatabase updates the title and properties of a database.
+// See: https://developers.notion.com/reference/update-a-database
+func (c *Client) UpdateDatabase(ctx context.Context, id string, params UpdateDatabaseParams) (*Database, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("notion: invalid database params: %w", err)
+	}
+
+	uri := "/databases/" + id
+	req, err := c.newRequestJSON(ctx, http.MethodPatch, uri, params)
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid request: %w", err)
+	}
+
+	var res Database
+	res.RawJSON, err = c.doHTTPAndUnmarshalResponse(req, &res, "update database")
+	return &res, err
+}
+
 // GetPage fetches information about a page by ID
 // See: https://developers.notion.com/reference/get-page
 func (c *Client) GetPage(ctx context.Context, id string) (*Page, error) {
@@ -156,7 +328,7 @@ func (c *Client) CreatePage(ctx context.Context, params CreatePageParams) (*Page
 	}
 
 	uri := "/pages"
-	req, err := c.newRequestJSON(ctx, http.MethodPost, uri, params)
+	req, err := c.newRequestBlocks(ctx, http.MethodPost, uri, params)
 	if err != nil {
 		return nil, fmt.Errorf("notion: invalid request: %w", err)
 	}
@@ -184,6 +356,55 @@ func (c *Client) UpdatePageProps(ctx context.Context, pageID string, params Upda
 	return &res, err
 }
 
+// GetBlock fetches a block by ID.
+// See: https://developers.notion.com/reference/retrieve-a-block
+func (c *Client) GetBlock(ctx context.Context, blockID string) (*Block, error) {
+	uri := "/blocks/" + blockID
+	req, err := c.newRequest(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid request: %w", err)
+	}
+
+	var res Block
+	res.RawJSON, err = c.doHTTPAndUnmarshalResponse(req, &res, "find block")
+	return &res, err
+}
+
+// UpdateBlock updates content of a block. block.Type must be set and
+// only the object for that type (e.g. block.Paragraph) is sent. Children
+// can't be updated, use AppendBlockChildren to add them.
+// See: https://developers.notion.com/reference/update-a-block
+func (c *Client) UpdateBlock(ctx context.Context, blockID string, block Block) (*Block, error) {
+	params, err := block.updateParams()
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid block params: %w", err)
+	}
+
+	uri := "/blocks/" + blockID
+	req, err := c.newRequestBlocks(ctx, http.MethodPatch, uri, params)
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid request: %w", err)
+	}
+
+	var res Block
+	res.RawJSON, err = c.doHTTPAndUnmarshalResponse(req, &res, "update block")
+	return &res, err
+}
+
+// DeleteBlock archives a block. Archived blocks can be restored from Notion UI.
+// See: https://developers.notion.com/reference/delete-a-block
+func (c *Client) DeleteBlock(ctx context.Context, blockID string) (*Block, error) {
+	uri := "/blocks/" + blockID
+	req, err := c.newRequest(ctx, http.MethodDelete, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid request: %w", err)
+	}
+
+	var res Block
+	res.RawJSON, err = c.doHTTPAndUnmarshalResponse(req, &res, "delete block")
+	return &res, err
+}
+
 // GetBlockChildren returns a list of block children for a given block ID.
 // See: https://developers.notion.com/reference/get-block-children
 func (c *Client) GetBlockChildren(ctx context.Context, blockID string, query *PaginationQuery) (*BlockChildrenResponse, error) {
@@ -217,7 +438,7 @@ func (c *Client) AppendBlockChildren(ctx context.Context, blockID string, childr
 	}
 	dto := PostBody{children}
 	uri := "/blocks/" + blockID + "/children"
-	req, err := c.newRequestJSON(ctx, http.MethodPatch, uri, dto)
+	req, err := c.newRequestBlocks(ctx, http.MethodPatch, uri, dto)
 	if err != nil {
 		return nil, fmt.Errorf("notion: invalid request: %w", err)
 	}
@@ -226,6 +447,35 @@ func (c *Client) AppendBlockChildren(ctx context.Context, blockID string, childr
 	return &res, err
 }
 
+// appendChildrenVersion is the first Notion-Version in which append block
+// children responds with a list of the created blocks.
+const appendChildrenVersion = "2021-08-16"
+
+// AppendBlockChildrenList appends child content (blocks) to an existing
+// block, like AppendBlockChildren, and returns the created blocks. The
+// request is sent with Notion-Version 2021-08-16 if the version of the
+// client is older, because older versions respond with the parent block.
+// See: https://developers.notion.com/reference/patch-block-children
+func (c *Client) AppendBlockChildrenList(ctx context.Context, blockID string, children []Block) ([]Block, error) {
+	if c.version(ctx) < appendChildrenVersion {
+		ctx = WithNotionVersion(ctx, appendChildrenVersion)
+	}
+	type PostBody struct {
+		Children []Block `json:"children"`
+	}
+	dto := PostBody{children}
+	uri := "/blocks/" + blockID + "/children"
+	req, err := c.newRequestBlocks(ctx, http.MethodPatch, uri, dto)
+	if err != nil {
+		return nil, fmt.Errorf("notion: invalid request: %w", err)
+	}
+	var res BlockChildrenResponse
+	if _, err = c.doHTTPAndUnmarshalResponse(req, &res, "append block children"); err != nil {
+		return nil, err
+	}
+	return res.Results, nil
+}
+
 // FindUserByID fetches a user by ID.
 // See: https://developers.notion.com/reference/get-user
 func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
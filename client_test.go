@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/kjk/notion"
 )
 
@@ -23,6 +24,35 @@ func (m *mockRoundtripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	return m.fn(r)
 }
 
+// mockResponse is a response of queueTransport, or an error if err is set.
+type mockResponse struct {
+	status int
+	header http.Header
+	body   string
+	err    error
+}
+
+// queueTransport returns a transport that responds to requests with
+// responses in order. onRequest, if not nil, is called with every request.
+func queueTransport(responses []mockResponse, onRequest func(*http.Request)) http.RoundTripper {
+	return &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+		if onRequest != nil {
+			onRequest(r)
+		}
+		res := responses[0]
+		responses = responses[1:]
+		if res.err != nil {
+			return nil, res.err
+		}
+		return &http.Response{
+			StatusCode: res.status,
+			Status:     http.StatusText(res.status),
+			Header:     res.header,
+			Body:       ioutil.NopCloser(strings.NewReader(res.body)),
+		}, nil
+	}}
+}
+
 func mustParseTime(layout, value string) time.Time {
 	t, err := time.Parse(layout, value)
 	if err != nil {
@@ -35,6 +65,10 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func floatPtr(n float64) *float64 {
+	return &n
+}
+
 func TestFindDatabaseByID(t *testing.T) {
 	t.Parallel()
 
@@ -1038,3 +1072,863 @@ func TestCreatePage(t *testing.T) {
 		})
 	}
 }
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	rateLimitedBody := `{
+		"object": "error",
+		"status": 429,
+		"code": "rate_limited",
+		"message": "slow down"
+	}`
+	okBody := `{
+		"object": "list",
+		"results": [],
+		"next_cursor": null,
+		"has_more": false
+	}`
+	policy := &notion.RetryPolicy{
+		MaxAttempts:    3,
+		BaseDelay:      time.Millisecond,
+		MaxDelay:       5 * time.Millisecond,
+		RetryableCodes: []string{"rate_limited"},
+	}
+
+	tests := []struct {
+		name        string
+		statusCodes []int
+		retryAfter  string
+		expAttempts int
+		expError    error
+	}{
+		{
+			name:        "succeeds after retries",
+			statusCodes: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:  "0",
+			expAttempts: 3,
+		},
+		{
+			name:        "gives up after max attempts",
+			statusCodes: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+			expAttempts: 3,
+			expError:    notion.ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			attempts := 0
+			httpClient := &http.Client{
+				Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+					postBody := make(map[string]interface{})
+					err := json.NewDecoder(r.Body).Decode(&postBody)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if postBody["start_cursor"] != "foo" {
+						t.Errorf("post body not re-sent on attempt %d: %v", attempts+1, postBody)
+					}
+
+					statusCode := tt.statusCodes[attempts]
+					attempts++
+					body := okBody
+					header := http.Header{}
+					if statusCode != http.StatusOK {
+						body = rateLimitedBody
+						if tt.retryAfter != "" {
+							header.Set("Retry-After", tt.retryAfter)
+						}
+					}
+					return &http.Response{
+						StatusCode: statusCode,
+						Status:     http.StatusText(statusCode),
+						Header:     header,
+						Body:       ioutil.NopCloser(strings.NewReader(body)),
+					}, nil
+				}},
+			}
+			opts := notion.ClientOptions{
+				HTTPClient: httpClient,
+				Retry:      policy,
+			}
+			client := notion.NewClient("secret-api-key", &opts)
+			query := &notion.DatabaseQuery{StartCursor: "foo"}
+			_, err := client.QueryDatabase(context.Background(), "00000000-0000-0000-0000-000000000000", query)
+
+			if tt.expError == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expError != nil && !errors.Is(err, tt.expError) {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expError, err)
+			}
+			if attempts != tt.expAttempts {
+				t.Fatalf("attempts not equal (expected: %d, got: %d)", tt.expAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Status:     http.StatusText(http.StatusBadRequest),
+				Body: ioutil.NopCloser(strings.NewReader(`{
+					"object": "error",
+					"status": 400,
+					"code": "validation_error",
+					"message": "foobar"
+				}`)),
+			}, nil
+		}},
+	}
+	opts := notion.ClientOptions{
+		HTTPClient: httpClient,
+		Retry:      notion.DefaultRetryPolicy(),
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	_, err := client.GetPage(context.Background(), "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, notion.ErrValidation) {
+		t.Fatalf("error not equal (expected: %v, got: %v)", notion.ErrValidation, err)
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts not equal (expected: 1, got: %d)", attempts)
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			// cancel while waiting for Retry-After
+			cancel()
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Status:     http.StatusText(http.StatusTooManyRequests),
+				Header:     http.Header{"Retry-After": []string{"60"}},
+				Body: ioutil.NopCloser(strings.NewReader(`{
+					"object": "error",
+					"status": 429,
+					"code": "rate_limited",
+					"message": "slow down"
+				}`)),
+			}, nil
+		}},
+	}
+	opts := notion.ClientOptions{
+		HTTPClient: httpClient,
+		Retry:      notion.DefaultRetryPolicy(),
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	_, err := client.GetPage(ctx, "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error not equal (expected: %v, got: %v)", context.Canceled, err)
+	}
+	// the error that was going to be retried is kept
+	var apiErr *notion.APIError
+	if !errors.Is(err, notion.ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.Message != "slow down" {
+		t.Fatalf("expected rate limited API error, got: %v", err)
+	}
+}
+
+type delayMetrics struct {
+	notion.NopMetrics
+	delays []time.Duration
+}
+
+func (m *delayMetrics) ObserveRetry(op, code string, delay time.Duration) {
+	m.delays = append(m.delays, delay)
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	t.Parallel()
+
+	responses := []mockResponse{
+		{
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": []string{"60"}},
+			body:   `{"object":"error","status":429,"code":"rate_limited","message":"slow down"}`,
+		},
+		{status: http.StatusOK, body: `{"object":"user","id":"be32e790-8292-46df-a248-b784fdf483cf","type":"person"}`},
+	}
+	metrics := &delayMetrics{}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient: &http.Client{Transport: queueTransport(responses, nil)},
+		Retry: &notion.RetryPolicy{
+			MaxAttempts:    2,
+			MaxDelay:       time.Millisecond,
+			RetryableCodes: []string{"rate_limited"},
+		},
+		Metrics: metrics,
+	})
+	if _, err := client.GetUser(context.Background(), "be32e790-8292-46df-a248-b784fdf483cf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(metrics.delays) != 1 || metrics.delays[0] != time.Millisecond {
+		t.Fatalf("expected one retry after 1ms, got: %v", metrics.delays)
+	}
+}
+
+func TestGetBlockChildren(t *testing.T) {
+	t.Parallel()
+
+	respBody := `{
+		"object": "list",
+		"results": [
+			{
+				"object": "block",
+				"id": "callout",
+				"type": "callout",
+				"has_children": false,
+				"callout": {
+					"text": [{"type": "text", "text": {"content": "Note"}, "plain_text": "Note"}],
+					"icon": {"type": "emoji", "emoji": "💡"}
+				}
+			},
+			{
+				"object": "block",
+				"id": "quote",
+				"type": "quote",
+				"quote": {
+					"text": [{"type": "text", "text": {"content": "Quote"}, "plain_text": "Quote"}]
+				}
+			},
+			{
+				"object": "block",
+				"id": "code",
+				"type": "code",
+				"code": {
+					"text": [{"type": "text", "text": {"content": "fmt.Println()"}, "plain_text": "fmt.Println()"}],
+					"caption": [{"type": "text", "text": {"content": "Caption"}, "plain_text": "Caption"}],
+					"language": "go"
+				}
+			},
+			{
+				"object": "block",
+				"id": "equation",
+				"type": "equation",
+				"equation": {"expression": "e=mc^2"}
+			},
+			{
+				"object": "block",
+				"id": "divider",
+				"type": "divider",
+				"divider": {}
+			},
+			{
+				"object": "block",
+				"id": "bookmark",
+				"type": "bookmark",
+				"bookmark": {"url": "https://example.com", "caption": []}
+			},
+			{
+				"object": "block",
+				"id": "embed",
+				"type": "embed",
+				"embed": {"url": "https://example.com/embed"}
+			},
+			{
+				"object": "block",
+				"id": "image",
+				"type": "image",
+				"image": {
+					"type": "file",
+					"file": {"url": "https://s3.example.com/image.png", "expiry_time": "2021-05-20T10:00:00.000Z"},
+					"caption": [{"type": "text", "text": {"content": "Image"}, "plain_text": "Image"}]
+				}
+			},
+			{
+				"object": "block",
+				"id": "video",
+				"type": "video",
+				"video": {"type": "external", "external": {"url": "https://youtube.com/watch?v=1"}}
+			},
+			{
+				"object": "block",
+				"id": "file",
+				"type": "file",
+				"file": {"type": "external", "external": {"url": "https://example.com/file.zip"}}
+			},
+			{
+				"object": "block",
+				"id": "pdf",
+				"type": "pdf",
+				"pdf": {"type": "external", "external": {"url": "https://example.com/doc.pdf"}}
+			},
+			{
+				"object": "block",
+				"id": "table_of_contents",
+				"type": "table_of_contents",
+				"table_of_contents": {}
+			},
+			{
+				"object": "block",
+				"id": "breadcrumb",
+				"type": "breadcrumb",
+				"breadcrumb": {}
+			},
+			{
+				"object": "block",
+				"id": "column_list",
+				"type": "column_list",
+				"has_children": true,
+				"column_list": {}
+			},
+			{
+				"object": "block",
+				"id": "column",
+				"type": "column",
+				"has_children": true,
+				"column": {}
+			},
+			{
+				"object": "block",
+				"id": "link_to_page",
+				"type": "link_to_page",
+				"link_to_page": {"type": "page_id", "page_id": "b0668f48-8d66-4733-9bdb-2f82215707f7"}
+			},
+			{
+				"object": "block",
+				"id": "synced_block",
+				"type": "synced_block",
+				"has_children": true,
+				"synced_block": {"synced_from": {"type": "block_id", "block_id": "original"}}
+			},
+			{
+				"object": "block",
+				"id": "template",
+				"type": "template",
+				"template": {
+					"text": [{"type": "text", "text": {"content": "New item"}, "plain_text": "New item"}]
+				}
+			},
+			{
+				"object": "block",
+				"id": "table",
+				"type": "table",
+				"has_children": true,
+				"table": {"table_width": 2, "has_column_header": true, "has_row_header": false}
+			},
+			{
+				"object": "block",
+				"id": "b0668f48-8d66-4733-9bdb-2f82215707f7",
+				"type": "child_page",
+				"has_children": true,
+				"child_page": {"title": "Sub-page"}
+			},
+			{
+				"object": "block",
+				"id": "668d797c-76fa-4934-9b05-ad288df2d136",
+				"type": "child_database",
+				"child_database": {"title": "Grocery List"}
+			},
+			{
+				"object": "block",
+				"id": "table_row",
+				"type": "table_row",
+				"table_row": {
+					"cells": [
+						[{"type": "text", "text": {"content": "a"}, "plain_text": "a"}],
+						[{"type": "text", "text": {"content": "b"}, "plain_text": "b"}]
+					]
+				}
+			}
+		],
+		"next_cursor": null,
+		"has_more": false
+	}`
+
+	richText := func(s string) []notion.RichText {
+		return []notion.RichText{
+			{
+				Type:      notion.RichTextTypeText,
+				Text:      &notion.Text{Content: s},
+				PlainText: s,
+			},
+		}
+	}
+	expiryTime := mustParseTime(time.RFC3339Nano, "2021-05-20T10:00:00.000Z")
+
+	expResponse := &notion.BlockChildrenResponse{
+		Results: []notion.Block{
+			{
+				Object: "block",
+				ID:     "callout",
+				Type:   notion.BlockTypeCallout,
+				Callout: &notion.Callout{
+					RichTextBlock: notion.RichTextBlock{Text: richText("Note")},
+					Icon:          &notion.Icon{Type: notion.IconTypeEmoji, Emoji: "💡"},
+				},
+			},
+			{
+				Object: "block",
+				ID:     "quote",
+				Type:   notion.BlockTypeQuote,
+				Quote:  &notion.RichTextBlock{Text: richText("Quote")},
+			},
+			{
+				Object: "block",
+				ID:     "code",
+				Type:   notion.BlockTypeCode,
+				Code: &notion.Code{
+					Text:     richText("fmt.Println()"),
+					Caption:  richText("Caption"),
+					Language: "go",
+				},
+			},
+			{
+				Object:   "block",
+				ID:       "equation",
+				Type:     notion.BlockTypeEquation,
+				Equation: &notion.Equation{Expression: "e=mc^2"},
+			},
+			{
+				Object:  "block",
+				ID:      "divider",
+				Type:    notion.BlockTypeDivider,
+				Divider: &notion.Divider{},
+			},
+			{
+				Object:   "block",
+				ID:       "bookmark",
+				Type:     notion.BlockTypeBookmark,
+				Bookmark: &notion.Bookmark{URL: "https://example.com", Caption: []notion.RichText{}},
+			},
+			{
+				Object: "block",
+				ID:     "embed",
+				Type:   notion.BlockTypeEmbed,
+				Embed:  &notion.Embed{URL: "https://example.com/embed"},
+			},
+			{
+				Object: "block",
+				ID:     "image",
+				Type:   notion.BlockTypeImage,
+				Image: &notion.FileObject{
+					Type:    notion.FileTypeFile,
+					File:    &notion.HostedFile{URL: "https://s3.example.com/image.png", ExpiryTime: &expiryTime},
+					Caption: richText("Image"),
+				},
+			},
+			{
+				Object: "block",
+				ID:     "video",
+				Type:   notion.BlockTypeVideo,
+				Video: &notion.FileObject{
+					Type:     notion.FileTypeExternal,
+					External: &notion.ExternalFile{URL: "https://youtube.com/watch?v=1"},
+				},
+			},
+			{
+				Object: "block",
+				ID:     "file",
+				Type:   notion.BlockTypeFile,
+				File: &notion.FileObject{
+					Type:     notion.FileTypeExternal,
+					External: &notion.ExternalFile{URL: "https://example.com/file.zip"},
+				},
+			},
+			{
+				Object: "block",
+				ID:     "pdf",
+				Type:   notion.BlockTypePDF,
+				PDF: &notion.FileObject{
+					Type:     notion.FileTypeExternal,
+					External: &notion.ExternalFile{URL: "https://example.com/doc.pdf"},
+				},
+			},
+			{
+				Object:          "block",
+				ID:              "table_of_contents",
+				Type:            notion.BlockTypeTableOfContents,
+				TableOfContents: &notion.TableOfContents{},
+			},
+			{
+				Object:     "block",
+				ID:         "breadcrumb",
+				Type:       notion.BlockTypeBreadcrumb,
+				Breadcrumb: &notion.Breadcrumb{},
+			},
+			{
+				Object:      "block",
+				ID:          "column_list",
+				Type:        notion.BlockTypeColumnList,
+				HasChildren: true,
+				ColumnList:  &notion.ColumnList{},
+			},
+			{
+				Object:      "block",
+				ID:          "column",
+				Type:        notion.BlockTypeColumn,
+				HasChildren: true,
+				Column:      &notion.Column{},
+			},
+			{
+				Object: "block",
+				ID:     "link_to_page",
+				Type:   notion.BlockTypeLinkToPage,
+				LinkToPage: &notion.LinkToPage{
+					Type:   notion.LinkToPageTypePage,
+					PageID: "b0668f48-8d66-4733-9bdb-2f82215707f7",
+				},
+			},
+			{
+				Object:      "block",
+				ID:          "synced_block",
+				Type:        notion.BlockTypeSyncedBlock,
+				HasChildren: true,
+				SyncedBlock: &notion.SyncedBlock{
+					SyncedFrom: &notion.SyncedFrom{Type: "block_id", BlockID: "original"},
+				},
+			},
+			{
+				Object:   "block",
+				ID:       "template",
+				Type:     notion.BlockTypeTemplate,
+				Template: &notion.RichTextBlock{Text: richText("New item")},
+			},
+			{
+				Object:      "block",
+				ID:          "table",
+				Type:        notion.BlockTypeTable,
+				HasChildren: true,
+				Table:       &notion.Table{TableWidth: 2, HasColumnHeader: true},
+			},
+			{
+				Object:      "block",
+				ID:          "b0668f48-8d66-4733-9bdb-2f82215707f7",
+				Type:        notion.BlockTypeChildPage,
+				HasChildren: true,
+				ChildPage:   &notion.ChildPage{Title: "Sub-page"},
+			},
+			{
+				Object:        "block",
+				ID:            "668d797c-76fa-4934-9b05-ad288df2d136",
+				Type:          notion.BlockTypeChildDatabase,
+				ChildDatabase: &notion.ChildDatabase{Title: "Grocery List"},
+			},
+			{
+				Object: "block",
+				ID:     "table_row",
+				Type:   notion.BlockTypeTableRow,
+				TableRow: &notion.TableRow{
+					Cells: [][]notion.RichText{richText("a"), richText("b")},
+				},
+			},
+		},
+		HasMore:    false,
+		NextCursor: "",
+	}
+
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(respBody)),
+			}, nil
+		}},
+	}
+	opts := notion.ClientOptions{
+		HTTPClient: httpClient,
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	resp, err := client.GetBlockChildren(context.Background(), "00000000-0000-0000-0000-000000000000", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.RawJSON = nil
+
+	if diff := cmp.Diff(expResponse, resp); diff != "" {
+		t.Fatalf("response not equal (-exp, +got):\n%v", diff)
+	}
+
+	// blocks must survive a JSON round-trip
+	for _, b := range resp.Results {
+		d, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("failed to marshal %s block: %v", b.Type, err)
+		}
+		var got notion.Block
+		if err := json.Unmarshal(d, &got); err != nil {
+			t.Fatalf("failed to unmarshal %s block: %v", b.Type, err)
+		}
+		if diff := cmp.Diff(b, got, cmpopts.EquateEmpty()); diff != "" {
+			t.Fatalf("%s block not equal after round-trip (-exp, +got):\n%v", b.Type, diff)
+		}
+	}
+}
+
+func TestUpdateBlock(t *testing.T) {
+	t.Parallel()
+
+	checked := true
+	tests := []struct {
+		name           string
+		block          notion.Block
+		respBody       func(r *http.Request) io.Reader
+		respStatusCode int
+		expPostBody    map[string]interface{}
+		expResponse    *notion.Block
+		expError       error
+	}{
+		{
+			name: "successful response",
+			block: notion.Block{
+				Type: notion.BlockTypeToDo,
+				ToDo: &notion.ToDo{
+					RichTextBlock: notion.RichTextBlock{
+						Text: []notion.RichText{
+							{
+								Text: &notion.Text{
+									Content: "Lorem ipsum",
+								},
+							},
+						},
+					},
+					Checked: &checked,
+				},
+			},
+			respBody: func(_ *http.Request) io.Reader {
+				return strings.NewReader(
+					`{
+						"object": "block",
+						"id": "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113",
+						"type": "to_do",
+						"has_children": false,
+						"to_do": {
+							"text": [
+								{
+									"type": "text",
+									"text": {
+										"content": "Lorem ipsum"
+									},
+									"plain_text": "Lorem ipsum"
+								}
+							],
+							"checked": true
+						}
+					}`,
+				)
+			},
+			respStatusCode: http.StatusOK,
+			expPostBody: map[string]interface{}{
+				"to_do": map[string]interface{}{
+					"text": []interface{}{
+						map[string]interface{}{
+							"text": map[string]interface{}{
+								"content": "Lorem ipsum",
+							},
+						},
+					},
+					"checked": true,
+				},
+			},
+			expResponse: &notion.Block{
+				Object: "block",
+				ID:     "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113",
+				Type:   notion.BlockTypeToDo,
+				ToDo: &notion.ToDo{
+					RichTextBlock: notion.RichTextBlock{
+						Text: []notion.RichText{
+							{
+								Type: notion.RichTextTypeText,
+								Text: &notion.Text{
+									Content: "Lorem ipsum",
+								},
+								PlainText: "Lorem ipsum",
+							},
+						},
+					},
+					Checked: &checked,
+				},
+			},
+			expError: nil,
+		},
+		{
+			name: "block type required error",
+			block: notion.Block{
+				Paragraph: &notion.RichTextBlock{},
+			},
+			expResponse: nil,
+			expError:    errors.New("notion: invalid block params: block type is required"),
+		},
+		{
+			name: "type object required error",
+			block: notion.Block{
+				Type:      notion.BlockTypeHeading1,
+				Paragraph: &notion.RichTextBlock{},
+			},
+			expResponse: nil,
+			expError:    errors.New("notion: invalid block params: heading_1 is required for block of type heading_1"),
+		},
+		{
+			name: "other type objects error",
+			block: notion.Block{
+				Type:      notion.BlockTypeParagraph,
+				Paragraph: &notion.RichTextBlock{},
+				Quote:     &notion.RichTextBlock{},
+				Divider:   &notion.Divider{},
+			},
+			expResponse: nil,
+			expError:    errors.New("notion: invalid block params: block of type paragraph can't have divider, quote set"),
+		},
+		{
+			name: "children error",
+			block: notion.Block{
+				Type: notion.BlockTypeToggle,
+				Toggle: &notion.RichTextBlock{
+					Children: []notion.Block{
+						{
+							Type:      notion.BlockTypeParagraph,
+							Paragraph: &notion.RichTextBlock{},
+						},
+					},
+				},
+			},
+			expResponse: nil,
+			expError:    errors.New("notion: invalid block params: children can't be updated, use AppendBlockChildren"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpClient := &http.Client{
+				Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+					if r.Method != http.MethodPatch {
+						t.Errorf("method not equal (expected: %s, got: %s)", http.MethodPatch, r.Method)
+					}
+
+					postBody := make(map[string]interface{})
+					err := json.NewDecoder(r.Body).Decode(&postBody)
+					if err != nil && err != io.EOF {
+						t.Fatal(err)
+					}
+
+					if diff := cmp.Diff(tt.expPostBody, postBody); diff != "" {
+						t.Errorf("post body not equal (-exp, +got):\n%v", diff)
+					}
+
+					return &http.Response{
+						StatusCode: tt.respStatusCode,
+						Status:     http.StatusText(tt.respStatusCode),
+						Body:       ioutil.NopCloser(tt.respBody(r)),
+					}, nil
+				}},
+			}
+			opts := notion.ClientOptions{
+				HTTPClient: httpClient,
+			}
+			client := notion.NewClient("secret-api-key", &opts)
+			block, err := client.UpdateBlock(context.Background(), "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113", tt.block)
+			if block != nil {
+				block.RawJSON = nil
+			}
+
+			if tt.expError == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expError != nil && err == nil {
+				t.Fatalf("error not equal (expected: %v, got: nil)", tt.expError)
+			}
+			if tt.expError != nil && err != nil && tt.expError.Error() != err.Error() {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expError, err)
+			}
+
+			if diff := cmp.Diff(tt.expResponse, block); diff != "" {
+				t.Fatalf("response not equal (-exp, +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestDeleteBlock(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			if r.Method != http.MethodDelete {
+				t.Errorf("method not equal (expected: %s, got: %s)", http.MethodDelete, r.Method)
+			}
+			if r.URL.Path != "/v1/blocks/ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body: ioutil.NopCloser(strings.NewReader(`{
+					"object": "block",
+					"id": "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113",
+					"type": "divider",
+					"archived": true,
+					"divider": {}
+				}`)),
+			}, nil
+		}},
+	}
+	opts := notion.ClientOptions{
+		HTTPClient: httpClient,
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	block, err := client.DeleteBlock(context.Background(), "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !block.Archived {
+		t.Fatalf("expected archived block")
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	t.Parallel()
+
+	var expVersion string
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			if got := r.URL.String(); got != "https://proxy.example.com/notion/v1/users/be32e790-8292-46df-a248-b784fdf483cf" {
+				t.Errorf("unexpected URL: %s", got)
+			}
+			if got := r.Header.Get("Notion-Version"); got != expVersion {
+				t.Errorf("Notion-Version not equal (expected: %s, got: %s)", expVersion, got)
+			}
+			if got, exp := r.Header.Get("User-Agent"), "go-notion/0.0.0 my-app/1.0"; got != exp {
+				t.Errorf("User-Agent not equal (expected: %s, got: %s)", exp, got)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"object": "user", "id": "be32e790-8292-46df-a248-b784fdf483cf", "type": "bot", "bot": {}}`)),
+			}, nil
+		}},
+	}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient:    httpClient,
+		BaseURL:       "https://proxy.example.com/notion/v1/",
+		NotionVersion: "2021-08-16",
+		UserAgent:     "my-app/1.0",
+	})
+
+	expVersion = "2021-08-16"
+	if _, err := client.GetUser(context.Background(), "be32e790-8292-46df-a248-b784fdf483cf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expVersion = "2022-02-22"
+	ctx := notion.WithNotionVersion(context.Background(), "2022-02-22")
+	if _, err := client.GetUser(ctx, "be32e790-8292-46df-a248-b784fdf483cf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kjk/notion"
+)
+
+type genOptions struct {
+	Package string
+	// TypeName is the name of the generated struct. Defaults to database
+	// title converted to a Go identifier.
+	TypeName string
+}
+
+// property is a database property with the names of Go identifiers
+// generated for it.
+type property struct {
+	name  string
+	ident string
+	prop  notion.DatabaseProperty
+}
+
+// generate returns Go source code with types for pages in a database.
+func generate(db *notion.Database, opts genOptions) ([]byte, error) {
+	title := notion.PlainText(db.Title)
+	typeName := opts.TypeName
+	if typeName == "" {
+		typeName = goIdent(title)
+	}
+	if typeName == "" {
+		return nil, fmt.Errorf("can't derive type name from database title %q, use -type", title)
+	}
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = strings.ToLower(typeName)
+	}
+
+	var props []property
+	idents := map[string]bool{}
+	names := make([]string, 0, len(db.Properties))
+	for name := range db.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ident := uniqueIdent(goIdent(name), idents)
+		props = append(props, property{name: name, ident: ident, prop: db.Properties[name]})
+	}
+
+	g := &generator{typeName: typeName}
+	g.printf("// %sDatabaseID is the ID of the %q database.\n", typeName, title)
+	g.printf("const %sDatabaseID = %q\n\n", typeName, db.ID)
+
+	g.printf("// Names of properties of the %q database.\n", title)
+	g.printf("const (\n")
+	for _, p := range props {
+		g.printf("%sProp%s = %q\n", typeName, p.ident, p.name)
+	}
+	g.printf(")\n\n")
+
+	g.printf("// %s is a page in the %q database.\n", typeName, title)
+	g.printf("type %s struct {\n", typeName)
+	g.printf("ID string `notion:\"-\"`\n\n")
+	for _, p := range props {
+		goType := g.fieldType(p)
+		if goType == "" {
+			g.printf("// %s: %s properties are not supported\n", p.ident, p.prop.Type)
+			continue
+		}
+		if strings.Contains(p.name, "`") {
+			g.printf("// %s: property names with a backtick are not supported\n", p.ident)
+			continue
+		}
+		g.printf("%s %s `notion:\"%s,%s\"`\n", p.ident, goType, escapeTag(p.name), p.prop.Type)
+	}
+	g.printf("}\n\n")
+
+	for _, p := range props {
+		g.selectOptions(p)
+	}
+
+	g.printf("// Unmarshal%s returns page of the %q database as %s.\n", typeName, title, typeName)
+	g.printf("func Unmarshal%s(page *notion.Page) (*%s, error) {\n", typeName, typeName)
+	g.printf("v := &%s{ID: page.ID}\n", typeName)
+	g.printf("if err := notion.UnmarshalPage(page, v); err != nil {\nreturn nil, err\n}\n")
+	g.printf("return v, nil\n}\n\n")
+
+	g.printf("// Query%s queries the %q database and returns all matching pages.\n", typeName, title)
+	g.printf("func Query%s(ctx context.Context, c *notion.Client, query *notion.DatabaseQuery) ([]*%s, error) {\n", typeName, typeName)
+	g.printf("pages, err := c.QueryDatabaseIterator(%sDatabaseID, query).All(ctx)\n", typeName)
+	g.printf("if err != nil {\nreturn nil, err\n}\n")
+	g.printf("res := make([]*%s, len(pages))\n", typeName)
+	g.printf("for i := range pages {\nif res[i], err = Unmarshal%s(&pages[i]); err != nil {\nreturn nil, err\n}\n}\n", typeName)
+	g.printf("return res, nil\n}\n")
+
+	for _, p := range props {
+		g.filters(p)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by notion-gen from database %q. DO NOT EDIT.\n\n", title)
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	src.WriteString("import (\n\"context\"\n")
+	if g.usesTime {
+		src.WriteString("\"time\"\n")
+	}
+	src.WriteString("\n\"github.com/kjk/notion\"\n)\n\n")
+	src.Write(g.buf.Bytes())
+
+	res, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %w", err)
+	}
+	return res, nil
+}
+
+type generator struct {
+	typeName string
+	usesTime bool
+	buf      bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) optionType(p property) string {
+	return g.typeName + p.ident
+}
+
+func (g *generator) fieldType(p property) string {
+	switch p.prop.Type {
+	case notion.DBPropTypeTitle, notion.DBPropTypeRichText,
+		notion.DBPropTypeURL, notion.DBPropTypeEmail, notion.DBPropTypePhoneNumber:
+		return "string"
+	case notion.DBPropTypeNumber:
+		return "float64"
+	case notion.DBPropTypeSelect:
+		return g.optionType(p)
+	case notion.DBPropTypeMultiSelect:
+		return "[]" + g.optionType(p)
+	case notion.DBPropTypeDate, notion.DBPropTypeCreatedTime, notion.DBPropTypeLastEditedTime:
+		g.usesTime = true
+		return "time.Time"
+	case notion.DBPropTypeCheckbox:
+		return "bool"
+	case notion.DBPropTypeRelation, notion.DBPropTypePeople, notion.DBPropTypeFiles:
+		return "[]string"
+	case notion.DBPropTypeCreatedBy, notion.DBPropTypeLastEditedBy:
+		return "string"
+	case notion.DBPropTypeFormula:
+		return "*notion.FormulaProperty"
+	case notion.DBPropTypeRollup:
+		return "*notion.RollupProperty"
+	}
+	return ""
+}
+
+// selectOptions generates a type and constants for options of select and
+// multi_select properties.
+func (g *generator) selectOptions(p property) {
+	var meta *notion.SelectMetadata
+	switch p.prop.Type {
+	case notion.DBPropTypeSelect:
+		meta = p.prop.Select
+	case notion.DBPropTypeMultiSelect:
+		meta = p.prop.MultiSelect
+	default:
+		return
+	}
+	typ := g.optionType(p)
+	g.printf("// %s is an option of the %q property.\n", typ, p.name)
+	g.printf("type %s string\n\n", typ)
+	if meta == nil || len(meta.Options) == 0 {
+		return
+	}
+	idents := map[string]bool{}
+	g.printf("// Options of the %q property.\n", p.name)
+	g.printf("const (\n")
+	for _, o := range meta.Options {
+		ident := uniqueIdent(goIdent(o.Name), idents)
+		g.printf("%s%s %s = %q\n", typ, ident, typ, o.Name)
+	}
+	g.printf(")\n\n")
+}
+
+// filters generates functions returning query filters for a property.
+func (g *generator) filters(p property) {
+	prefix := g.typeName + "Filter" + p.ident
+	prop := g.typeName + "Prop" + p.ident
+	name := commentText(p.name)
+	filter := func(doc, name, params, body string) {
+		g.printf("\n// %s%s %s\n", prefix, name, doc)
+		g.printf("func %s%s(%s) notion.FilterExpr {\n", prefix, name, params)
+		g.printf("return notion.Filter.Prop(%s).%s\n}\n", prop, body)
+	}
+	switch p.prop.Type {
+	case notion.DBPropTypeTitle, notion.DBPropTypeRichText,
+		notion.DBPropTypeURL, notion.DBPropTypeEmail, notion.DBPropTypePhoneNumber:
+		filter("matches pages where "+name+" is equal to s.", "Equals", "s string", "Text().Equals(s)")
+		filter("matches pages where "+name+" contains s.", "Contains", "s string", "Text().Contains(s)")
+	case notion.DBPropTypeNumber:
+		filter("matches pages where "+name+" is greater than n.", "GreaterThan", "n float64", "Number().GreaterThan(n)")
+		filter("matches pages where "+name+" is less than n.", "LessThan", "n float64", "Number().LessThan(n)")
+	case notion.DBPropTypeSelect:
+		filter("matches pages where "+name+" is v.", "Equals", "v "+g.optionType(p), "Select().Equals(string(v))")
+	case notion.DBPropTypeMultiSelect:
+		filter("matches pages where "+name+" contains v.", "Contains", "v "+g.optionType(p), "MultiSelect().Contains(string(v))")
+	case notion.DBPropTypeCheckbox:
+		filter("matches pages where "+name+" is v.", "Equals", "v bool", "Checkbox().Equals(v)")
+	case notion.DBPropTypeDate:
+		filter("matches pages where "+name+" is before t.", "Before", "t time.Time", "Date().Before(t)")
+		filter("matches pages where "+name+" is after t.", "After", "t time.Time", "Date().After(t)")
+	case notion.DBPropTypeCreatedTime:
+		filter("matches pages where "+name+" is before t.", "Before", "t time.Time", "CreatedTime().Before(t)")
+		filter("matches pages where "+name+" is after t.", "After", "t time.Time", "CreatedTime().After(t)")
+	case notion.DBPropTypeLastEditedTime:
+		filter("matches pages where "+name+" is before t.", "Before", "t time.Time", "LastEditedTime().Before(t)")
+		filter("matches pages where "+name+" is after t.", "After", "t time.Time", "LastEditedTime().After(t)")
+	case notion.DBPropTypeRelation:
+		filter("matches pages where "+name+" contains page with a given ID.", "Contains", "id string", "Relation().Contains(id)")
+	case notion.DBPropTypePeople:
+		filter("matches pages where "+name+" contains user with a given ID.", "Contains", "id string", "People().Contains(id)")
+	}
+}
+
+// goIdent converts a name like "due date" to an exported Go identifier
+// like "DueDate".
+func goIdent(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, w := range words {
+		switch strings.ToLower(w) {
+		case "id", "url", "api", "http", "html", "json":
+			sb.WriteString(strings.ToUpper(w))
+			continue
+		}
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	s := sb.String()
+	if s != "" && !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// uniqueIdent returns ident or ident with a number suffix if it's already
+// in seen.
+func uniqueIdent(ident string, seen map[string]bool) string {
+	if ident == "" {
+		ident = "X"
+	}
+	res := ident
+	for n := 2; seen[res]; n++ {
+		res = fmt.Sprintf("%s%d", ident, n)
+	}
+	seen[res] = true
+	return res
+}
+
+// escapeTag escapes characters that can't appear in a struct tag value.
+func escapeTag(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s)
+}
+
+// commentText returns s with runs of whitespace, including newlines that
+// would end a comment, replaced with a single space.
+func commentText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
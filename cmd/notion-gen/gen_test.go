@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	d, err := ioutil.ReadFile(filepath.Join("testdata", "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var db notion.Database
+	if err := json.Unmarshal(d, &db); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate(&db, genOptions{TypeName: "Task", Package: "tasks"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "tasks", "tasks.go")
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(exp), string(got)); diff != "" {
+		t.Fatalf("generated code not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestGenerateNewlineInName(t *testing.T) {
+	t.Parallel()
+
+	db := &notion.Database{
+		ID:    "668d797c-76fa-4934-9b05-ad288df2d136",
+		Title: []notion.RichText{{Type: notion.RichTextTypeText, PlainText: "Tasks\nand bugs"}},
+		Properties: notion.DatabaseProperties{
+			"Name":      {Type: notion.DBPropTypeTitle},
+			"Due\ndate": {Type: notion.DBPropTypeDate},
+		},
+	}
+	got, err := generate(db, genOptions{TypeName: "Task", Package: "tasks"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, exp := range []string{
+		"DueDate time.Time `notion:\"Due\\ndate,date\"`",
+		"// TaskFilterDueDateBefore matches pages where Due date is before t.",
+	} {
+		if !strings.Contains(string(got), exp) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", exp, got)
+		}
+	}
+}
+
+func TestGoIdent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		exp  string
+	}{
+		{name: "Name", exp: "Name"},
+		{name: "due date", exp: "DueDate"},
+		{name: "Estimate (days)", exp: "EstimateDays"},
+		{name: "Spec URL", exp: "SpecURL"},
+		{name: "user_id", exp: "UserID"},
+		{name: "2nd reviewer", exp: "X2ndReviewer"},
+		{name: "żółw", exp: "Żółw"},
+		{name: "???", exp: ""},
+	}
+	for _, tt := range tests {
+		if got := goIdent(tt.name); got != tt.exp {
+			t.Errorf("goIdent(%q): expected %q, got %q", tt.name, tt.exp, got)
+		}
+	}
+}
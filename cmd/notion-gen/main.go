@@ -0,0 +1,108 @@
+// Command notion-gen generates Go types for pages in a Notion database.
+//
+// It fetches the database schema and writes a Go file with:
+//   - a struct with a field for each property, tagged for notion.UnmarshalPage
+//     and notion.MarshalProperties
+//   - constants for options of select and multi_select properties
+//   - functions to query the database and build filters for its properties
+//
+// Usage:
+//
+//	notion-gen -db <database id> -o tasks.go
+//
+// The API key is read from NOTION_API_KEY environment variable or -api-key.
+// With -check, the file is not written and notion-gen exits with an error if
+// the file is not up to date, e.g. a property was renamed or removed. Run it
+// in CI to catch schema drift.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/kjk/notion"
+)
+
+func main() {
+	var (
+		flgAPIKey  string
+		flgDB      string
+		flgSchema  string
+		flgOut     string
+		flgPackage string
+		flgType    string
+		flgCheck   bool
+	)
+	{
+		flag.StringVar(&flgAPIKey, "api-key", os.Getenv("NOTION_API_KEY"), "api key for authentication (default $NOTION_API_KEY)")
+		flag.StringVar(&flgDB, "db", "", "id of the database")
+		flag.StringVar(&flgSchema, "schema", "", "read database from a JSON file (as returned by the API) instead of fetching it")
+		flag.StringVar(&flgOut, "o", "", "output file (default stdout)")
+		flag.StringVar(&flgPackage, "pkg", "", "package name (default lowercase type name)")
+		flag.StringVar(&flgType, "type", "", "name of the generated struct (default derived from database title)")
+		flag.BoolVar(&flgCheck, "check", false, "check that the output file is up to date instead of writing it")
+		flag.Parse()
+	}
+
+	if err := run(flgAPIKey, flgDB, flgSchema, flgOut, flgCheck, genOptions{Package: flgPackage, TypeName: flgType}); err != nil {
+		fmt.Fprintf(os.Stderr, "notion-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(apiKey, dbID, schema, out string, check bool, opts genOptions) error {
+	db, err := loadDatabase(apiKey, dbID, schema)
+	if err != nil {
+		return err
+	}
+	src, err := generate(db, opts)
+	if err != nil {
+		return err
+	}
+
+	if check {
+		if out == "" {
+			return fmt.Errorf("-check requires -o")
+		}
+		existing, err := ioutil.ReadFile(out)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(existing, src) {
+			return fmt.Errorf("%s is out of date with the schema of database %s, regenerate it", out, db.ID)
+		}
+		return nil
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
+
+func loadDatabase(apiKey, dbID, schema string) (*notion.Database, error) {
+	if schema != "" {
+		d, err := ioutil.ReadFile(schema)
+		if err != nil {
+			return nil, err
+		}
+		var db notion.Database
+		if err := json.Unmarshal(d, &db); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", schema, err)
+		}
+		return &db, nil
+	}
+	if dbID == "" {
+		return nil, fmt.Errorf("-db or -schema is required")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("api key is required, set NOTION_API_KEY or use -api-key")
+	}
+	c := notion.NewClient(apiKey, nil)
+	return c.GetDatabase(context.Background(), dbID)
+}
@@ -0,0 +1,165 @@
+// Code generated by notion-gen from database "Tasks". DO NOT EDIT.
+
+package tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/kjk/notion"
+)
+
+// TaskDatabaseID is the ID of the "Tasks" database.
+const TaskDatabaseID = "39ddf6b8-e8a0-4d2a-98d4-de7f2f0e5b38"
+
+// Names of properties of the "Tasks" database.
+const (
+	TaskPropBlockedBy    = "Blocked by"
+	TaskPropBlockersLeft = "Blockers left"
+	TaskPropCreated      = "Created"
+	TaskPropDaysLeft     = "Days left"
+	TaskPropDone         = "Done"
+	TaskPropDueDate      = "Due date"
+	TaskPropEstimateDays = "Estimate (days)"
+	TaskPropName         = "Name"
+	TaskPropOwner        = "Owner"
+	TaskPropSpecURL      = "Spec URL"
+	TaskPropStatus       = "Status"
+	TaskPropTags         = "Tags"
+)
+
+// Task is a page in the "Tasks" database.
+type Task struct {
+	ID string `notion:"-"`
+
+	BlockedBy    []string                `notion:"Blocked by,relation"`
+	BlockersLeft *notion.RollupProperty  `notion:"Blockers left,rollup"`
+	Created      time.Time               `notion:"Created,created_time"`
+	DaysLeft     *notion.FormulaProperty `notion:"Days left,formula"`
+	Done         bool                    `notion:"Done,checkbox"`
+	DueDate      time.Time               `notion:"Due date,date"`
+	EstimateDays float64                 `notion:"Estimate (days),number"`
+	Name         string                  `notion:"Name,title"`
+	Owner        []string                `notion:"Owner,people"`
+	SpecURL      string                  `notion:"Spec URL,url"`
+	Status       TaskStatus              `notion:"Status,select"`
+	Tags         []TaskTags              `notion:"Tags,multi_select"`
+}
+
+// TaskStatus is an option of the "Status" property.
+type TaskStatus string
+
+// Options of the "Status" property.
+const (
+	TaskStatusNotStarted TaskStatus = "Not started"
+	TaskStatusInProgress TaskStatus = "In progress"
+	TaskStatusDone       TaskStatus = "Done"
+)
+
+// TaskTags is an option of the "Tags" property.
+type TaskTags string
+
+// Options of the "Tags" property.
+const (
+	TaskTagsBackend  TaskTags = "backend"
+	TaskTagsFrontEnd TaskTags = "front-end"
+)
+
+// UnmarshalTask returns page of the "Tasks" database as Task.
+func UnmarshalTask(page *notion.Page) (*Task, error) {
+	v := &Task{ID: page.ID}
+	if err := notion.UnmarshalPage(page, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// QueryTask queries the "Tasks" database and returns all matching pages.
+func QueryTask(ctx context.Context, c *notion.Client, query *notion.DatabaseQuery) ([]*Task, error) {
+	pages, err := c.QueryDatabaseIterator(TaskDatabaseID, query).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Task, len(pages))
+	for i := range pages {
+		if res[i], err = UnmarshalTask(&pages[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// TaskFilterBlockedByContains matches pages where Blocked by contains page with a given ID.
+func TaskFilterBlockedByContains(id string) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropBlockedBy).Relation().Contains(id)
+}
+
+// TaskFilterCreatedBefore matches pages where Created is before t.
+func TaskFilterCreatedBefore(t time.Time) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropCreated).CreatedTime().Before(t)
+}
+
+// TaskFilterCreatedAfter matches pages where Created is after t.
+func TaskFilterCreatedAfter(t time.Time) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropCreated).CreatedTime().After(t)
+}
+
+// TaskFilterDoneEquals matches pages where Done is v.
+func TaskFilterDoneEquals(v bool) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropDone).Checkbox().Equals(v)
+}
+
+// TaskFilterDueDateBefore matches pages where Due date is before t.
+func TaskFilterDueDateBefore(t time.Time) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropDueDate).Date().Before(t)
+}
+
+// TaskFilterDueDateAfter matches pages where Due date is after t.
+func TaskFilterDueDateAfter(t time.Time) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropDueDate).Date().After(t)
+}
+
+// TaskFilterEstimateDaysGreaterThan matches pages where Estimate (days) is greater than n.
+func TaskFilterEstimateDaysGreaterThan(n float64) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropEstimateDays).Number().GreaterThan(n)
+}
+
+// TaskFilterEstimateDaysLessThan matches pages where Estimate (days) is less than n.
+func TaskFilterEstimateDaysLessThan(n float64) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropEstimateDays).Number().LessThan(n)
+}
+
+// TaskFilterNameEquals matches pages where Name is equal to s.
+func TaskFilterNameEquals(s string) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropName).Text().Equals(s)
+}
+
+// TaskFilterNameContains matches pages where Name contains s.
+func TaskFilterNameContains(s string) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropName).Text().Contains(s)
+}
+
+// TaskFilterOwnerContains matches pages where Owner contains user with a given ID.
+func TaskFilterOwnerContains(id string) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropOwner).People().Contains(id)
+}
+
+// TaskFilterSpecURLEquals matches pages where Spec URL is equal to s.
+func TaskFilterSpecURLEquals(s string) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropSpecURL).Text().Equals(s)
+}
+
+// TaskFilterSpecURLContains matches pages where Spec URL contains s.
+func TaskFilterSpecURLContains(s string) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropSpecURL).Text().Contains(s)
+}
+
+// TaskFilterStatusEquals matches pages where Status is v.
+func TaskFilterStatusEquals(v TaskStatus) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropStatus).Select().Equals(string(v))
+}
+
+// TaskFilterTagsContains matches pages where Tags contains v.
+func TaskFilterTagsContains(v TaskTags) notion.FilterExpr {
+	return notion.Filter.Prop(TaskPropTags).MultiSelect().Contains(string(v))
+}
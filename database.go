@@ -1,6 +1,11 @@
 package notion
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -51,7 +56,10 @@ type SelectOptions struct {
 }
 
 type DatabaseProperty struct {
-	ID   string               `json:"id"`
+	ID string `json:"id"`
+	// Name is the name of the property. When updating a database, set it to
+	// rename a property.
+	Name string               `json:"name,omitempty"`
 	Type DatabasePropertyType `json:"type"`
 
 	Number      *NumberMetadata   `json:"number,omitempty"`
@@ -82,7 +90,11 @@ type DatabaseQueryResponse struct {
 // DatabaseQueryFilter is used to filter database contents.
 // See: https://developers.notion.com/reference/post-database-query#post-database-query-filter
 type DatabaseQueryFilter struct {
-	Property string `json:"property,omitempty"`
+	// Property is the name of a filtered property. Timestamp filters set
+	// Timestamp instead, with a matching CreatedTime or LastEditedTime
+	// condition.
+	Property  string        `json:"property,omitempty"`
+	Timestamp SortTimestamp `json:"timestamp,omitempty"`
 
 	Text        *TextDatabaseQueryFilter        `json:"text,omitempty"`
 	Number      *NumberDatabaseQueryFilter      `json:"number,omitempty"`
@@ -93,6 +105,11 @@ type DatabaseQueryFilter struct {
 	People      *PeopleDatabaseQueryFilter      `json:"people,omitempty"`
 	Files       *FilesDatabaseQueryFilter       `json:"files,omitempty"`
 	Relation    *RelationDatabaseQueryFilter    `json:"relation,omitempty"`
+	Formula     *FormulaDatabaseQueryFilter     `json:"formula,omitempty"`
+	Rollup      *RollupDatabaseQueryFilter      `json:"rollup,omitempty"`
+
+	CreatedTime    *DateDatabaseQueryFilter `json:"created_time,omitempty"`
+	LastEditedTime *DateDatabaseQueryFilter `json:"last_edited_time,omitempty"`
 
 	Or  []DatabaseQueryFilter `json:"or,omitempty"`
 	And []DatabaseQueryFilter `json:"and,omitempty"`
@@ -110,14 +127,14 @@ type TextDatabaseQueryFilter struct {
 }
 
 type NumberDatabaseQueryFilter struct {
-	Equals               *int `json:"equals,omitempty"`
-	DoesNotEqual         *int `json:"does_not_equal,omitempty"`
-	GreaterThan          *int `json:"greater_than,omitempty"`
-	LessThan             *int `json:"less_than,omitempty"`
-	GreaterThanOrEqualTo *int `json:"greater_than_or_equal_to,omitempty"`
-	LessThanOrEqualTo    *int `json:"less_than_or_equal_to,omitempty"`
-	IsEmpty              bool `json:"is_empty,omitempty"`
-	IsNotEmpty           bool `json:"is_not_empty,omitempty"`
+	Equals               *float64 `json:"equals,omitempty"`
+	DoesNotEqual         *float64 `json:"does_not_equal,omitempty"`
+	GreaterThan          *float64 `json:"greater_than,omitempty"`
+	LessThan             *float64 `json:"less_than,omitempty"`
+	GreaterThanOrEqualTo *float64 `json:"greater_than_or_equal_to,omitempty"`
+	LessThanOrEqualTo    *float64 `json:"less_than_or_equal_to,omitempty"`
+	IsEmpty              bool     `json:"is_empty,omitempty"`
+	IsNotEmpty           bool     `json:"is_not_empty,omitempty"`
 }
 
 type CheckboxDatabaseQueryFilter struct {
@@ -174,11 +191,26 @@ type RelationDatabaseQueryFilter struct {
 	IsNotEmpty     bool   `json:"is_not_empty,omitempty"`
 }
 
+// FormulaDatabaseQueryFilter filters by the result of a formula. Set one
+// condition, matching the type of formula result.
 type FormulaDatabaseQueryFilter struct {
-	Text     TextDatabaseQueryFilter     `json:"text,omitempty"`
-	Checkbox CheckboxDatabaseQueryFilter `json:"checkbox,omitempty"`
-	Number   NumberDatabaseQueryFilter   `json:"number,omitempty"`
-	Date     DateDatabaseQueryFilter     `json:"date,omitempty"`
+	Text     *TextDatabaseQueryFilter     `json:"text,omitempty"`
+	Checkbox *CheckboxDatabaseQueryFilter `json:"checkbox,omitempty"`
+	Number   *NumberDatabaseQueryFilter   `json:"number,omitempty"`
+	Date     *DateDatabaseQueryFilter     `json:"date,omitempty"`
+}
+
+// RollupDatabaseQueryFilter filters by the value of a rollup. Any, Every and
+// None match rollups of arrays where any, every or none of the items match
+// a condition. The condition is a DatabaseQueryFilter without Property,
+// e.g. {Select: &SelectDatabaseQueryFilter{Equals: "Done"}}.
+// Number and Date match rollups calculated to a number or a date.
+type RollupDatabaseQueryFilter struct {
+	Any    *DatabaseQueryFilter       `json:"any,omitempty"`
+	Every  *DatabaseQueryFilter       `json:"every,omitempty"`
+	None   *DatabaseQueryFilter       `json:"none,omitempty"`
+	Number *NumberDatabaseQueryFilter `json:"number,omitempty"`
+	Date   *DateDatabaseQueryFilter   `json:"date,omitempty"`
 }
 
 type DatabaseQuerySort struct {
@@ -203,7 +235,7 @@ const (
 	DBPropTypeMultiSelect    DatabasePropertyType = "multi_select"
 	DBPropTypeDate           DatabasePropertyType = "date"
 	DBPropTypePeople         DatabasePropertyType = "people"
-	DBPropTypeFile           DatabasePropertyType = "file"
+	DBPropTypeFiles          DatabasePropertyType = "files"
 	DBPropTypeCheckbox       DatabasePropertyType = "checkbox"
 	DBPropTypeURL            DatabasePropertyType = "url"
 	DBPropTypeEmail          DatabasePropertyType = "email"
@@ -216,6 +248,9 @@ const (
 	DBPropTypeLastEditedTime DatabasePropertyType = "last_edited_time"
 	DBPropTypeLastEditedBy   DatabasePropertyType = "last_edited_by"
 
+	// Deprecated: Notion uses "files" for files properties, use DBPropTypeFiles.
+	DBPropTypeFile DatabasePropertyType = "file"
+
 	// Number format enums.
 	NumberFormatNumber           NumberFormat = "number"
 	NumberFormatNumberWithCommas NumberFormat = "number_with_commas"
@@ -223,11 +258,41 @@ const (
 	NumberFormatDollar           NumberFormat = "dollar"
 	NumberFormatEuro             NumberFormat = "euro"
 	NumberFormatPound            NumberFormat = "pound"
-	NumberFormatPonud            NumberFormat = "yen"
+	NumberFormatYen              NumberFormat = "yen"
 	NumberFormatRuble            NumberFormat = "ruble"
 	NumberFormatRupee            NumberFormat = "rupee"
 	NumberFormatWon              NumberFormat = "won"
-	NumberformatYuan             NumberFormat = "yuan"
+	NumberFormatYuan             NumberFormat = "yuan"
+	NumberFormatCanadianDollar   NumberFormat = "canadian_dollar"
+	NumberFormatReal             NumberFormat = "real"
+	NumberFormatLira             NumberFormat = "lira"
+	NumberFormatRupiah           NumberFormat = "rupiah"
+	NumberFormatFranc            NumberFormat = "franc"
+	NumberFormatHongKongDollar   NumberFormat = "hong_kong_dollar"
+	NumberFormatNewZealandDollar NumberFormat = "new_zealand_dollar"
+	NumberFormatKrona            NumberFormat = "krona"
+	NumberFormatNorwegianKrone   NumberFormat = "norwegian_krone"
+	NumberFormatMexicanPeso      NumberFormat = "mexican_peso"
+	NumberFormatRand             NumberFormat = "rand"
+	NumberFormatNewTaiwanDollar  NumberFormat = "new_taiwan_dollar"
+	NumberFormatDanishKrone      NumberFormat = "danish_krone"
+	NumberFormatZloty            NumberFormat = "zloty"
+	NumberFormatBaht             NumberFormat = "baht"
+	NumberFormatForint           NumberFormat = "forint"
+	NumberFormatKoruna           NumberFormat = "koruna"
+	NumberFormatShekel           NumberFormat = "shekel"
+	NumberFormatChileanPeso      NumberFormat = "chilean_peso"
+	NumberFormatPhilippinePeso   NumberFormat = "philippine_peso"
+	NumberFormatDirham           NumberFormat = "dirham"
+	NumberFormatColombianPeso    NumberFormat = "colombian_peso"
+	NumberFormatRiyal            NumberFormat = "riyal"
+	NumberFormatRinggit          NumberFormat = "ringgit"
+	NumberFormatLeu              NumberFormat = "leu"
+
+	// Deprecated: misspelled, use NumberFormatYen.
+	NumberFormatPonud = NumberFormatYen
+	// Deprecated: misspelled, use NumberFormatYuan.
+	NumberformatYuan = NumberFormatYuan
 
 	// Sort timestamp enums.
 	SortTimeStampCreatedTime    SortTimestamp = "created_time"
@@ -258,3 +323,239 @@ func (prop DatabaseProperty) Metadata() interface{} {
 		return nil
 	}
 }
+
+// MarshalJSON implements json.Marshaler. The object for the property type is
+// always included, e.g. "title": {}, as required when creating and updating
+// databases. Properties without Type are encoded as {"name": Name}, which
+// renames a property when updating a database.
+func (prop DatabaseProperty) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	if prop.ID != "" {
+		m["id"] = prop.ID
+	}
+	if prop.Name != "" {
+		m["name"] = prop.Name
+	}
+	if prop.Type != "" {
+		m["type"] = prop.Type
+		meta := prop.Metadata()
+		if isNil(meta) {
+			meta = struct{}{}
+		}
+		m[string(prop.Type)] = meta
+	}
+	return json.Marshal(m)
+}
+
+var numberFormats = map[NumberFormat]bool{
+	NumberFormatNumber: true, NumberFormatNumberWithCommas: true, NumberFormatPercent: true,
+	NumberFormatDollar: true, NumberFormatEuro: true, NumberFormatPound: true, NumberFormatYen: true,
+	NumberFormatRuble: true, NumberFormatRupee: true, NumberFormatWon: true, NumberFormatYuan: true,
+	NumberFormatCanadianDollar: true, NumberFormatReal: true, NumberFormatLira: true,
+	NumberFormatRupiah: true, NumberFormatFranc: true, NumberFormatHongKongDollar: true,
+	NumberFormatNewZealandDollar: true, NumberFormatKrona: true, NumberFormatNorwegianKrone: true,
+	NumberFormatMexicanPeso: true, NumberFormatRand: true, NumberFormatNewTaiwanDollar: true,
+	NumberFormatDanishKrone: true, NumberFormatZloty: true, NumberFormatBaht: true,
+	NumberFormatForint: true, NumberFormatKoruna: true, NumberFormatShekel: true,
+	NumberFormatChileanPeso: true, NumberFormatPhilippinePeso: true, NumberFormatDirham: true,
+	NumberFormatColombianPeso: true, NumberFormatRiyal: true, NumberFormatRinggit: true,
+	NumberFormatLeu: true,
+}
+
+var rollupFunctions = map[string]bool{
+	"count_all": true, "count_values": true, "count_unique_values": true,
+	"count_empty": true, "count_not_empty": true, "percent_empty": true,
+	"percent_not_empty": true, "sum": true, "average": true, "median": true,
+	"min": true, "max": true, "range": true, "show_original": true,
+}
+
+var selectColors = map[Color]bool{
+	ColorDefault: true, ColorGray: true, ColorBrown: true, ColorOrange: true, ColorYellow: true,
+	ColorGreen: true, ColorBlue: true, ColorPurple: true, ColorPink: true, ColorRed: true,
+}
+
+// Validate checks that the property has a known type and valid metadata
+// for that type. A property without Type is valid if it has Name, as used
+// for renaming properties.
+func (prop DatabaseProperty) Validate() error {
+	metas := []struct {
+		typ   DatabasePropertyType
+		isSet bool
+	}{
+		{DBPropTypeNumber, prop.Number != nil},
+		{DBPropTypeSelect, prop.Select != nil},
+		{DBPropTypeMultiSelect, prop.MultiSelect != nil},
+		{DBPropTypeFormula, prop.Formula != nil},
+		{DBPropTypeRelation, prop.Relation != nil},
+		{DBPropTypeRollup, prop.Rollup != nil},
+	}
+	for _, m := range metas {
+		if typ := m.typ; m.isSet && typ != prop.Type {
+			if prop.Type == "" {
+				return fmt.Errorf("type is required with %s metadata", typ)
+			}
+			return fmt.Errorf("%s metadata is not allowed for %s property", typ, prop.Type)
+		}
+	}
+
+	switch prop.Type {
+	case "":
+		if prop.Name == "" {
+			return errors.New("type or name is required")
+		}
+	case DBPropTypeTitle, DBPropTypeRichText, DBPropTypeDate, DBPropTypePeople, DBPropTypeFiles,
+		DBPropTypeCheckbox, DBPropTypeURL, DBPropTypeEmail, DBPropTypePhoneNumber,
+		DBPropTypeCreatedTime, DBPropTypeCreatedBy, DBPropTypeLastEditedTime, DBPropTypeLastEditedBy:
+	case DBPropTypeNumber:
+		if prop.Number != nil && !numberFormats[prop.Number.Format] {
+			return fmt.Errorf("invalid number format %q", prop.Number.Format)
+		}
+	case DBPropTypeSelect:
+		if prop.Select != nil {
+			return validateOptions(prop.Select.Options)
+		}
+	case DBPropTypeMultiSelect:
+		if prop.MultiSelect != nil {
+			return validateOptions(prop.MultiSelect.Options)
+		}
+	case DBPropTypeFormula:
+		if prop.Formula == nil || prop.Formula.Expression == "" {
+			return errors.New("formula expression is required")
+		}
+	case DBPropTypeRelation:
+		if prop.Relation == nil || prop.Relation.DatabaseID == "" {
+			return errors.New("relation database ID is required")
+		}
+	case DBPropTypeRollup:
+		r := prop.Rollup
+		switch {
+		case r == nil:
+			return errors.New("rollup metadata is required")
+		case r.RelationPropName == "" && r.RelationPropID == "":
+			return errors.New("rollup relation property name or ID is required")
+		case r.RollupPropName == "" && r.RollupPropID == "":
+			return errors.New("rollup property name or ID is required")
+		case !rollupFunctions[r.Function]:
+			return fmt.Errorf("invalid rollup function %q", r.Function)
+		}
+	default:
+		return fmt.Errorf("unknown property type %q", prop.Type)
+	}
+	return nil
+}
+
+// validateOptions checks options of select and multi_select properties.
+// Notion doesn't allow commas in option names.
+func validateOptions(options []SelectOptions) error {
+	seen := map[string]bool{}
+	for _, o := range options {
+		switch {
+		case o.Name == "":
+			return errors.New("option name is required")
+		case strings.Contains(o.Name, ","):
+			return fmt.Errorf("option name %q can't contain commas", o.Name)
+		case seen[o.Name]:
+			return fmt.Errorf("option %q is defined more than once", o.Name)
+		case o.Color != "" && !selectColors[o.Color]:
+			return fmt.Errorf("invalid color %q of option %q", o.Color, o.Name)
+		}
+		seen[o.Name] = true
+	}
+	return nil
+}
+
+// CreateDatabaseParams are the params used for creating a database.
+type CreateDatabaseParams struct {
+	ParentPageID string
+	Title        []RichText
+	// Properties must have exactly one title property.
+	Properties DatabaseProperties
+}
+
+// UpdateDatabaseParams are the params used for updating a database.
+// Either Title or Properties must be set.
+type UpdateDatabaseParams struct {
+	Title []RichText
+	// Properties to add or change, by property name or ID. Properties not
+	// listed are kept. Set Name to rename a property and a nil value to
+	// remove a property. Options of select properties replace existing
+	// options.
+	Properties map[string]*DatabaseProperty
+}
+
+func (p CreateDatabaseParams) Validate() error {
+	if p.ParentPageID == "" {
+		return errors.New("parent page ID is required")
+	}
+	titles := 0
+	for _, name := range sortedPropertyNames(p.Properties) {
+		prop := p.Properties[name]
+		if prop.Type == "" {
+			return fmt.Errorf("property %q: type is required", name)
+		}
+		if err := prop.Validate(); err != nil {
+			return fmt.Errorf("property %q: %w", name, err)
+		}
+		if prop.Type == DBPropTypeTitle {
+			titles++
+		}
+	}
+	if titles != 1 {
+		return fmt.Errorf("database must have exactly one title property, has %d", titles)
+	}
+	return nil
+}
+
+func (p CreateDatabaseParams) MarshalJSON() ([]byte, error) {
+	type CreateDatabaseParamsDTO struct {
+		Parent     PageParent         `json:"parent"`
+		Title      []RichText         `json:"title,omitempty"`
+		Properties DatabaseProperties `json:"properties"`
+	}
+
+	dto := CreateDatabaseParamsDTO{
+		Parent:     PageParent{Type: ParentTypePage, PageID: &p.ParentPageID},
+		Title:      p.Title,
+		Properties: p.Properties,
+	}
+	return json.Marshal(dto)
+}
+
+func (p UpdateDatabaseParams) Validate() error {
+	if p.Title == nil && len(p.Properties) == 0 {
+		return errors.New("either title or properties is required")
+	}
+	names := make([]string, 0, len(p.Properties))
+	for name := range p.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if prop := p.Properties[name]; prop != nil {
+			if err := prop.Validate(); err != nil {
+				return fmt.Errorf("property %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p UpdateDatabaseParams) MarshalJSON() ([]byte, error) {
+	type UpdateDatabaseParamsDTO struct {
+		Title      []RichText                   `json:"title,omitempty"`
+		Properties map[string]*DatabaseProperty `json:"properties,omitempty"`
+	}
+
+	return json.Marshal(UpdateDatabaseParamsDTO(p))
+}
+
+// sortedPropertyNames returns names of properties in alphabetical order, so
+// that validation errors are deterministic.
+func sortedPropertyNames(props DatabaseProperties) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,273 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+// databaseClient returns a client that checks the method, path and JSON body
+// of a request and responds with respBody.
+func databaseClient(t *testing.T, method, path string, expPostBody map[string]interface{}, respBody string) *notion.Client {
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			if r.Method != method {
+				t.Errorf("method not equal (expected: %s, got: %s)", method, r.Method)
+			}
+			if r.URL.Path != path {
+				t.Errorf("path not equal (expected: %s, got: %s)", path, r.URL.Path)
+			}
+
+			postBody := make(map[string]interface{})
+			err := json.NewDecoder(r.Body).Decode(&postBody)
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(expPostBody, postBody); diff != "" {
+				t.Errorf("post body not equal (-exp, +got):\n%v", diff)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(respBody)),
+			}, nil
+		}},
+	}
+	return notion.NewClient("secret-api-key", &notion.ClientOptions{HTTPClient: httpClient})
+}
+
+func TestCreateDatabase(t *testing.T) {
+	t.Parallel()
+
+	props, err := notion.NewSchema().
+		Title("Name").
+		Select("Status", notion.SelectOptions{Name: "Done", Color: notion.ColorGreen}).
+		Number("Price", notion.NumberFormatYen).
+		Relation("Project", "668d797c-76fa-4934-9b05-ad288df2d136").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expPostBody := map[string]interface{}{
+		"parent": map[string]interface{}{
+			"type":    "page_id",
+			"page_id": "98ad959b-2b6a-4774-80ee-00246fb0ea9b",
+		},
+		"title": []interface{}{
+			map[string]interface{}{"text": map[string]interface{}{"content": "Tasks"}},
+		},
+		"properties": map[string]interface{}{
+			"Name": map[string]interface{}{"type": "title", "title": map[string]interface{}{}},
+			"Status": map[string]interface{}{
+				"type": "select",
+				"select": map[string]interface{}{
+					"options": []interface{}{
+						map[string]interface{}{"name": "Done", "color": "green"},
+					},
+				},
+			},
+			"Price": map[string]interface{}{
+				"type":   "number",
+				"number": map[string]interface{}{"format": "yen"},
+			},
+			"Project": map[string]interface{}{
+				"type":     "relation",
+				"relation": map[string]interface{}{"database_id": "668d797c-76fa-4934-9b05-ad288df2d136"},
+			},
+		},
+	}
+	respBody := `{
+		"object": "database",
+		"id": "bc1211ca-e3f1-4939-ae34-5260b16f627c",
+		"created_time": "2021-07-08T23:50:00.000Z",
+		"last_edited_time": "2021-07-08T23:50:00.000Z",
+		"title": [{"type": "text", "text": {"content": "Tasks"}, "plain_text": "Tasks"}],
+		"properties": {
+			"Name": {"id": "title", "name": "Name", "type": "title", "title": {}},
+			"Price": {"id": "aB~x", "name": "Price", "type": "number", "number": {"format": "yen"}}
+		}
+	}`
+	client := databaseClient(t, http.MethodPost, "/v1/databases", expPostBody, respBody)
+
+	db, err := client.CreateDatabase(context.Background(), notion.CreateDatabaseParams{
+		ParentPageID: "98ad959b-2b6a-4774-80ee-00246fb0ea9b",
+		Title:        []notion.RichText{{Text: &notion.Text{Content: "Tasks"}}},
+		Properties:   props,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expProps := notion.DatabaseProperties{
+		"Name":  {ID: "title", Name: "Name", Type: notion.DBPropTypeTitle},
+		"Price": {ID: "aB~x", Name: "Price", Type: notion.DBPropTypeNumber, Number: &notion.NumberMetadata{Format: notion.NumberFormatYen}},
+	}
+	if diff := cmp.Diff(expProps, db.Properties); diff != "" {
+		t.Fatalf("properties not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestUpdateDatabase(t *testing.T) {
+	t.Parallel()
+
+	expPostBody := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"Status":    map[string]interface{}{"name": "Stage"},
+			"Old notes": nil,
+			"Tags": map[string]interface{}{
+				"type": "multi_select",
+				"multi_select": map[string]interface{}{
+					"options": []interface{}{
+						map[string]interface{}{"name": "bug", "color": "red"},
+						map[string]interface{}{"name": "feature"},
+					},
+				},
+			},
+		},
+	}
+	respBody := `{
+		"object": "database",
+		"id": "bc1211ca-e3f1-4939-ae34-5260b16f627c",
+		"properties": {
+			"Stage": {"id": "a", "name": "Stage", "type": "select", "select": {"options": []}}
+		}
+	}`
+	client := databaseClient(t, http.MethodPatch, "/v1/databases/bc1211ca-e3f1-4939-ae34-5260b16f627c", expPostBody, respBody)
+
+	db, err := client.UpdateDatabase(context.Background(), "bc1211ca-e3f1-4939-ae34-5260b16f627c", notion.UpdateDatabaseParams{
+		Properties: map[string]*notion.DatabaseProperty{
+			"Status":    {Name: "Stage"},
+			"Old notes": nil,
+			"Tags": {
+				Type: notion.DBPropTypeMultiSelect,
+				MultiSelect: &notion.SelectMetadata{Options: []notion.SelectOptions{
+					{Name: "bug", Color: notion.ColorRed},
+					{Name: "feature"},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := db.Properties["Stage"]; !ok {
+		t.Fatalf("expected Stage property, got %+v", db.Properties)
+	}
+}
+
+func TestDatabaseParamsErrors(t *testing.T) {
+	t.Parallel()
+
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient: &http.Client{
+			Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+				return nil, errors.New("unexpected request")
+			}},
+		},
+	})
+	ctx := context.Background()
+
+	_, err := client.CreateDatabase(ctx, notion.CreateDatabaseParams{
+		ParentPageID: "98ad959b-2b6a-4774-80ee-00246fb0ea9b",
+		Properties: notion.DatabaseProperties{
+			"Notes": {Type: notion.DBPropTypeRichText},
+		},
+	})
+	exp := "notion: invalid database params: database must have exactly one title property, has 0"
+	if err == nil || err.Error() != exp {
+		t.Errorf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+
+	_, err = client.UpdateDatabase(ctx, "bc1211ca-e3f1-4939-ae34-5260b16f627c", notion.UpdateDatabaseParams{})
+	exp = "notion: invalid database params: either title or properties is required"
+	if err == nil || err.Error() != exp {
+		t.Errorf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+}
+
+func TestSchemaBuilderErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema *notion.SchemaBuilder
+		expErr string
+	}{
+		{
+			name:   "duplicate property",
+			schema: notion.NewSchema().Title("Name").RichText("Name"),
+			expErr: `notion: invalid schema: property "Name" is defined more than once`,
+		},
+		{
+			name:   "invalid number format",
+			schema: notion.NewSchema().Number("Price", "bitcoin"),
+			expErr: `notion: invalid schema: property "Price": invalid number format "bitcoin"`,
+		},
+		{
+			name:   "missing relation database",
+			schema: notion.NewSchema().Relation("Project", ""),
+			expErr: `notion: invalid schema: property "Project": relation database ID is required`,
+		},
+		{
+			name:   "invalid rollup function",
+			schema: notion.NewSchema().Rollup("Total", "Items", "Price", "total"),
+			expErr: `notion: invalid schema: property "Total": invalid rollup function "total"`,
+		},
+		{
+			name:   "empty formula",
+			schema: notion.NewSchema().Formula("Days", ""),
+			expErr: `notion: invalid schema: property "Days": formula expression is required`,
+		},
+		{
+			name: "duplicate option",
+			schema: notion.NewSchema().MultiSelect("Tags",
+				notion.SelectOptions{Name: "bug"},
+				notion.SelectOptions{Name: "bug"}),
+			expErr: `notion: invalid schema: property "Tags": option "bug" is defined more than once`,
+		},
+		{
+			name:   "option with comma",
+			schema: notion.NewSchema().Select("Status", notion.SelectOptions{Name: "a,b"}),
+			expErr: `notion: invalid schema: property "Status": option name "a,b" can't contain commas`,
+		},
+		{
+			name:   "background color",
+			schema: notion.NewSchema().Select("Status", notion.SelectOptions{Name: "Done", Color: notion.ColorRedBg}),
+			expErr: `notion: invalid schema: property "Status": invalid color "red_background" of option "Done"`,
+		},
+		{
+			name: "metadata of other type",
+			schema: notion.NewSchema().Property("Price", notion.DatabaseProperty{
+				Type:   notion.DBPropTypeRichText,
+				Number: &notion.NumberMetadata{Format: notion.NumberFormatDollar},
+			}),
+			expErr: `notion: invalid schema: property "Price": number metadata is not allowed for rich_text property`,
+		},
+		{
+			name:   "unknown type",
+			schema: notion.NewSchema().Property("Attachments", notion.DatabaseProperty{Type: notion.DBPropTypeFile}),
+			expErr: `notion: invalid schema: property "Attachments": unknown property type "file"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.schema.Build()
+			if err == nil || err.Error() != tt.expErr {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,577 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Evaluator evaluates database query filters and sorts against pages in
+// memory, without calling the API, e.g. to re-filter cached pages.
+// The zero value is ready to use.
+//
+// It follows Notion semantics: text contains, starts with and ends with are
+// case-insensitive, "does not" conditions match empty values, comparisons
+// don't match empty values and empty values are sorted last.
+//
+// Rollup filters are not supported and return an error.
+type Evaluator struct {
+	// Now returns current time, used by relative date filters like PastWeek.
+	// Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (e *Evaluator) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// Query returns pages matching the filter of q, sorted by sorts of q.
+// Pagination params of q are ignored.
+func (e *Evaluator) Query(pages []Page, q *DatabaseQuery) ([]Page, error) {
+	if q == nil {
+		return pages, nil
+	}
+	res, err := e.Filter(pages, q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Sort(res, q.Sorts); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Filter returns pages matching f. A nil filter matches all pages.
+func (e *Evaluator) Filter(pages []Page, f *DatabaseQueryFilter) ([]Page, error) {
+	if f == nil {
+		return pages, nil
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("notion: invalid filter: %w", err)
+	}
+	var res []Page
+	for i := range pages {
+		ok, err := e.match(&pages[i], f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res = append(res, pages[i])
+		}
+	}
+	return res, nil
+}
+
+// Match returns true if page matches f.
+func (e *Evaluator) Match(page *Page, f *DatabaseQueryFilter) (bool, error) {
+	if err := f.Validate(); err != nil {
+		return false, fmt.Errorf("notion: invalid filter: %w", err)
+	}
+	return e.match(page, f)
+}
+
+func (e *Evaluator) match(page *Page, f *DatabaseQueryFilter) (bool, error) {
+	if f.And != nil {
+		for i := range f.And {
+			ok, err := e.match(page, &f.And[i])
+			if !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	if f.Or != nil {
+		for i := range f.Or {
+			ok, err := e.match(page, &f.Or[i])
+			if ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	if f.Timestamp != "" {
+		t := page.CreatedTime
+		c := f.CreatedTime
+		if f.Timestamp == SortTimeStampLastEditedTime {
+			t, c = page.LastEditedTime, f.LastEditedTime
+		}
+		return e.matchDate(c, t, t), nil
+	}
+
+	prop, err := pageProperty(page, f.Property)
+	if err != nil {
+		return false, err
+	}
+	mismatch := func(filterType string) error {
+		return fmt.Errorf("notion: can't use %s filter on %s property %q", filterType, prop.Type, f.Property)
+	}
+
+	switch {
+	case f.Text != nil:
+		s, ok := textValue(prop)
+		if !ok {
+			return false, mismatch("text")
+		}
+		return matchText(f.Text, s), nil
+	case f.Number != nil:
+		if prop.Type != DBPropTypeNumber {
+			return false, mismatch("number")
+		}
+		return matchNumber(f.Number, prop.Number), nil
+	case f.Checkbox != nil:
+		if prop.Type != DBPropTypeCheckbox {
+			return false, mismatch("checkbox")
+		}
+		return matchCheckbox(f.Checkbox, prop.Checkbox != nil && *prop.Checkbox), nil
+	case f.Select != nil:
+		if prop.Type != DBPropTypeSelect {
+			return false, mismatch("select")
+		}
+		return matchSelect(f.Select, prop.Select), nil
+	case f.MultiSelect != nil:
+		if prop.Type != DBPropTypeMultiSelect {
+			return false, mismatch("multi_select")
+		}
+		names := make([]string, len(prop.MultiSelect))
+		for i, o := range prop.MultiSelect {
+			names[i] = o.Name
+		}
+		c := f.MultiSelect
+		return matchList(names, c.Contains, c.DoesNotContain, c.IsEmpty, c.IsNotEmpty, false), nil
+	case f.Date != nil:
+		start, end, ok := dateValue(prop)
+		if !ok {
+			return false, mismatch("date")
+		}
+		return e.matchDate(f.Date, start, end), nil
+	case f.People != nil:
+		var ids []string
+		switch prop.Type {
+		case DBPropTypePeople:
+			for _, u := range prop.People {
+				ids = append(ids, u.ID)
+			}
+		case DBPropTypeCreatedBy, DBPropTypeLastEditedBy:
+			u := prop.CreatedBy
+			if prop.Type == DBPropTypeLastEditedBy {
+				u = prop.LastEditedBy
+			}
+			if u != nil {
+				ids = append(ids, u.ID)
+			}
+		default:
+			return false, mismatch("people")
+		}
+		c := f.People
+		return matchList(ids, c.Contains, c.DoesNotContain, c.IsEmpty, c.IsNotEmpty, true), nil
+	case f.Relation != nil:
+		if prop.Type != DBPropTypeRelation {
+			return false, mismatch("relation")
+		}
+		ids := make([]string, len(prop.Relation))
+		for i, r := range prop.Relation {
+			ids[i] = r.ID
+		}
+		c := f.Relation
+		return matchList(ids, c.Contains, c.DoesNotContain, c.IsEmpty, c.IsNotEmpty, true), nil
+	case f.Files != nil:
+		if prop.Type != DBPropTypeFiles {
+			return false, mismatch("files")
+		}
+		if f.Files.IsEmpty {
+			return len(prop.Files) == 0, nil
+		}
+		return len(prop.Files) > 0, nil
+	case f.CreatedTime != nil, f.LastEditedTime != nil:
+		c, typ := f.CreatedTime, DBPropTypeCreatedTime
+		if c == nil {
+			c, typ = f.LastEditedTime, DBPropTypeLastEditedTime
+		}
+		if prop.Type != typ {
+			return false, mismatch(string(typ))
+		}
+		start, end, _ := dateValue(prop)
+		return e.matchDate(c, start, end), nil
+	case f.Formula != nil:
+		if prop.Type != DBPropTypeFormula {
+			return false, mismatch("formula")
+		}
+		return e.matchFormula(f.Formula, prop.Formula, f.Property)
+	case f.Rollup != nil:
+		return false, fmt.Errorf("notion: evaluating rollup filter on property %q is not supported", f.Property)
+	}
+	return false, fmt.Errorf("notion: unsupported filter on property %q", f.Property)
+}
+
+func pageProperty(page *Page, name string) (*DatabasePageProperty, error) {
+	props, ok := page.Properties.(DatabasePageProperties)
+	if !ok {
+		return nil, fmt.Errorf("notion: page %s is not in a database", page.ID)
+	}
+	prop, ok := props[name]
+	if !ok {
+		return nil, fmt.Errorf("notion: property %q not found in page %s", name, page.ID)
+	}
+	return &prop, nil
+}
+
+// textValue returns the value of a property that can be filtered with a
+// text filter.
+func textValue(prop *DatabasePageProperty) (string, bool) {
+	switch prop.Type {
+	case DBPropTypeTitle:
+		return PlainText(prop.Title), true
+	case DBPropTypeRichText:
+		return PlainText(prop.RichText), true
+	case DBPropTypeURL:
+		return stringValue(prop.URL), true
+	case DBPropTypeEmail:
+		return stringValue(prop.Email), true
+	case DBPropTypePhoneNumber:
+		return stringValue(prop.PhoneNumber), true
+	}
+	return "", false
+}
+
+// dateValue returns the date range of a property that can be filtered with
+// a date filter. start is zero for empty dates.
+func dateValue(prop *DatabasePageProperty) (start, end time.Time, ok bool) {
+	switch prop.Type {
+	case DBPropTypeDate:
+		if prop.Date == nil {
+			return start, end, true
+		}
+		start = time.Time(prop.Date.Start)
+		end = start
+		if prop.Date.End != nil {
+			end = time.Time(*prop.Date.End)
+		}
+		return start, end, true
+	case DBPropTypeCreatedTime:
+		start = timeValue(prop.CreatedTime)
+		return start, start, true
+	case DBPropTypeLastEditedTime:
+		start = timeValue(prop.LastEditedTime)
+		return start, start, true
+	}
+	return start, end, false
+}
+
+func (e *Evaluator) matchFormula(c *FormulaDatabaseQueryFilter, v *FormulaProperty, name string) (bool, error) {
+	if v == nil {
+		v = &FormulaProperty{}
+	}
+	var condType FormulaType
+	var filterType string
+	switch {
+	case c.Text != nil:
+		condType, filterType = FormulaTypeString, "text"
+	case c.Number != nil:
+		condType, filterType = FormulaTypeNumber, "number"
+	case c.Checkbox != nil:
+		condType, filterType = FormulaTypeBoolean, "checkbox"
+	case c.Date != nil:
+		condType, filterType = FormulaTypeDate, "date"
+	}
+	if v.Type != "" && v.Type != condType {
+		return false, fmt.Errorf("notion: can't use formula %s filter on formula property %q with %s result", filterType, name, v.Type)
+	}
+
+	switch {
+	case c.Text != nil:
+		return matchText(c.Text, v.String), nil
+	case c.Number != nil:
+		return matchNumber(c.Number, v.Number), nil
+	case c.Checkbox != nil:
+		return matchCheckbox(c.Checkbox, v.Boolean), nil
+	}
+	var t time.Time
+	if v.Date != nil {
+		t = *v.Date
+	}
+	return e.matchDate(c.Date, t, t), nil
+}
+
+func matchText(c *TextDatabaseQueryFilter, s string) bool {
+	lower, isEmpty := strings.ToLower(s), s == ""
+	switch {
+	case c.Equals != "":
+		return s == c.Equals
+	case c.DoesNotEqual != "":
+		return s != c.DoesNotEqual
+	case c.Contains != "":
+		return strings.Contains(lower, strings.ToLower(c.Contains))
+	case c.DoesNotContain != "":
+		return !strings.Contains(lower, strings.ToLower(c.DoesNotContain))
+	case c.StartsWith != "":
+		return strings.HasPrefix(lower, strings.ToLower(c.StartsWith))
+	case c.EndsWith != "":
+		return strings.HasSuffix(lower, strings.ToLower(c.EndsWith))
+	case c.IsEmpty:
+		return isEmpty
+	case c.IsNotEmpty:
+		return !isEmpty
+	}
+	return false
+}
+
+func matchNumber(c *NumberDatabaseQueryFilter, n *float64) bool {
+	switch {
+	case c.IsEmpty:
+		return n == nil
+	case c.IsNotEmpty:
+		return n != nil
+	case c.DoesNotEqual != nil:
+		return n == nil || *n != *c.DoesNotEqual
+	case n == nil:
+		return false
+	case c.Equals != nil:
+		return *n == *c.Equals
+	case c.GreaterThan != nil:
+		return *n > *c.GreaterThan
+	case c.LessThan != nil:
+		return *n < *c.LessThan
+	case c.GreaterThanOrEqualTo != nil:
+		return *n >= *c.GreaterThanOrEqualTo
+	case c.LessThanOrEqualTo != nil:
+		return *n <= *c.LessThanOrEqualTo
+	}
+	return false
+}
+
+func matchCheckbox(c *CheckboxDatabaseQueryFilter, v bool) bool {
+	switch {
+	case c.Equals != nil:
+		return v == *c.Equals
+	case c.DoesNotEqual != nil:
+		return v != *c.DoesNotEqual
+	}
+	return false
+}
+
+func matchSelect(c *SelectDatabaseQueryFilter, o *SelectOptions) bool {
+	name := ""
+	if o != nil {
+		name = o.Name
+	}
+	switch {
+	case c.Equals != "":
+		return name == c.Equals
+	case c.DoesNotEqual != "":
+		return name != c.DoesNotEqual
+	case c.IsEmpty:
+		return o == nil
+	case c.IsNotEmpty:
+		return o != nil
+	}
+	return false
+}
+
+// normalizeID removes dashes from an ID, so that IDs with and without dashes
+// compare equal.
+func normalizeID(id string) string {
+	return strings.ToLower(strings.Replace(id, "-", "", -1))
+}
+
+func matchList(values []string, contains, doesNotContain string, isEmpty, isNotEmpty, ids bool) bool {
+	has := func(s string) bool {
+		for _, v := range values {
+			if v == s || (ids && normalizeID(v) == normalizeID(s)) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case contains != "":
+		return has(contains)
+	case doesNotContain != "":
+		return !has(doesNotContain)
+	case isEmpty:
+		return len(values) == 0
+	case isNotEmpty:
+		return len(values) > 0
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// matchDate matches date range [start, end]. Range matches equals if the
+// day is within the range and before/after conditions if any part of the
+// range is before/after the date.
+func (e *Evaluator) matchDate(c *DateDatabaseQueryFilter, start, end time.Time) bool {
+	isEmpty := start.IsZero()
+	if c.IsEmpty {
+		return isEmpty
+	}
+	if c.IsNotEmpty {
+		return !isEmpty
+	}
+	if isEmpty {
+		return false
+	}
+
+	inWindow := func(from, to time.Time) bool {
+		return !end.Before(from) && !start.After(to)
+	}
+	now := e.now()
+	switch {
+	case c.Equals != nil:
+		day := startOfDay(*c.Equals)
+		return !startOfDay(end).Before(day) && !startOfDay(start).After(day)
+	case c.Before != nil:
+		return start.Before(*c.Before)
+	case c.After != nil:
+		return end.After(*c.After)
+	case c.OnOrBefore != nil:
+		return !start.After(*c.OnOrBefore)
+	case c.OnOrAfter != nil:
+		return !end.Before(*c.OnOrAfter)
+	case c.PastWeek != nil:
+		return inWindow(now.AddDate(0, 0, -7), now)
+	case c.PastMonth != nil:
+		return inWindow(now.AddDate(0, -1, 0), now)
+	case c.PastYear != nil:
+		return inWindow(now.AddDate(-1, 0, 0), now)
+	case c.NextWeek != nil:
+		return inWindow(now, now.AddDate(0, 0, 7))
+	case c.NextMonth != nil:
+		return inWindow(now, now.AddDate(0, 1, 0))
+	case c.NextYear != nil:
+		return inWindow(now, now.AddDate(1, 0, 0))
+	}
+	return false
+}
+
+// sortKey is a value of a page compared when sorting.
+type sortKey struct {
+	empty bool
+	s     string
+	n     float64
+	t     time.Time
+}
+
+func (a sortKey) compare(b sortKey) int {
+	switch {
+	case !a.t.Equal(b.t):
+		if a.t.Before(b.t) {
+			return -1
+		}
+		return 1
+	case a.n != b.n:
+		if a.n < b.n {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(strings.ToLower(a.s), strings.ToLower(b.s))
+}
+
+func pageSortKey(page *Page, s *DatabaseQuerySort) (sortKey, error) {
+	if s.Property == "" {
+		switch s.Timestamp {
+		case SortTimeStampCreatedTime:
+			return sortKey{t: page.CreatedTime}, nil
+		case SortTimeStampLastEditedTime:
+			return sortKey{t: page.LastEditedTime}, nil
+		}
+		return sortKey{}, errors.New("notion: sort must have property or timestamp")
+	}
+	prop, err := pageProperty(page, s.Property)
+	if err != nil {
+		return sortKey{}, err
+	}
+
+	var k sortKey
+	if str, ok := textValue(prop); ok {
+		return sortKey{s: str, empty: str == ""}, nil
+	}
+	if start, _, ok := dateValue(prop); ok {
+		return sortKey{t: start, empty: start.IsZero()}, nil
+	}
+	switch prop.Type {
+	case DBPropTypeNumber:
+		k.empty = prop.Number == nil
+		k.n = floatValue(prop.Number)
+	case DBPropTypeCheckbox:
+		if prop.Checkbox != nil && *prop.Checkbox {
+			k.n = 1
+		}
+	case DBPropTypeSelect:
+		k.empty = prop.Select == nil
+		if prop.Select != nil {
+			k.s = prop.Select.Name
+		}
+	case DBPropTypeMultiSelect:
+		k.empty = len(prop.MultiSelect) == 0
+		for _, o := range prop.MultiSelect {
+			k.s += o.Name + ","
+		}
+	case DBPropTypePeople:
+		k.empty = len(prop.People) == 0
+		for _, u := range prop.People {
+			k.s += u.Name + ","
+		}
+	default:
+		return k, fmt.Errorf("notion: sorting by %s property %q is not supported", prop.Type, s.Property)
+	}
+	return k, nil
+}
+
+// Sort sorts pages in place. Sorting is stable, pages that compare equal
+// keep their order.
+func (e *Evaluator) Sort(pages []Page, sorts []DatabaseQuerySort) error {
+	if len(sorts) == 0 {
+		return nil
+	}
+	keys := make([][]sortKey, len(pages))
+	for i := range pages {
+		keys[i] = make([]sortKey, len(sorts))
+		for j := range sorts {
+			k, err := pageSortKey(&pages[i], &sorts[j])
+			if err != nil {
+				return err
+			}
+			keys[i][j] = k
+		}
+	}
+
+	idx := make([]int, len(pages))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(x, y int) bool {
+		a, b := keys[idx[x]], keys[idx[y]]
+		for j := range sorts {
+			if a[j].empty != b[j].empty {
+				// empty values are last, regardless of direction
+				return b[j].empty
+			}
+			c := a[j].compare(b[j])
+			if sorts[j].Direction == SortDirDesc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	sorted := make([]Page, len(pages))
+	for i, j := range idx {
+		sorted[i] = pages[j]
+	}
+	copy(pages, sorted)
+	return nil
+}
@@ -0,0 +1,126 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+// evalFixture is a set of pages of a database and queries with expected
+// results, written by hand from the API documentation. Pages are in the
+// format returned by the query database endpoint.
+type evalFixture struct {
+	Now   time.Time                    `json:"now"`
+	Pages notion.DatabaseQueryResponse `json:"pages"`
+	Cases []struct {
+		Name    string               `json:"name"`
+		Query   notion.DatabaseQuery `json:"query"`
+		Results []string             `json:"results"`
+	} `json:"cases"`
+}
+
+func TestEvaluatorExamples(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "eval", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fixture evalFixture
+		if err := json.Unmarshal(b, &fixture); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		e := &notion.Evaluator{Now: func() time.Time { return fixture.Now }}
+
+		for _, tt := range fixture.Cases {
+			tt := tt
+			t.Run(filepath.Base(file)+"/"+tt.Name, func(t *testing.T) {
+				t.Parallel()
+
+				pages := append([]notion.Page(nil), fixture.Pages.Results...)
+				res, err := e.Query(pages, &tt.Query)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(tt.Results, pageIDs(res)); diff != "" {
+					t.Fatalf("results not equal (-exp, +got):\n%v", diff)
+				}
+			})
+		}
+	}
+}
+
+func pageIDs(pages []notion.Page) []string {
+	ids := []string{}
+	for _, p := range pages {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestEvaluatorErrors(t *testing.T) {
+	t.Parallel()
+
+	page := &notion.Page{
+		ID: "page-id",
+		Properties: notion.DatabasePageProperties{
+			"Done":  {Type: notion.DBPropTypeCheckbox},
+			"Total": {Type: notion.DBPropTypeRollup},
+		},
+	}
+	yes := true
+	tests := []struct {
+		name   string
+		filter *notion.DatabaseQueryFilter
+		expErr string
+	}{
+		{
+			name:   "invalid filter",
+			filter: &notion.DatabaseQueryFilter{Property: "Done"},
+			expErr: `notion: invalid filter: property "Done": filter has no condition`,
+		},
+		{
+			name:   "missing property",
+			filter: &notion.DatabaseQueryFilter{Property: "Nope", Checkbox: &notion.CheckboxDatabaseQueryFilter{Equals: &yes}},
+			expErr: `notion: property "Nope" not found in page page-id`,
+		},
+		{
+			name:   "filter type mismatch",
+			filter: &notion.DatabaseQueryFilter{Property: "Done", Text: &notion.TextDatabaseQueryFilter{Contains: "a"}},
+			expErr: `notion: can't use text filter on checkbox property "Done"`,
+		},
+		{
+			name: "rollup filter",
+			filter: &notion.DatabaseQueryFilter{Property: "Total", Rollup: &notion.RollupDatabaseQueryFilter{
+				Number: &notion.NumberDatabaseQueryFilter{IsEmpty: true},
+			}},
+			expErr: `notion: evaluating rollup filter on property "Total" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := (&notion.Evaluator{}).Match(page, tt.filter)
+			if err == nil || err.Error() != tt.expErr {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expErr, err)
+			}
+		})
+	}
+}
@@ -26,7 +26,18 @@ func showBlock(b *notion.Block) {
 		logf(" %v\n", b.ToDo.Text)
 	case notion.BlockTypeToggle:
 		logf(" %v\n", b.Toggle.Text)
+	case notion.BlockTypeQuote:
+		logf(" %v\n", b.Quote.Text)
+	case notion.BlockTypeCallout:
+		logf(" %v\n", b.Callout.Text)
+	case notion.BlockTypeCode:
+		logf(" %s: %v\n", b.Code.Language, b.Code.Text)
+	case notion.BlockTypeImage:
+		logf(" %s\n", b.Image.URL())
 	case notion.BlockTypeChildPage:
+		logf(" %s\n", b.ChildPage.Title)
+	case notion.BlockTypeChildDatabase:
+		logf(" %s\n", b.ChildDatabase.Title)
 	case notion.BlockTypeUnsupported:
 	}
 }
@@ -0,0 +1,67 @@
+package notion
+
+import "time"
+
+// FileObject is a file either uploaded to Notion or hosted externally.
+// It's used by image, video, file and pdf blocks and files properties.
+// See: https://developers.notion.com/reference/file-object
+type FileObject struct {
+	Type FileType `json:"type"`
+	// Name is only set for files in a files property.
+	Name string `json:"name,omitempty"`
+
+	// one of those depending on Type
+	File     *HostedFile   `json:"file,omitempty"`
+	External *ExternalFile `json:"external,omitempty"`
+
+	Caption []RichText `json:"caption,omitempty"`
+}
+
+// URL returns the URL of the file, regardless of where it's hosted.
+func (f *FileObject) URL() string {
+	switch {
+	case f.File != nil:
+		return f.File.URL
+	case f.External != nil:
+		return f.External.URL
+	}
+	return ""
+}
+
+// HostedFile is a file uploaded to Notion. The URL is temporary and
+// expires at ExpiryTime.
+type HostedFile struct {
+	URL        string     `json:"url"`
+	ExpiryTime *time.Time `json:"expiry_time,omitempty"`
+}
+
+// ExternalFile is a file hosted outside of Notion.
+type ExternalFile struct {
+	URL string `json:"url"`
+}
+
+// Icon is an icon of a callout block.
+type Icon struct {
+	Type IconType `json:"type"`
+
+	// one of those depending on Type
+	Emoji    string        `json:"emoji,omitempty"`
+	File     *HostedFile   `json:"file,omitempty"`
+	External *ExternalFile `json:"external,omitempty"`
+}
+
+type (
+	FileType string
+	IconType string
+)
+
+const (
+	FileTypeFile     FileType = "file"
+	FileTypeExternal FileType = "external"
+)
+
+const (
+	IconTypeEmoji    IconType = "emoji"
+	IconTypeFile     IconType = "file"
+	IconTypeExternal IconType = "external"
+)
@@ -0,0 +1,597 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MaxFilterDepth is the maximum nesting of compound (and, or) filters
+// accepted by Notion.
+const MaxFilterDepth = 2
+
+// Filter is the entry point for building database query filters e.g.:
+//
+//	f, err := notion.Filter.Prop("Status").Select().Equals("Done").
+//		And(notion.Filter.Prop("Due").Date().PastWeek()).
+//		Build()
+var Filter FilterBuilder
+
+// FilterBuilder builds property and compound filters.
+type FilterBuilder struct{}
+
+// FilterExpr is a filter built with FilterBuilder. Combine filters with And
+// and Or and call Build to get a validated DatabaseQueryFilter.
+type FilterExpr struct {
+	f DatabaseQueryFilter
+}
+
+// PropFilterBuilder selects the type of filter for a property.
+type PropFilterBuilder struct {
+	prop string
+	// wrap, if set, returns the filter for a condition. It's used for
+	// conditions nested in formula, rollup and timestamp filters.
+	wrap func(cond DatabaseQueryFilter) DatabaseQueryFilter
+}
+
+// Prop starts a filter for a property with a given name.
+func (FilterBuilder) Prop(name string) PropFilterBuilder {
+	return PropFilterBuilder{prop: name}
+}
+
+// CreatedTime returns a builder of a filter on the time pages were created.
+func (FilterBuilder) CreatedTime() DateFilterBuilder {
+	return timestampFilter(SortTimeStampCreatedTime)
+}
+
+// LastEditedTime returns a builder of a filter on the time pages were last
+// edited.
+func (FilterBuilder) LastEditedTime() DateFilterBuilder {
+	return timestampFilter(SortTimeStampLastEditedTime)
+}
+
+func timestampFilter(ts SortTimestamp) DateFilterBuilder {
+	return DateFilterBuilder{PropFilterBuilder{wrap: func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		f := DatabaseQueryFilter{Timestamp: ts}
+		if ts == SortTimeStampCreatedTime {
+			f.CreatedTime = c.Date
+		} else {
+			f.LastEditedTime = c.Date
+		}
+		return f
+	}}}
+}
+
+// And returns a filter matching pages matched by all filters.
+func (FilterBuilder) And(filters ...FilterExpr) FilterExpr {
+	var res FilterExpr
+	res.f.And = compound(filters, func(f *DatabaseQueryFilter) []DatabaseQueryFilter { return f.And })
+	return res
+}
+
+// Or returns a filter matching pages matched by any of the filters.
+func (FilterBuilder) Or(filters ...FilterExpr) FilterExpr {
+	var res FilterExpr
+	res.f.Or = compound(filters, func(f *DatabaseQueryFilter) []DatabaseQueryFilter { return f.Or })
+	return res
+}
+
+// compound returns filters for a compound filter. Filters that are already
+// compound filters of the same kind are flattened, so that e.g.
+// a.And(b).And(c) doesn't increase the nesting depth.
+func compound(filters []FilterExpr, children func(f *DatabaseQueryFilter) []DatabaseQueryFilter) []DatabaseQueryFilter {
+	res := []DatabaseQueryFilter{}
+	for i := range filters {
+		f := &filters[i].f
+		if c := children(f); len(c) > 0 && f.Property == "" {
+			res = append(res, c...)
+			continue
+		}
+		res = append(res, *f)
+	}
+	return res
+}
+
+// And returns a filter matching pages matched by e and all other filters.
+func (e FilterExpr) And(others ...FilterExpr) FilterExpr {
+	return Filter.And(append([]FilterExpr{e}, others...)...)
+}
+
+// Or returns a filter matching pages matched by e or any other filter.
+func (e FilterExpr) Or(others ...FilterExpr) FilterExpr {
+	return Filter.Or(append([]FilterExpr{e}, others...)...)
+}
+
+// Build returns the filter after validating it.
+func (e FilterExpr) Build() (*DatabaseQueryFilter, error) {
+	f := e.f
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("notion: invalid filter: %w", err)
+	}
+	return &f, nil
+}
+
+// Validate checks that the filter is either a property filter with exactly
+// one condition or a compound filter, and that compound filters are not
+// nested deeper than MaxFilterDepth.
+func (f *DatabaseQueryFilter) Validate() error {
+	return f.validate(0)
+}
+
+func (f *DatabaseQueryFilter) validate(depth int) error {
+	isCompound := f.And != nil || f.Or != nil
+	if !isCompound {
+		return f.validateProperty()
+	}
+
+	if f.Property != "" || countSetFields(reflect.ValueOf(*f)) > 1 {
+		return errors.New("compound filter must only have one of and, or set")
+	}
+	depth++
+	if depth > MaxFilterDepth {
+		return fmt.Errorf("compound filters can't be nested more than %d levels deep", MaxFilterDepth)
+	}
+	kind, filters := "and", f.And
+	if f.Or != nil {
+		kind, filters = "or", f.Or
+	}
+	if len(filters) == 0 {
+		return fmt.Errorf("%s filter is empty", kind)
+	}
+	for i := range filters {
+		if err := filters[i].validate(depth); err != nil {
+			return fmt.Errorf("%s[%d]: %w", kind, i, err)
+		}
+	}
+	return nil
+}
+
+func (f *DatabaseQueryFilter) validateProperty() error {
+	if f.Property == "" && f.Timestamp == "" {
+		return errors.New("property is required")
+	}
+	if f.Property != "" && f.Timestamp != "" {
+		return errors.New("filter must only have one of property, timestamp set")
+	}
+	label := fmt.Sprintf("property %q", f.Property)
+	if f.Timestamp != "" {
+		label = fmt.Sprintf("timestamp %q", f.Timestamp)
+	}
+
+	cond, condName, err := oneCondition("", reflect.ValueOf(*f))
+	if err != nil {
+		return fmt.Errorf("%s: %w", label, err)
+	}
+	if f.Timestamp != "" && condName != string(f.Timestamp) {
+		return fmt.Errorf("%s: filter must have %s condition, has %s", label, f.Timestamp, condName)
+	}
+	if err := validateCondition(condName, cond); err != nil {
+		return fmt.Errorf("%s: %w", label, err)
+	}
+	return nil
+}
+
+// oneCondition returns the only set pointer field of a filter struct, with
+// its JSON name.
+func oneCondition(name string, v reflect.Value) (reflect.Value, string, error) {
+	if name != "" {
+		name += " "
+	}
+	var cond reflect.Value
+	var condName string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Ptr || field.IsNil() {
+			continue
+		}
+		if cond.IsValid() {
+			return cond, "", fmt.Errorf("%sfilter must have exactly one type of condition", name)
+		}
+		cond, condName = field.Elem(), jsonName(v.Type().Field(i))
+	}
+	if !cond.IsValid() {
+		return cond, "", fmt.Errorf("%sfilter has no condition", name)
+	}
+	return cond, condName, nil
+}
+
+// validateCondition checks that a condition has exactly one operator set.
+// Formula and rollup conditions are checked recursively.
+func validateCondition(name string, v reflect.Value) error {
+	switch c := v.Interface().(type) {
+	case FormulaDatabaseQueryFilter, RollupDatabaseQueryFilter:
+		cond, condName, err := oneCondition(name, v)
+		if err != nil {
+			return err
+		}
+		return validateCondition(name+" "+condName, cond)
+	case DatabaseQueryFilter:
+		// condition of a rollup any, every or none filter
+		if c.Property != "" || c.Timestamp != "" || c.And != nil || c.Or != nil {
+			return fmt.Errorf("%s filter must only have a condition", name)
+		}
+		cond, condName, err := oneCondition(name, v)
+		if err != nil {
+			return err
+		}
+		if c.Formula != nil || c.Rollup != nil || c.CreatedTime != nil || c.LastEditedTime != nil {
+			return fmt.Errorf("%s filter can't have %s condition", name, condName)
+		}
+		return validateCondition(name+" "+condName, cond)
+	}
+	if n := countSetFields(v); n != 1 {
+		return fmt.Errorf("%s filter must have exactly one condition, has %d", name, n)
+	}
+	return nil
+}
+
+// countSetFields returns the number of non-zero fields of a struct.
+func countSetFields(v reflect.Value) int {
+	n := 0
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Field(i).IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+func (b PropFilterBuilder) expr(set func(f *DatabaseQueryFilter)) FilterExpr {
+	var cond DatabaseQueryFilter
+	set(&cond)
+	if b.wrap != nil {
+		return FilterExpr{f: b.wrap(cond)}
+	}
+	cond.Property = b.prop
+	return FilterExpr{f: cond}
+}
+
+// nested returns a builder of a condition nested in a filter of b's property.
+func (b PropFilterBuilder) nested(wrap func(cond DatabaseQueryFilter) DatabaseQueryFilter) PropFilterBuilder {
+	return PropFilterBuilder{wrap: func(cond DatabaseQueryFilter) DatabaseQueryFilter {
+		return b.expr(func(f *DatabaseQueryFilter) { *f = wrap(cond) }).f
+	}}
+}
+
+// TextFilterBuilder builds a filter for title, rich_text, url, email and
+// phone_number properties.
+type TextFilterBuilder struct{ b PropFilterBuilder }
+
+// Text returns a builder of a text filter.
+func (b PropFilterBuilder) Text() TextFilterBuilder { return TextFilterBuilder{b} }
+
+func (b TextFilterBuilder) expr(c TextDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Text = &c })
+}
+
+func (b TextFilterBuilder) Equals(s string) FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{Equals: s})
+}
+func (b TextFilterBuilder) DoesNotEqual(s string) FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{DoesNotEqual: s})
+}
+func (b TextFilterBuilder) Contains(s string) FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{Contains: s})
+}
+func (b TextFilterBuilder) DoesNotContain(s string) FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{DoesNotContain: s})
+}
+func (b TextFilterBuilder) StartsWith(s string) FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{StartsWith: s})
+}
+func (b TextFilterBuilder) EndsWith(s string) FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{EndsWith: s})
+}
+func (b TextFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{IsEmpty: true})
+}
+func (b TextFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(TextDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// NumberFilterBuilder builds a filter for number properties.
+type NumberFilterBuilder struct{ b PropFilterBuilder }
+
+// Number returns a builder of a number filter.
+func (b PropFilterBuilder) Number() NumberFilterBuilder { return NumberFilterBuilder{b} }
+
+func (b NumberFilterBuilder) expr(c NumberDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Number = &c })
+}
+
+func (b NumberFilterBuilder) Equals(n float64) FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{Equals: &n})
+}
+func (b NumberFilterBuilder) DoesNotEqual(n float64) FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{DoesNotEqual: &n})
+}
+func (b NumberFilterBuilder) GreaterThan(n float64) FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{GreaterThan: &n})
+}
+func (b NumberFilterBuilder) LessThan(n float64) FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{LessThan: &n})
+}
+func (b NumberFilterBuilder) GreaterThanOrEqualTo(n float64) FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{GreaterThanOrEqualTo: &n})
+}
+func (b NumberFilterBuilder) LessThanOrEqualTo(n float64) FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{LessThanOrEqualTo: &n})
+}
+func (b NumberFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{IsEmpty: true})
+}
+func (b NumberFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(NumberDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// CheckboxFilterBuilder builds a filter for checkbox properties.
+type CheckboxFilterBuilder struct{ b PropFilterBuilder }
+
+// Checkbox returns a builder of a checkbox filter.
+func (b PropFilterBuilder) Checkbox() CheckboxFilterBuilder { return CheckboxFilterBuilder{b} }
+
+func (b CheckboxFilterBuilder) expr(c CheckboxDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Checkbox = &c })
+}
+
+func (b CheckboxFilterBuilder) Equals(v bool) FilterExpr {
+	return b.expr(CheckboxDatabaseQueryFilter{Equals: &v})
+}
+func (b CheckboxFilterBuilder) DoesNotEqual(v bool) FilterExpr {
+	return b.expr(CheckboxDatabaseQueryFilter{DoesNotEqual: &v})
+}
+
+// SelectFilterBuilder builds a filter for select properties.
+type SelectFilterBuilder struct{ b PropFilterBuilder }
+
+// Select returns a builder of a select filter.
+func (b PropFilterBuilder) Select() SelectFilterBuilder { return SelectFilterBuilder{b} }
+
+func (b SelectFilterBuilder) expr(c SelectDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Select = &c })
+}
+
+func (b SelectFilterBuilder) Equals(s string) FilterExpr {
+	return b.expr(SelectDatabaseQueryFilter{Equals: s})
+}
+func (b SelectFilterBuilder) DoesNotEqual(s string) FilterExpr {
+	return b.expr(SelectDatabaseQueryFilter{DoesNotEqual: s})
+}
+func (b SelectFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(SelectDatabaseQueryFilter{IsEmpty: true})
+}
+func (b SelectFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(SelectDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// MultiSelectFilterBuilder builds a filter for multi_select properties.
+type MultiSelectFilterBuilder struct{ b PropFilterBuilder }
+
+// MultiSelect returns a builder of a multi_select filter.
+func (b PropFilterBuilder) MultiSelect() MultiSelectFilterBuilder { return MultiSelectFilterBuilder{b} }
+
+func (b MultiSelectFilterBuilder) expr(c MultiSelectDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.MultiSelect = &c })
+}
+
+func (b MultiSelectFilterBuilder) Contains(s string) FilterExpr {
+	return b.expr(MultiSelectDatabaseQueryFilter{Contains: s})
+}
+func (b MultiSelectFilterBuilder) DoesNotContain(s string) FilterExpr {
+	return b.expr(MultiSelectDatabaseQueryFilter{DoesNotContain: s})
+}
+func (b MultiSelectFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(MultiSelectDatabaseQueryFilter{IsEmpty: true})
+}
+func (b MultiSelectFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(MultiSelectDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// DateFilterBuilder builds a filter for date properties.
+type DateFilterBuilder struct{ b PropFilterBuilder }
+
+// Date returns a builder of a date filter.
+func (b PropFilterBuilder) Date() DateFilterBuilder { return DateFilterBuilder{b} }
+
+func (b DateFilterBuilder) expr(c DateDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Date = &c })
+}
+
+func (b DateFilterBuilder) Equals(t time.Time) FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{Equals: &t})
+}
+func (b DateFilterBuilder) Before(t time.Time) FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{Before: &t})
+}
+func (b DateFilterBuilder) After(t time.Time) FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{After: &t})
+}
+func (b DateFilterBuilder) OnOrBefore(t time.Time) FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{OnOrBefore: &t})
+}
+func (b DateFilterBuilder) OnOrAfter(t time.Time) FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{OnOrAfter: &t})
+}
+func (b DateFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{IsEmpty: true})
+}
+func (b DateFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{IsNotEmpty: true})
+}
+func (b DateFilterBuilder) PastWeek() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{PastWeek: &struct{}{}})
+}
+func (b DateFilterBuilder) PastMonth() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{PastMonth: &struct{}{}})
+}
+func (b DateFilterBuilder) PastYear() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{PastYear: &struct{}{}})
+}
+func (b DateFilterBuilder) NextWeek() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{NextWeek: &struct{}{}})
+}
+func (b DateFilterBuilder) NextMonth() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{NextMonth: &struct{}{}})
+}
+func (b DateFilterBuilder) NextYear() FilterExpr {
+	return b.expr(DateDatabaseQueryFilter{NextYear: &struct{}{}})
+}
+
+// PeopleFilterBuilder builds a filter for people, created_by and
+// last_edited_by properties.
+type PeopleFilterBuilder struct{ b PropFilterBuilder }
+
+// People returns a builder of a people filter.
+func (b PropFilterBuilder) People() PeopleFilterBuilder { return PeopleFilterBuilder{b} }
+
+func (b PeopleFilterBuilder) expr(c PeopleDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.People = &c })
+}
+
+func (b PeopleFilterBuilder) Contains(userID string) FilterExpr {
+	return b.expr(PeopleDatabaseQueryFilter{Contains: userID})
+}
+func (b PeopleFilterBuilder) DoesNotContain(userID string) FilterExpr {
+	return b.expr(PeopleDatabaseQueryFilter{DoesNotContain: userID})
+}
+func (b PeopleFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(PeopleDatabaseQueryFilter{IsEmpty: true})
+}
+func (b PeopleFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(PeopleDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// FilesFilterBuilder builds a filter for files properties.
+type FilesFilterBuilder struct{ b PropFilterBuilder }
+
+// Files returns a builder of a files filter.
+func (b PropFilterBuilder) Files() FilesFilterBuilder { return FilesFilterBuilder{b} }
+
+func (b FilesFilterBuilder) expr(c FilesDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Files = &c })
+}
+
+func (b FilesFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(FilesDatabaseQueryFilter{IsEmpty: true})
+}
+func (b FilesFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(FilesDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// RelationFilterBuilder builds a filter for relation properties.
+type RelationFilterBuilder struct{ b PropFilterBuilder }
+
+// Relation returns a builder of a relation filter.
+func (b PropFilterBuilder) Relation() RelationFilterBuilder { return RelationFilterBuilder{b} }
+
+func (b RelationFilterBuilder) expr(c RelationDatabaseQueryFilter) FilterExpr {
+	return b.b.expr(func(f *DatabaseQueryFilter) { f.Relation = &c })
+}
+
+func (b RelationFilterBuilder) Contains(pageID string) FilterExpr {
+	return b.expr(RelationDatabaseQueryFilter{Contains: pageID})
+}
+func (b RelationFilterBuilder) DoesNotContain(pageID string) FilterExpr {
+	return b.expr(RelationDatabaseQueryFilter{DoesNotContain: pageID})
+}
+func (b RelationFilterBuilder) IsEmpty() FilterExpr {
+	return b.expr(RelationDatabaseQueryFilter{IsEmpty: true})
+}
+func (b RelationFilterBuilder) IsNotEmpty() FilterExpr {
+	return b.expr(RelationDatabaseQueryFilter{IsNotEmpty: true})
+}
+
+// FormulaFilterBuilder builds a filter for formula properties. The type of
+// condition must match the type of formula result.
+type FormulaFilterBuilder struct{ b PropFilterBuilder }
+
+// Formula returns a builder of a formula filter.
+func (b PropFilterBuilder) Formula() FormulaFilterBuilder { return FormulaFilterBuilder{b} }
+
+func (b FormulaFilterBuilder) cond() PropFilterBuilder {
+	return b.b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{Formula: &FormulaDatabaseQueryFilter{
+			Text:     c.Text,
+			Checkbox: c.Checkbox,
+			Number:   c.Number,
+			Date:     c.Date,
+		}}
+	})
+}
+
+func (b FormulaFilterBuilder) Text() TextFilterBuilder         { return b.cond().Text() }
+func (b FormulaFilterBuilder) Checkbox() CheckboxFilterBuilder { return b.cond().Checkbox() }
+func (b FormulaFilterBuilder) Number() NumberFilterBuilder     { return b.cond().Number() }
+func (b FormulaFilterBuilder) Date() DateFilterBuilder         { return b.cond().Date() }
+
+// RollupFilterBuilder builds a filter for rollup properties.
+type RollupFilterBuilder struct{ b PropFilterBuilder }
+
+// Rollup returns a builder of a rollup filter.
+func (b PropFilterBuilder) Rollup() RollupFilterBuilder { return RollupFilterBuilder{b} }
+
+// Any returns a builder of a condition matching rollups where any item
+// matches, e.g. Rollup().Any().Select().Equals("Done").
+func (b RollupFilterBuilder) Any() PropFilterBuilder {
+	return b.b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{Rollup: &RollupDatabaseQueryFilter{Any: &c}}
+	})
+}
+
+// Every returns a builder of a condition matching rollups where every item
+// matches.
+func (b RollupFilterBuilder) Every() PropFilterBuilder {
+	return b.b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{Rollup: &RollupDatabaseQueryFilter{Every: &c}}
+	})
+}
+
+// None returns a builder of a condition matching rollups where no item
+// matches.
+func (b RollupFilterBuilder) None() PropFilterBuilder {
+	return b.b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{Rollup: &RollupDatabaseQueryFilter{None: &c}}
+	})
+}
+
+// Number returns a builder of a filter on rollups calculated to a number.
+func (b RollupFilterBuilder) Number() NumberFilterBuilder {
+	return b.b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{Rollup: &RollupDatabaseQueryFilter{Number: c.Number}}
+	}).Number()
+}
+
+// Date returns a builder of a filter on rollups calculated to a date.
+func (b RollupFilterBuilder) Date() DateFilterBuilder {
+	return b.b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{Rollup: &RollupDatabaseQueryFilter{Date: c.Date}}
+	}).Date()
+}
+
+// CreatedTime returns a builder of a filter for created_time properties. To
+// filter by the creation time of pages without such property use
+// Filter.CreatedTime.
+func (b PropFilterBuilder) CreatedTime() DateFilterBuilder {
+	return b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{CreatedTime: c.Date}
+	}).Date()
+}
+
+// LastEditedTime returns a builder of a filter for last_edited_time
+// properties. To filter by the last edit time of pages without such property
+// use Filter.LastEditedTime.
+func (b PropFilterBuilder) LastEditedTime() DateFilterBuilder {
+	return b.nested(func(c DatabaseQueryFilter) DatabaseQueryFilter {
+		return DatabaseQueryFilter{LastEditedTime: c.Date}
+	}).Date()
+}
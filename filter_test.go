@@ -0,0 +1,350 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	t.Parallel()
+
+	f, err := notion.Filter.Prop("Status").Select().Equals("Done").
+		And(notion.Filter.Prop("Due").Date().PastWeek()).
+		And(notion.Filter.Or(
+			notion.Filter.Prop("Estimate").Number().GreaterThan(3),
+			notion.Filter.Prop("Urgent").Checkbox().Equals(true),
+		)).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	three, yes := 3.0, true
+	exp := &notion.DatabaseQueryFilter{
+		And: []notion.DatabaseQueryFilter{
+			{Property: "Status", Select: &notion.SelectDatabaseQueryFilter{Equals: "Done"}},
+			{Property: "Due", Date: &notion.DateDatabaseQueryFilter{PastWeek: &struct{}{}}},
+			{
+				Or: []notion.DatabaseQueryFilter{
+					{Property: "Estimate", Number: &notion.NumberDatabaseQueryFilter{GreaterThan: &three}},
+					{Property: "Urgent", Checkbox: &notion.CheckboxDatabaseQueryFilter{Equals: &yes}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(exp, f); diff != "" {
+		t.Fatalf("filter not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestFilterJSON(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		filter  notion.FilterExpr
+		expJSON string
+	}{
+		{
+			name:    "formula",
+			filter:  notion.Filter.Prop("Days left").Formula().Number().LessThan(3),
+			expJSON: `{"property":"Days left","formula":{"number":{"less_than":3}}}`,
+		},
+		{
+			name:    "formula text",
+			filter:  notion.Filter.Prop("Full name").Formula().Text().Contains("Doe"),
+			expJSON: `{"property":"Full name","formula":{"text":{"contains":"Doe"}}}`,
+		},
+		{
+			name:    "rollup any",
+			filter:  notion.Filter.Prop("Subtasks").Rollup().Any().Select().Equals("Done"),
+			expJSON: `{"property":"Subtasks","rollup":{"any":{"select":{"equals":"Done"}}}}`,
+		},
+		{
+			name:    "rollup every",
+			filter:  notion.Filter.Prop("Subtasks").Rollup().Every().Checkbox().Equals(true),
+			expJSON: `{"property":"Subtasks","rollup":{"every":{"checkbox":{"equals":true}}}}`,
+		},
+		{
+			name:    "rollup none",
+			filter:  notion.Filter.Prop("Subtasks").Rollup().None().Text().IsEmpty(),
+			expJSON: `{"property":"Subtasks","rollup":{"none":{"text":{"is_empty":true}}}}`,
+		},
+		{
+			name:    "rollup number",
+			filter:  notion.Filter.Prop("Total").Rollup().Number().GreaterThan(10),
+			expJSON: `{"property":"Total","rollup":{"number":{"greater_than":10}}}`,
+		},
+		{
+			name:    "rollup date",
+			filter:  notion.Filter.Prop("Latest").Rollup().Date().PastMonth(),
+			expJSON: `{"property":"Latest","rollup":{"date":{"past_month":{}}}}`,
+		},
+		{
+			name:    "created time timestamp",
+			filter:  notion.Filter.CreatedTime().OnOrAfter(since),
+			expJSON: `{"timestamp":"created_time","created_time":{"on_or_after":"2021-06-01T00:00:00Z"}}`,
+		},
+		{
+			name:    "last edited time timestamp",
+			filter:  notion.Filter.LastEditedTime().PastWeek(),
+			expJSON: `{"timestamp":"last_edited_time","last_edited_time":{"past_week":{}}}`,
+		},
+		{
+			name:    "created time property",
+			filter:  notion.Filter.Prop("Created").CreatedTime().Before(since),
+			expJSON: `{"property":"Created","created_time":{"before":"2021-06-01T00:00:00Z"}}`,
+		},
+		{
+			name:    "compound with timestamp",
+			filter:  notion.Filter.LastEditedTime().PastWeek().And(notion.Filter.Prop("Done").Checkbox().Equals(false)),
+			expJSON: `{"and":[{"timestamp":"last_edited_time","last_edited_time":{"past_week":{}}},{"property":"Done","checkbox":{"equals":false}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := tt.filter.Build()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := json.Marshal(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expJSON, string(b)); diff != "" {
+				t.Fatalf("JSON not equal (-exp, +got):\n%v", diff)
+			}
+
+			var got notion.DatabaseQueryFilter
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(f, &got); diff != "" {
+				t.Fatalf("unmarshaled filter not equal (-exp, +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	t.Parallel()
+
+	leaf := notion.DatabaseQueryFilter{Property: "Name", Text: &notion.TextDatabaseQueryFilter{Contains: "a"}}
+	tests := []struct {
+		name   string
+		filter notion.DatabaseQueryFilter
+		expErr string
+	}{
+		{
+			name:   "valid property filter",
+			filter: leaf,
+		},
+		{
+			name:   "missing property",
+			filter: notion.DatabaseQueryFilter{Text: &notion.TextDatabaseQueryFilter{Contains: "a"}},
+			expErr: "property is required",
+		},
+		{
+			name:   "no condition",
+			filter: notion.DatabaseQueryFilter{Property: "Name"},
+			expErr: `property "Name": filter has no condition`,
+		},
+		{
+			name: "two types of conditions",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Name",
+				Text:     &notion.TextDatabaseQueryFilter{Contains: "a"},
+				Select:   &notion.SelectDatabaseQueryFilter{Equals: "b"},
+			},
+			expErr: `property "Name": filter must have exactly one type of condition`,
+		},
+		{
+			name: "two conditions",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Name",
+				Text:     &notion.TextDatabaseQueryFilter{Contains: "a", StartsWith: "b"},
+			},
+			expErr: `property "Name": text filter must have exactly one condition, has 2`,
+		},
+		{
+			name: "empty condition",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Name",
+				Text:     &notion.TextDatabaseQueryFilter{},
+			},
+			expErr: `property "Name": text filter must have exactly one condition, has 0`,
+		},
+		{
+			name: "and with or",
+			filter: notion.DatabaseQueryFilter{
+				And: []notion.DatabaseQueryFilter{leaf},
+				Or:  []notion.DatabaseQueryFilter{leaf},
+			},
+			expErr: "compound filter must only have one of and, or set",
+		},
+		{
+			name:   "empty and",
+			filter: notion.DatabaseQueryFilter{And: []notion.DatabaseQueryFilter{}},
+			expErr: "and filter is empty",
+		},
+		{
+			name: "nested two levels deep",
+			filter: notion.DatabaseQueryFilter{
+				And: []notion.DatabaseQueryFilter{
+					leaf,
+					{Or: []notion.DatabaseQueryFilter{leaf}},
+				},
+			},
+		},
+		{
+			name: "nested three levels deep",
+			filter: notion.DatabaseQueryFilter{
+				And: []notion.DatabaseQueryFilter{
+					{Or: []notion.DatabaseQueryFilter{
+						{And: []notion.DatabaseQueryFilter{leaf}},
+					}},
+				},
+			},
+			expErr: "and[0]: or[0]: compound filters can't be nested more than 2 levels deep",
+		},
+		{
+			name: "invalid nested filter",
+			filter: notion.DatabaseQueryFilter{
+				Or: []notion.DatabaseQueryFilter{leaf, {Property: "Done"}},
+			},
+			expErr: `or[1]: property "Done": filter has no condition`,
+		},
+		{
+			name: "formula without condition",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Days",
+				Formula:  &notion.FormulaDatabaseQueryFilter{},
+			},
+			expErr: `property "Days": formula filter has no condition`,
+		},
+		{
+			name: "formula with empty condition",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Days",
+				Formula:  &notion.FormulaDatabaseQueryFilter{Number: &notion.NumberDatabaseQueryFilter{}},
+			},
+			expErr: `property "Days": formula number filter must have exactly one condition, has 0`,
+		},
+		{
+			name: "rollup with two conditions",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Subtasks",
+				Rollup: &notion.RollupDatabaseQueryFilter{
+					Any:  &notion.DatabaseQueryFilter{Text: &notion.TextDatabaseQueryFilter{Contains: "a"}},
+					None: &notion.DatabaseQueryFilter{Text: &notion.TextDatabaseQueryFilter{Contains: "b"}},
+				},
+			},
+			expErr: `property "Subtasks": rollup filter must have exactly one type of condition`,
+		},
+		{
+			name: "rollup any with property",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Subtasks",
+				Rollup: &notion.RollupDatabaseQueryFilter{
+					Any: &leaf,
+				},
+			},
+			expErr: `property "Subtasks": rollup any filter must only have a condition`,
+		},
+		{
+			name: "rollup any with invalid condition",
+			filter: notion.DatabaseQueryFilter{
+				Property: "Subtasks",
+				Rollup: &notion.RollupDatabaseQueryFilter{
+					Any: &notion.DatabaseQueryFilter{Select: &notion.SelectDatabaseQueryFilter{}},
+				},
+			},
+			expErr: `property "Subtasks": rollup any select filter must have exactly one condition, has 0`,
+		},
+		{
+			name: "timestamp with mismatched condition",
+			filter: notion.DatabaseQueryFilter{
+				Timestamp:      notion.SortTimeStampCreatedTime,
+				LastEditedTime: &notion.DateDatabaseQueryFilter{IsNotEmpty: true},
+			},
+			expErr: `timestamp "created_time": filter must have created_time condition, has last_edited_time`,
+		},
+		{
+			name: "timestamp with property",
+			filter: notion.DatabaseQueryFilter{
+				Property:    "Created",
+				Timestamp:   notion.SortTimeStampCreatedTime,
+				CreatedTime: &notion.DateDatabaseQueryFilter{IsNotEmpty: true},
+			},
+			expErr: "filter must only have one of property, timestamp set",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.filter.Validate()
+			if tt.expErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expErr != "" && (err == nil || err.Error() != tt.expErr) {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestFilterBuilderFlattensCompound(t *testing.T) {
+	t.Parallel()
+
+	a := notion.Filter.Prop("A").Checkbox().Equals(true)
+	b := notion.Filter.Prop("B").Checkbox().Equals(false)
+	c := notion.Filter.Prop("C").Files().IsEmpty()
+	f, err := a.And(b).And(c).Or(a.Or(b)).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Or) != 3 || len(f.Or[0].And) != 3 {
+		t.Fatalf("expected or[and[a, b, c], a, b], got %+v", f)
+	}
+
+	// empty string is not a valid condition
+	_, err = notion.Filter.Prop("Name").Text().Equals("").Build()
+	exp := `notion: invalid filter: property "Name": text filter must have exactly one condition, has 0`
+	if err == nil || err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+}
+
+func TestQueryDatabaseInvalidFilter(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("unexpected request")
+		}},
+	}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{HTTPClient: httpClient})
+	query := &notion.DatabaseQuery{
+		Filter: &notion.DatabaseQueryFilter{Property: "Name"},
+	}
+	_, err := client.QueryDatabase(context.Background(), "00000000-0000-0000-0000-000000000000", query)
+	exp := `notion: invalid filter: property "Name": filter has no condition`
+	if err == nil || err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+}
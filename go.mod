@@ -3,6 +3,7 @@ module github.com/kjk/notion
 go 1.16
 
 require (
+	github.com/gomarkdown/markdown v0.0.0-20260411013819-759bbc3e3207
 	github.com/google/go-cmp v0.5.5 // indirect
 	github.com/json-iterator/go v1.1.11 // indirect
 	github.com/kjk/u v0.0.0-20210327060556-13ea33918991
@@ -13,4 +14,5 @@ require (
 	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a // indirect
 	golang.org/x/net v0.0.0-20210510120150-4163338589ed // indirect
 	golang.org/x/sys v0.0.0-20210514084401-e8d321eab015 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,93 @@
+package notion
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Hooks are functions called for every HTTP request of a Client, e.g. to
+// add tracing headers, log requests or count API usage. Any of them can be
+// nil. Retried requests call hooks for every attempt.
+type Hooks struct {
+	// BeforeRequest is called before a request is sent. It can change
+	// info.Request e.g. set headers. Returning an error cancels the request.
+	BeforeRequest func(info *RequestInfo) error
+	// AfterResponse is called after every request, including failed ones.
+	AfterResponse func(info *ResponseInfo)
+	// OnError is called after AfterResponse when a request failed, either
+	// without a response or with an error response.
+	OnError func(info *ResponseInfo)
+}
+
+// RequestInfo describes a request, for Hooks.
+type RequestInfo struct {
+	// Op is the operation e.g. "query database".
+	Op string
+	// ResourceID is the ID of a database, page, block or user in the path of
+	// the request, if any.
+	ResourceID string
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+	Request *http.Request
+}
+
+// ResponseInfo describes the result of a request, for Hooks.
+type ResponseInfo struct {
+	RequestInfo
+	// Response is nil if the request failed without a response. Its body
+	// was already read.
+	Response *http.Response
+	// Duration is the time from sending the request until reading the
+	// whole response.
+	Duration time.Duration
+	// Err is the error of a failed request.
+	Err error
+	// APIError is the error returned by the API, if any.
+	APIError *APIError
+}
+
+// resourceID returns the ID following a collection in a path of the API
+// e.g. the database ID in "/v1/databases/{id}/query".
+func resourceID(path string) string {
+	parts := strings.Split(path, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		switch parts[i] {
+		case "databases", "pages", "blocks", "users":
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+func (c *Client) beforeRequest(info *RequestInfo) error {
+	for _, h := range c.hooks {
+		if h.BeforeRequest == nil {
+			continue
+		}
+		if err := h.BeforeRequest(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) afterResponse(info *ResponseInfo) {
+	if info.Err != nil {
+		errors.As(info.Err, &info.APIError)
+	}
+	for _, h := range c.hooks {
+		if h.AfterResponse != nil {
+			h.AfterResponse(info)
+		}
+	}
+	if info.Err == nil {
+		return
+	}
+	for _, h := range c.hooks {
+		if h.OnError != nil {
+			h.OnError(info)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package notion_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+func TestHooks(t *testing.T) {
+	responses := []mockResponse{
+		{status: http.StatusServiceUnavailable, body: `{"object":"error","status":503,"code":"service_unavailable","message":"Unavailable."}`},
+		{status: http.StatusOK, body: `{"object":"user","id":"be32e790-8292-46df-a248-b784fdf483cf","type":"person"}`},
+		{status: http.StatusNotFound, body: `{"object":"error","status":404,"code":"object_not_found","message":"Could not find user."}`},
+	}
+	var traceIDs []string
+	httpClient := &http.Client{
+		Transport: queueTransport(responses, func(r *http.Request) {
+			traceIDs = append(traceIDs, r.Header.Get("X-Trace-Id"))
+		}),
+	}
+
+	type call struct {
+		Hook       string
+		Op         string
+		ResourceID string
+		Attempt    int
+		Status     int
+		ErrorCode  string
+	}
+	var calls []call
+	record := func(hook string) func(info *notion.ResponseInfo) {
+		return func(info *notion.ResponseInfo) {
+			if info.Duration < 0 {
+				t.Errorf("negative duration %v", info.Duration)
+			}
+			c := call{Hook: hook, Op: info.Op, ResourceID: info.ResourceID, Attempt: info.Attempt}
+			if info.Response != nil {
+				c.Status = info.Response.StatusCode
+			}
+			if info.APIError != nil {
+				c.ErrorCode = info.APIError.Code
+			}
+			calls = append(calls, c)
+		}
+	}
+	policy := &notion.RetryPolicy{MaxAttempts: 2, RetryableCodes: []string{"service_unavailable"}}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient: httpClient,
+		Retry:      policy,
+		Hooks: []notion.Hooks{
+			{
+				BeforeRequest: func(info *notion.RequestInfo) error {
+					info.Request.Header.Set("X-Trace-Id", "trace-"+info.ResourceID)
+					return nil
+				},
+			},
+			{
+				AfterResponse: record("after"),
+				OnError:       record("error"),
+			},
+		},
+	})
+
+	userID := "be32e790-8292-46df-a248-b784fdf483cf"
+	if _, err := client.GetUser(context.Background(), userID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetUser(context.Background(), userID); err == nil {
+		t.Fatal("expected error")
+	}
+
+	want := []call{
+		{"after", "find user", userID, 1, 503, "service_unavailable"},
+		{"error", "find user", userID, 1, 503, "service_unavailable"},
+		{"after", "find user", userID, 2, 200, ""},
+		{"after", "find user", userID, 1, 404, "object_not_found"},
+		{"error", "find user", userID, 1, 404, "object_not_found"},
+	}
+	if diff := cmp.Diff(want, calls); diff != "" {
+		t.Errorf("calls not equal (-exp, +got):\n%v", diff)
+	}
+	wantTraceIDs := []string{"trace-" + userID, "trace-" + userID, "trace-" + userID}
+	if diff := cmp.Diff(wantTraceIDs, traceIDs); diff != "" {
+		t.Errorf("trace IDs not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestHooksBeforeRequestError(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			t.Fatal("request should not be sent")
+			return nil, nil
+		}},
+	}
+	errDenied := errors.New("denied")
+	var afterCalled bool
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient: httpClient,
+		Hooks: []notion.Hooks{{
+			BeforeRequest: func(info *notion.RequestInfo) error { return errDenied },
+			AfterResponse: func(info *notion.ResponseInfo) { afterCalled = true },
+		}},
+	})
+
+	_, err := client.GetDatabase(context.Background(), "668d797c-76fa-4934-9b05-ad288df2d136")
+	if !errors.Is(err, errDenied) {
+		t.Fatalf("expected error %v, got %v", errDenied, err)
+	}
+	if want := "notion: failed to find database: denied"; err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err.Error())
+	}
+	if afterCalled {
+		t.Error("AfterResponse should not be called for a canceled request")
+	}
+}
+
+func TestHooksTransportError(t *testing.T) {
+	errNetwork := errors.New("connection refused")
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			time.Sleep(time.Millisecond)
+			return nil, errNetwork
+		}},
+	}
+	var info *notion.ResponseInfo
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient: httpClient,
+		Hooks: []notion.Hooks{{
+			OnError: func(i *notion.ResponseInfo) { info = i },
+		}},
+	})
+
+	_, err := client.GetPage(context.Background(), "b0668f48-8d66-4733-9bdb-2f82215707f7")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if info == nil {
+		t.Fatal("OnError not called")
+	}
+	if info.Response != nil || info.APIError != nil {
+		t.Errorf("expected no response and API error, got %v and %v", info.Response, info.APIError)
+	}
+	if !errors.Is(info.Err, errNetwork) {
+		t.Errorf("expected error %v, got %v", errNetwork, info.Err)
+	}
+	if info.Duration < time.Millisecond {
+		t.Errorf("expected duration >= 1ms, got %v", info.Duration)
+	}
+	if info.Op != "find page" || info.ResourceID != "b0668f48-8d66-4733-9bdb-2f82215707f7" {
+		t.Errorf("unexpected op %q and resource ID %q", info.Op, info.ResourceID)
+	}
+}
@@ -0,0 +1,383 @@
+// Package html renders Notion blocks and rich text as HTML.
+//
+// The output is semantic HTML without inline styles. Notion colors are
+// mapped to CSS classes (see ColorClass) and Notion-specific blocks get
+// "notion-" classes, so the look is controlled by a stylesheet.
+//
+// Rendering of any block type can be replaced with Renderer.Overrides.
+package html
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+// BlockRenderer renders a single block as HTML.
+type BlockRenderer interface {
+	RenderBlock(r *Renderer, b *notion.Block) string
+}
+
+// BlockRendererFunc is an adapter to use a function as a BlockRenderer.
+type BlockRendererFunc func(r *Renderer, b *notion.Block) string
+
+// RenderBlock implements BlockRenderer.
+func (f BlockRendererFunc) RenderBlock(r *Renderer, b *notion.Block) string {
+	return f(r, b)
+}
+
+// Renderer renders Notion blocks as HTML.
+type Renderer struct {
+	// Overrides replaces default rendering of blocks of a given type.
+	// An override can call RenderDefault to wrap the default HTML.
+	Overrides map[notion.BlockType]BlockRenderer
+	// PageURL returns URL of a page or database with a given ID. It's used
+	// for child_page, child_database, link_to_page blocks and page mentions.
+	// Default links to notion.so.
+	PageURL func(id string) string
+}
+
+// PageToHTML renders a page with its blocks, as returned by
+// Client.GetBlockTree, using default Renderer.
+func PageToHTML(page *notion.Page, blocks []notion.Block) []byte {
+	var r Renderer
+	return r.RenderPage(page, blocks)
+}
+
+// BlocksToHTML renders blocks using default Renderer.
+func BlocksToHTML(blocks []notion.Block) []byte {
+	var r Renderer
+	return r.RenderBlocks(blocks)
+}
+
+// RichTextToHTML renders rich text using default Renderer.
+func RichTextToHTML(rts []notion.RichText) string {
+	var r Renderer
+	return r.RenderRichText(rts)
+}
+
+// RenderPage renders page title as <h1> followed by blocks.
+func (r *Renderer) RenderPage(page *notion.Page, blocks []notion.Block) []byte {
+	var sb strings.Builder
+	if title := page.Title(); len(title) > 0 {
+		sb.WriteString(`<h1 class="notion-page-title">` + r.RenderRichText(title) + "</h1>\n")
+	}
+	sb.Write(r.RenderBlocks(blocks))
+	return []byte(sb.String())
+}
+
+// RenderBlocks renders blocks and their children. Consecutive list items
+// are grouped in a list.
+func (r *Renderer) RenderBlocks(blocks []notion.Block) []byte {
+	return []byte(r.renderBlocks(blocks))
+}
+
+// RenderChildren renders children of a block.
+func (r *Renderer) RenderChildren(b *notion.Block) string {
+	return r.renderBlocks(b.ChildBlocks())
+}
+
+func listTag(typ notion.BlockType) string {
+	switch typ {
+	case notion.BlockTypeBulletedListItem:
+		return `<ul>`
+	case notion.BlockTypeNumberedListItem:
+		return `<ol>`
+	case notion.BlockTypeToDo:
+		return `<ul class="notion-to-do">`
+	}
+	return ""
+}
+
+func (r *Renderer) renderBlocks(blocks []notion.Block) string {
+	var sb strings.Builder
+	var list notion.BlockType
+	closeList := func() {
+		if list == notion.BlockTypeNumberedListItem {
+			sb.WriteString("</ol>\n")
+		} else if list != "" {
+			sb.WriteString("</ul>\n")
+		}
+		list = ""
+	}
+	for i := range blocks {
+		b := &blocks[i]
+		if tag := listTag(b.Type); tag != "" {
+			if list != b.Type {
+				closeList()
+				sb.WriteString(tag + "\n")
+				list = b.Type
+			}
+		} else {
+			closeList()
+		}
+		sb.WriteString(r.RenderBlock(b))
+	}
+	closeList()
+	return sb.String()
+}
+
+// RenderBlock renders a block, using an override if there is one for its type.
+func (r *Renderer) RenderBlock(b *notion.Block) string {
+	if o, ok := r.Overrides[b.Type]; ok {
+		return o.RenderBlock(r, b)
+	}
+	return r.RenderDefault(b)
+}
+
+func (r *Renderer) pageURL(id string) string {
+	if r.PageURL != nil {
+		return r.PageURL(id)
+	}
+	return "https://www.notion.so/" + strings.Replace(id, "-", "", -1)
+}
+
+// withChildren appends rendered children of b to s.
+func (r *Renderer) withChildren(s string, b *notion.Block) string {
+	if children := r.RenderChildren(b); children != "" {
+		return s + "\n" + children
+	}
+	return s
+}
+
+// RenderDefault renders a block ignoring Overrides.
+func (r *Renderer) RenderDefault(b *notion.Block) string {
+	switch b.Type {
+	case notion.BlockTypeParagraph:
+		if b.Paragraph == nil {
+			return ""
+		}
+		s := "<p>" + r.RenderRichText(b.Paragraph.Text) + "</p>\n"
+		if children := r.RenderChildren(b); children != "" {
+			s += `<div class="notion-indent">` + "\n" + children + "</div>\n"
+		}
+		return s
+	case notion.BlockTypeHeading1:
+		if b.Heading1 == nil {
+			return ""
+		}
+		return "<h1>" + r.RenderRichText(b.Heading1.Text) + "</h1>\n"
+	case notion.BlockTypeHeading2:
+		if b.Heading2 == nil {
+			return ""
+		}
+		return "<h2>" + r.RenderRichText(b.Heading2.Text) + "</h2>\n"
+	case notion.BlockTypeHeading3:
+		if b.Heading3 == nil {
+			return ""
+		}
+		return "<h3>" + r.RenderRichText(b.Heading3.Text) + "</h3>\n"
+	case notion.BlockTypeBulletedListItem:
+		if b.BulletedListItem == nil {
+			return ""
+		}
+		return "<li>" + r.withChildren(r.RenderRichText(b.BulletedListItem.Text), b) + "</li>\n"
+	case notion.BlockTypeNumberedListItem:
+		if b.NumberedListItem == nil {
+			return ""
+		}
+		return "<li>" + r.withChildren(r.RenderRichText(b.NumberedListItem.Text), b) + "</li>\n"
+	case notion.BlockTypeToDo:
+		if b.ToDo == nil {
+			return ""
+		}
+		checkbox := `<input type="checkbox" disabled>`
+		if b.ToDo.Checked != nil && *b.ToDo.Checked {
+			checkbox = `<input type="checkbox" disabled checked>`
+		}
+		return "<li>" + checkbox + " " + r.withChildren(r.RenderRichText(b.ToDo.Text), b) + "</li>\n"
+	case notion.BlockTypeToggle:
+		if b.Toggle == nil {
+			return ""
+		}
+		return "<details>\n<summary>" + r.RenderRichText(b.Toggle.Text) + "</summary>\n" + r.RenderChildren(b) + "</details>\n"
+	case notion.BlockTypeQuote:
+		if b.Quote == nil {
+			return ""
+		}
+		return "<blockquote>" + r.withChildren(r.RenderRichText(b.Quote.Text), b) + "</blockquote>\n"
+	case notion.BlockTypeCallout:
+		if b.Callout == nil {
+			return ""
+		}
+		icon := ""
+		if b.Callout.Icon != nil {
+			switch {
+			case b.Callout.Icon.Emoji != "":
+				icon = `<span class="notion-callout-icon">` + escape(b.Callout.Icon.Emoji) + "</span>"
+			case b.Callout.Icon.External != nil:
+				icon = `<img class="notion-callout-icon" src="` + escapeURL(b.Callout.Icon.External.URL) + `" alt="">`
+			case b.Callout.Icon.File != nil:
+				icon = `<img class="notion-callout-icon" src="` + escapeURL(b.Callout.Icon.File.URL) + `" alt="">`
+			}
+		}
+		return `<div class="notion-callout">` + icon + "<div>" + r.withChildren(r.RenderRichText(b.Callout.Text), b) + "</div></div>\n"
+	case notion.BlockTypeCode:
+		if b.Code == nil {
+			return ""
+		}
+		class := ""
+		if b.Code.Language != "" && b.Code.Language != "plain text" {
+			class = ` class="language-` + escape(strings.Replace(b.Code.Language, " ", "-", -1)) + `"`
+		}
+		s := "<pre><code" + class + ">" + escape(notion.PlainText(b.Code.Text)) + "</code></pre>\n"
+		return r.figure(s, b.Code.Caption)
+	case notion.BlockTypeEquation:
+		if b.Equation == nil {
+			return ""
+		}
+		return `<div class="notion-equation">` + escape(b.Equation.Expression) + "</div>\n"
+	case notion.BlockTypeDivider:
+		return "<hr>\n"
+	case notion.BlockTypeBookmark:
+		if b.Bookmark == nil {
+			return ""
+		}
+		return `<div class="notion-bookmark">` + r.link(b.Bookmark.Caption, b.Bookmark.URL) + "</div>\n"
+	case notion.BlockTypeEmbed:
+		if b.Embed == nil {
+			return ""
+		}
+		s := `<iframe class="notion-embed" src="` + escapeURL(b.Embed.URL) + `"></iframe>` + "\n"
+		return r.figure(s, b.Embed.Caption)
+	case notion.BlockTypeImage:
+		if b.Image == nil {
+			return ""
+		}
+		alt := escape(notion.PlainText(b.Image.Caption))
+		s := `<img src="` + escapeURL(b.Image.URL()) + `" alt="` + alt + `">` + "\n"
+		return r.figure(s, b.Image.Caption)
+	case notion.BlockTypeVideo:
+		if b.Video == nil {
+			return ""
+		}
+		s := `<video controls src="` + escapeURL(b.Video.URL()) + `"></video>` + "\n"
+		return r.figure(s, b.Video.Caption)
+	case notion.BlockTypeFile:
+		if b.File == nil {
+			return ""
+		}
+		return `<div class="notion-file">` + r.link(b.File.Caption, b.File.URL()) + "</div>\n"
+	case notion.BlockTypePDF:
+		if b.PDF == nil {
+			return ""
+		}
+		return `<div class="notion-pdf">` + r.link(b.PDF.Caption, b.PDF.URL()) + "</div>\n"
+	case notion.BlockTypeChildPage:
+		title := ""
+		if b.ChildPage != nil {
+			title = b.ChildPage.Title
+		}
+		return `<div class="notion-page-link"><a href="` + escapeURL(r.pageURL(b.ID)) + `">` + escape(title) + "</a></div>\n"
+	case notion.BlockTypeChildDatabase:
+		title := ""
+		if b.ChildDatabase != nil {
+			title = b.ChildDatabase.Title
+		}
+		return `<div class="notion-database-link"><a href="` + escapeURL(r.pageURL(b.ID)) + `">` + escape(title) + "</a></div>\n"
+	case notion.BlockTypeLinkToPage:
+		if b.LinkToPage == nil {
+			return ""
+		}
+		id := b.LinkToPage.PageID
+		if b.LinkToPage.Type == notion.LinkToPageTypeDatabase {
+			id = b.LinkToPage.DatabaseID
+		}
+		u := r.pageURL(id)
+		return `<div class="notion-page-link"><a href="` + escapeURL(u) + `">` + escape(u) + "</a></div>\n"
+	case notion.BlockTypeColumnList:
+		return `<div class="notion-column-list">` + "\n" + r.RenderChildren(b) + "</div>\n"
+	case notion.BlockTypeColumn:
+		return `<div class="notion-column">` + "\n" + r.RenderChildren(b) + "</div>\n"
+	case notion.BlockTypeSyncedBlock:
+		return r.RenderChildren(b)
+	case notion.BlockTypeTable:
+		if b.Table == nil {
+			return ""
+		}
+		return r.table(b)
+	}
+	// table_of_contents, breadcrumb, template and unsupported blocks are
+	// not rendered
+	return ""
+}
+
+// figure wraps s in <figure> with a caption, if there is one.
+func (r *Renderer) figure(s string, caption []notion.RichText) string {
+	if len(caption) == 0 {
+		return s
+	}
+	return "<figure>\n" + s + "<figcaption>" + r.RenderRichText(caption) + "</figcaption>\n</figure>\n"
+}
+
+func (r *Renderer) link(caption []notion.RichText, url string) string {
+	text := r.RenderRichText(caption)
+	if text == "" {
+		text = escape(url)
+	}
+	return `<a href="` + escapeURL(url) + `">` + text + "</a>"
+}
+
+func (r *Renderer) table(b *notion.Block) string {
+	var sb strings.Builder
+	sb.WriteString(`<table class="notion-table">` + "\n")
+	rowNo := 0
+	for _, child := range b.ChildBlocks() {
+		if child.Type != notion.BlockTypeTableRow || child.TableRow == nil {
+			continue
+		}
+		isHeader := rowNo == 0 && b.Table.HasColumnHeader
+		if isHeader {
+			sb.WriteString("<thead>\n")
+		} else if rowNo == 0 || (rowNo == 1 && b.Table.HasColumnHeader) {
+			sb.WriteString("<tbody>\n")
+		}
+		sb.WriteString("<tr>")
+		for i, cell := range child.TableRow.Cells {
+			tag := "td"
+			if isHeader || (i == 0 && b.Table.HasRowHeader) {
+				tag = "th"
+			}
+			sb.WriteString(fmt.Sprintf("<%s>%s</%s>", tag, r.RenderRichText(cell), tag))
+		}
+		sb.WriteString("</tr>\n")
+		if isHeader {
+			sb.WriteString("</thead>\n")
+		}
+		rowNo++
+	}
+	if rowNo > 1 || (rowNo == 1 && !b.Table.HasColumnHeader) {
+		sb.WriteString("</tbody>\n")
+	}
+	sb.WriteString("</table>\n")
+	return sb.String()
+}
+
+// ColorClass returns CSS class for a Notion color e.g. "notion-red" for red
+// text and "notion-red-background" for red background. Default color has
+// no class.
+func ColorClass(c notion.Color) string {
+	if c == "" || c == notion.ColorDefault {
+		return ""
+	}
+	return "notion-" + strings.Replace(string(c), "_", "-", -1)
+}
+
+func escape(s string) string {
+	return html.EscapeString(s)
+}
+
+// escapeURL escapes URL for use in an attribute. URLs with schemes that
+// could execute code, like javascript:, are replaced with "#".
+func escapeURL(u string) string {
+	if i := strings.IndexAny(u, ":/?#"); i >= 0 && u[i] == ':' {
+		switch strings.ToLower(u[:i]) {
+		case "http", "https", "mailto", "tel":
+		default:
+			return "#"
+		}
+	}
+	return html.EscapeString(u)
+}
@@ -0,0 +1,301 @@
+package html_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/html"
+)
+
+func text(s string) notion.RichText {
+	return notion.RichText{
+		Type: notion.RichTextTypeText,
+		Text: &notion.Text{Content: s},
+	}
+}
+
+func styled(s string, a notion.Annotations) notion.RichText {
+	rt := text(s)
+	rt.Annotations = &a
+	return rt
+}
+
+func texts(rts ...notion.RichText) []notion.RichText {
+	return rts
+}
+
+func TestRichTextToHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rts  []notion.RichText
+		exp  string
+	}{
+		{
+			name: "plain text is escaped",
+			rts:  texts(text("<script>alert(\"&\")</script>\nnext")),
+			exp:  "&lt;script&gt;alert(&#34;&amp;&#34;)&lt;/script&gt;<br>next",
+		},
+		{
+			name: "annotations",
+			rts: texts(
+				styled("bold", notion.Annotations{Bold: true}),
+				text(" "),
+				styled("italic", notion.Annotations{Italic: true}),
+				text(" "),
+				styled("strike", notion.Annotations{Strikethrough: true}),
+				text(" "),
+				styled("under", notion.Annotations{Underline: true}),
+				text(" "),
+				styled("a<b", notion.Annotations{Code: true}),
+				text(" "),
+				styled("all", notion.Annotations{Bold: true, Italic: true}),
+			),
+			exp: "<strong>bold</strong> <em>italic</em> <s>strike</s> <u>under</u> <code>a&lt;b</code> <strong><em>all</em></strong>",
+		},
+		{
+			name: "colors",
+			rts: texts(
+				styled("red", notion.Annotations{Color: notion.ColorRed}),
+				styled("bg", notion.Annotations{Color: notion.ColorYellowBg}),
+				styled("default", notion.Annotations{Color: notion.ColorDefault}),
+			),
+			exp: `<span class="notion-red">red</span><span class="notion-yellow-background">bg</span>default`,
+		},
+		{
+			name: "links",
+			rts: texts(
+				notion.RichText{
+					Type: notion.RichTextTypeText,
+					Text: &notion.Text{Content: "Go", Link: &notion.Link{URL: "https://golang.org/?a=1&b=2"}},
+				},
+				notion.RichText{
+					Type: notion.RichTextTypeText,
+					Text: &notion.Text{Content: "bad", Link: &notion.Link{URL: "javascript:alert(1)"}},
+				},
+			),
+			exp: `<a href="https://golang.org/?a=1&amp;b=2">Go</a><a href="#">bad</a>`,
+		},
+		{
+			name: "mentions",
+			rts: texts(
+				notion.RichText{
+					Type:      notion.RichTextTypeMention,
+					PlainText: "Lorem ipsum",
+					Mention: &notion.Mention{
+						Type: notion.MentionTypePage,
+						Page: &notion.ID{ID: "b0668f48-8d66-4733-9bdb-2f82215707f7"},
+					},
+				},
+				text(" by "),
+				notion.RichText{
+					Type: notion.RichTextTypeMention,
+					Mention: &notion.Mention{
+						Type: notion.MentionTypeUser,
+						User: &notion.User{Name: "John"},
+					},
+				},
+			),
+			exp: `<a href="https://www.notion.so/b0668f488d6647339bdb2f82215707f7">Lorem ipsum</a> by <span class="notion-mention">@John</span>`,
+		},
+		{
+			name: "equation",
+			rts:  texts(notion.RichText{Type: notion.RichTextTypeEquation, Equation: &notion.Equation{Expression: "a<b"}}),
+			exp:  `<span class="notion-equation">a&lt;b</span>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := html.RichTextToHTML(tt.rts)
+			if diff := cmp.Diff(tt.exp, got); diff != "" {
+				t.Fatalf("html not equal (-exp, +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestColorClass(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		color notion.Color
+		exp   string
+	}{
+		{color: "", exp: ""},
+		{color: notion.ColorDefault, exp: ""},
+		{color: notion.ColorGray, exp: "notion-gray"},
+		{color: notion.ColorPinkBg, exp: "notion-pink-background"},
+	}
+	for _, tt := range tests {
+		if got := html.ColorClass(tt.color); got != tt.exp {
+			t.Errorf("ColorClass(%q): expected %q, got %q", tt.color, tt.exp, got)
+		}
+	}
+}
+
+func TestBlocksToHTML(t *testing.T) {
+	t.Parallel()
+
+	checked := true
+	blocks := []notion.Block{
+		{
+			Type:     notion.BlockTypeHeading2,
+			Heading2: &notion.Heading{Text: texts(text("Title"))},
+		},
+		{
+			Type:             notion.BlockTypeBulletedListItem,
+			BulletedListItem: &notion.RichTextBlock{Text: texts(text("one"))},
+			Children: []notion.Block{
+				{
+					Type:             notion.BlockTypeNumberedListItem,
+					NumberedListItem: &notion.RichTextBlock{Text: texts(text("nested"))},
+				},
+			},
+		},
+		{
+			Type:             notion.BlockTypeBulletedListItem,
+			BulletedListItem: &notion.RichTextBlock{Text: texts(text("two"))},
+		},
+		{
+			Type: notion.BlockTypeToDo,
+			ToDo: &notion.ToDo{RichTextBlock: notion.RichTextBlock{Text: texts(text("done"))}, Checked: &checked},
+		},
+		{
+			Type:   notion.BlockTypeToggle,
+			Toggle: &notion.RichTextBlock{Text: texts(text("More"))},
+			Children: []notion.Block{
+				{
+					Type:      notion.BlockTypeParagraph,
+					Paragraph: &notion.RichTextBlock{Text: texts(text("hidden"))},
+				},
+			},
+		},
+		{
+			Type: notion.BlockTypeCode,
+			Code: &notion.Code{Text: texts(text("if a < b {}")), Language: "go"},
+		},
+		{
+			Type: notion.BlockTypeCallout,
+			Callout: &notion.Callout{
+				RichTextBlock: notion.RichTextBlock{Text: texts(text("Note"))},
+				Icon:          &notion.Icon{Type: notion.IconTypeEmoji, Emoji: "💡"},
+			},
+		},
+		{
+			Type:    notion.BlockTypeDivider,
+			Divider: &notion.Divider{},
+		},
+		{
+			Type: notion.BlockTypeImage,
+			Image: &notion.FileObject{
+				Type:     notion.FileTypeExternal,
+				External: &notion.ExternalFile{URL: "https://example.com/a.png"},
+				Caption:  texts(text("diagram")),
+			},
+		},
+		{
+			Type:      notion.BlockTypeChildPage,
+			ID:        "b0668f48-8d66-4733-9bdb-2f82215707f7",
+			ChildPage: &notion.ChildPage{Title: "Sub & page"},
+		},
+		{
+			Type:  notion.BlockTypeTable,
+			Table: &notion.Table{TableWidth: 2, HasColumnHeader: true},
+			Children: []notion.Block{
+				{
+					Type:     notion.BlockTypeTableRow,
+					TableRow: &notion.TableRow{Cells: [][]notion.RichText{texts(text("Name")), texts(text("Value"))}},
+				},
+				{
+					Type:     notion.BlockTypeTableRow,
+					TableRow: &notion.TableRow{Cells: [][]notion.RichText{texts(text("a")), texts(text("1"))}},
+				},
+			},
+		},
+	}
+
+	exp := "<h2>Title</h2>\n" +
+		"<ul>\n" +
+		"<li>one\n" +
+		"<ol>\n" +
+		"<li>nested</li>\n" +
+		"</ol>\n" +
+		"</li>\n" +
+		"<li>two</li>\n" +
+		"</ul>\n" +
+		`<ul class="notion-to-do">` + "\n" +
+		`<li><input type="checkbox" disabled checked> done</li>` + "\n" +
+		"</ul>\n" +
+		"<details>\n" +
+		"<summary>More</summary>\n" +
+		"<p>hidden</p>\n" +
+		"</details>\n" +
+		`<pre><code class="language-go">if a &lt; b {}</code></pre>` + "\n" +
+		`<div class="notion-callout"><span class="notion-callout-icon">💡</span><div>Note</div></div>` + "\n" +
+		"<hr>\n" +
+		"<figure>\n" +
+		`<img src="https://example.com/a.png" alt="diagram">` + "\n" +
+		"<figcaption>diagram</figcaption>\n" +
+		"</figure>\n" +
+		`<div class="notion-page-link"><a href="https://www.notion.so/b0668f488d6647339bdb2f82215707f7">Sub &amp; page</a></div>` + "\n" +
+		`<table class="notion-table">` + "\n" +
+		"<thead>\n" +
+		"<tr><th>Name</th><th>Value</th></tr>\n" +
+		"</thead>\n" +
+		"<tbody>\n" +
+		"<tr><td>a</td><td>1</td></tr>\n" +
+		"</tbody>\n" +
+		"</table>\n"
+
+	got := string(html.BlocksToHTML(blocks))
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("html not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestRendererOverrides(t *testing.T) {
+	t.Parallel()
+
+	r := html.Renderer{
+		Overrides: map[notion.BlockType]html.BlockRenderer{
+			notion.BlockTypeCode: html.BlockRendererFunc(func(r *html.Renderer, b *notion.Block) string {
+				return `<div class="highlight">` + strings.TrimSuffix(r.RenderDefault(b), "\n") + "</div>\n"
+			}),
+			notion.BlockTypeDivider: html.BlockRendererFunc(func(r *html.Renderer, b *notion.Block) string {
+				return ""
+			}),
+		},
+		PageURL: func(id string) string {
+			return "/p/" + id
+		},
+	}
+	blocks := []notion.Block{
+		{
+			Type: notion.BlockTypeCode,
+			Code: &notion.Code{Text: texts(text("x")), Language: "plain text"},
+		},
+		{
+			Type:    notion.BlockTypeDivider,
+			Divider: &notion.Divider{},
+		},
+		{
+			Type:      notion.BlockTypeChildPage,
+			ID:        "abc",
+			ChildPage: &notion.ChildPage{Title: "Sub"},
+		},
+	}
+
+	exp := `<div class="highlight"><pre><code>x</code></pre></div>` + "\n" +
+		`<div class="notion-page-link"><a href="/p/abc">Sub</a></div>` + "\n"
+	got := string(r.RenderBlocks(blocks))
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("html not equal (-exp, +got):\n%v", diff)
+	}
+}
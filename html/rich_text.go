@@ -0,0 +1,96 @@
+package html
+
+import (
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+// RenderRichText renders rich text as inline HTML.
+func (r *Renderer) RenderRichText(rts []notion.RichText) string {
+	var sb strings.Builder
+	for i := range rts {
+		sb.WriteString(r.renderRichText(&rts[i]))
+	}
+	return sb.String()
+}
+
+func (r *Renderer) renderRichText(rt *notion.RichText) string {
+	var s, href string
+	switch {
+	case rt.Type == notion.RichTextTypeEquation || (rt.Type == "" && rt.Equation != nil):
+		if rt.Equation == nil {
+			return ""
+		}
+		s = `<span class="notion-equation">` + escape(rt.Equation.Expression) + "</span>"
+	case rt.Type == notion.RichTextTypeMention || (rt.Type == "" && rt.Mention != nil):
+		s, href = r.mention(rt)
+	default:
+		s = escapeText(notion.PlainText([]notion.RichText{*rt}))
+		if rt.Text != nil && rt.Text.Link != nil {
+			href = rt.Text.Link.URL
+		} else if rt.HRef != nil {
+			href = *rt.HRef
+		}
+	}
+	if s == "" {
+		return ""
+	}
+
+	if a := rt.Annotations; a != nil {
+		if a.Code {
+			s = "<code>" + s + "</code>"
+		}
+		if a.Strikethrough {
+			s = "<s>" + s + "</s>"
+		}
+		if a.Underline {
+			s = "<u>" + s + "</u>"
+		}
+		if a.Italic {
+			s = "<em>" + s + "</em>"
+		}
+		if a.Bold {
+			s = "<strong>" + s + "</strong>"
+		}
+		if class := ColorClass(a.Color); class != "" {
+			s = `<span class="` + class + `">` + s + "</span>"
+		}
+	}
+	if href != "" {
+		s = `<a href="` + escapeURL(href) + `">` + s + "</a>"
+	}
+	return s
+}
+
+// mention returns HTML and link of a mention.
+func (r *Renderer) mention(rt *notion.RichText) (string, string) {
+	m := rt.Mention
+	text := rt.PlainText
+	if m == nil {
+		return escapeText(text), ""
+	}
+	switch m.Type {
+	case notion.MentionTypeUser:
+		if text == "" && m.User != nil {
+			text = "@" + m.User.Name
+		}
+		return `<span class="notion-mention">` + escape(text) + "</span>", ""
+	case notion.MentionTypePage:
+		if m.Page != nil {
+			return escapeText(text), r.pageURL(m.Page.ID)
+		}
+	case notion.MentionTypeDatabase:
+		if m.Database != nil {
+			return escapeText(text), r.pageURL(m.Database.ID)
+		}
+	case notion.MentionTypeDate:
+		return `<time class="notion-mention">` + escape(text) + "</time>", ""
+	}
+	return escapeText(text), ""
+}
+
+// escapeText escapes text and converts newlines to <br>.
+func escapeText(s string) string {
+	return strings.Replace(escape(s), "\n", "<br>", -1)
+}
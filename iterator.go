@@ -0,0 +1,303 @@
+package notion
+
+import "context"
+
+// pager implements cursor based pagination shared by all iterators.
+// fetch gets a batch of results starting at cursor and returns the number
+// of results in the batch.
+type pager struct {
+	fetch func(ctx context.Context, cursor string) (n int, nextCursor string, hasMore bool, err error)
+
+	cursor  string
+	n       int
+	idx     int
+	started bool
+	hasMore bool
+	err     error
+}
+
+func newPager(startCursor string) pager {
+	return pager{
+		cursor: startCursor,
+		idx:    -1,
+	}
+}
+
+func (p *pager) next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	p.idx++
+	for p.idx >= p.n {
+		if p.started && !p.hasMore {
+			return false
+		}
+		n, nextCursor, hasMore, err := p.fetch(ctx, p.cursor)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.started = true
+		p.n = n
+		p.idx = 0
+		p.cursor = nextCursor
+		p.hasMore = hasMore && nextCursor != ""
+	}
+	return true
+}
+
+// nextCursor returns the cursor of the first batch that wasn't fetched yet.
+func (p *pager) nextCursor() string {
+	if p.started && !p.hasMore {
+		return ""
+	}
+	return p.cursor
+}
+
+// PageIterator iterates over all pages returned by a database query,
+// fetching more results as needed.
+//
+//	it := client.QueryDatabaseIterator(dbID, nil)
+//	for it.Next(ctx) {
+//		page := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	pager
+	results []Page
+}
+
+// QueryDatabaseIterator returns an iterator over all pages matching the query.
+// query.StartCursor, if set, is where the iteration starts.
+func (c *Client) QueryDatabaseIterator(id string, query *DatabaseQuery) *PageIterator {
+	var q DatabaseQuery
+	if query != nil {
+		q = *query
+	}
+	it := &PageIterator{pager: newPager(q.StartCursor)}
+	it.fetch = func(ctx context.Context, cursor string) (int, string, bool, error) {
+		q.StartCursor = cursor
+		res, err := c.QueryDatabase(ctx, id, &q)
+		if err != nil {
+			return 0, "", false, err
+		}
+		it.results = res.Results
+		return len(res.Results), res.NextCursor, res.HasMore, nil
+	}
+	return it
+}
+
+// Next advances to the next page. It returns false when there are no more
+// pages or an error happened. Check Err to tell the two apart.
+func (it *PageIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Value returns the current page.
+func (it *PageIterator) Value() *Page {
+	return &it.results[it.idx]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the cursor of the first batch of results that wasn't
+// fetched yet, to resume the iteration later with DatabaseQuery.StartCursor.
+// Results already fetched are not included. Returns "" if there are no more
+// results.
+func (it *PageIterator) Cursor() string {
+	return it.nextCursor()
+}
+
+// All returns all remaining pages.
+func (it *PageIterator) All(ctx context.Context) ([]Page, error) {
+	var res []Page
+	for it.Next(ctx) {
+		res = append(res, *it.Value())
+	}
+	return res, it.Err()
+}
+
+// BlockIterator iterates over all children of a block, fetching more results
+// as needed.
+type BlockIterator struct {
+	pager
+	results []Block
+}
+
+// GetBlockChildrenIterator returns an iterator over all children of a block.
+// query.StartCursor, if set, is where the iteration starts.
+func (c *Client) GetBlockChildrenIterator(blockID string, query *PaginationQuery) *BlockIterator {
+	var q PaginationQuery
+	if query != nil {
+		q = *query
+	}
+	it := &BlockIterator{pager: newPager(q.StartCursor)}
+	it.fetch = func(ctx context.Context, cursor string) (int, string, bool, error) {
+		q.StartCursor = cursor
+		res, err := c.GetBlockChildren(ctx, blockID, &q)
+		if err != nil {
+			return 0, "", false, err
+		}
+		it.results = res.Results
+		return len(res.Results), res.NextCursor, res.HasMore, nil
+	}
+	return it
+}
+
+// Next advances to the next block. It returns false when there are no more
+// blocks or an error happened. Check Err to tell the two apart.
+func (it *BlockIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Value returns the current block.
+func (it *BlockIterator) Value() *Block {
+	return &it.results[it.idx]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *BlockIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the cursor of the first batch of results that wasn't
+// fetched yet, to resume the iteration later with PaginationQuery.StartCursor.
+// Results already fetched are not included. Returns "" if there are no more
+// results.
+func (it *BlockIterator) Cursor() string {
+	return it.nextCursor()
+}
+
+// All returns all remaining blocks.
+func (it *BlockIterator) All(ctx context.Context) ([]Block, error) {
+	var res []Block
+	for it.Next(ctx) {
+		res = append(res, *it.Value())
+	}
+	return res, it.Err()
+}
+
+// UserIterator iterates over all users, fetching more results as needed.
+type UserIterator struct {
+	pager
+	results []User
+}
+
+// ListUsersIterator returns an iterator over all users.
+// query.StartCursor, if set, is where the iteration starts.
+func (c *Client) ListUsersIterator(query *PaginationQuery) *UserIterator {
+	var q PaginationQuery
+	if query != nil {
+		q = *query
+	}
+	it := &UserIterator{pager: newPager(q.StartCursor)}
+	it.fetch = func(ctx context.Context, cursor string) (int, string, bool, error) {
+		q.StartCursor = cursor
+		res, err := c.ListUsers(ctx, &q)
+		if err != nil {
+			return 0, "", false, err
+		}
+		it.results = res.Results
+		return len(res.Results), res.NextCursor, res.HasMore, nil
+	}
+	return it
+}
+
+// Next advances to the next user. It returns false when there are no more
+// users or an error happened. Check Err to tell the two apart.
+func (it *UserIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Value returns the current user.
+func (it *UserIterator) Value() *User {
+	return &it.results[it.idx]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *UserIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the cursor of the first batch of results that wasn't
+// fetched yet, to resume the iteration later with PaginationQuery.StartCursor.
+// Results already fetched are not included. Returns "" if there are no more
+// results.
+func (it *UserIterator) Cursor() string {
+	return it.nextCursor()
+}
+
+// All returns all remaining users.
+func (it *UserIterator) All(ctx context.Context) ([]User, error) {
+	var res []User
+	for it.Next(ctx) {
+		res = append(res, *it.Value())
+	}
+	return res, it.Err()
+}
+
+// SearchIterator iterates over all search results, fetching more results
+// as needed.
+type SearchIterator struct {
+	pager
+	results SearchResults
+}
+
+// SearchIterator returns an iterator over all search results.
+// opts.StartCursor, if set, is where the iteration starts.
+func (c *Client) SearchIterator(opts *SearchOpts) *SearchIterator {
+	var o SearchOpts
+	if opts != nil {
+		o = *opts
+	}
+	it := &SearchIterator{pager: newPager(o.StartCursor)}
+	it.fetch = func(ctx context.Context, cursor string) (int, string, bool, error) {
+		o.StartCursor = cursor
+		res, err := c.Search(ctx, &o)
+		if err != nil {
+			return 0, "", false, err
+		}
+		it.results = res.Results
+		return len(res.Results), res.NextCursor, res.HasMore, nil
+	}
+	return it
+}
+
+// Next advances to the next result. It returns false when there are no more
+// results or an error happened. Check Err to tell the two apart.
+func (it *SearchIterator) Next(ctx context.Context) bool {
+	return it.next(ctx)
+}
+
+// Value returns the current result, either *Page or *Database.
+func (it *SearchIterator) Value() interface{} {
+	return it.results[it.idx]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the cursor of the first batch of results that wasn't
+// fetched yet, to resume the iteration later with SearchOpts.StartCursor.
+// Results already fetched are not included. Returns "" if there are no more
+// results.
+func (it *SearchIterator) Cursor() string {
+	return it.nextCursor()
+}
+
+// All returns all remaining results.
+func (it *SearchIterator) All(ctx context.Context) (SearchResults, error) {
+	var res SearchResults
+	for it.Next(ctx) {
+		res = append(res, it.Value())
+	}
+	return res, it.Err()
+}
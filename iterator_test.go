@@ -0,0 +1,137 @@
+package notion_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kjk/notion"
+)
+
+// blockChildrenPages serves 5 paragraph blocks in batches of 2.
+func blockChildrenPages(t *testing.T, requests *[]string) *http.Client {
+	return &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			cursor := r.URL.Query().Get("start_cursor")
+			*requests = append(*requests, cursor)
+
+			start := 0
+			if cursor != "" {
+				_, err := fmt.Sscanf(cursor, "cursor-%d", &start)
+				if err != nil {
+					t.Fatalf("invalid cursor %q", cursor)
+				}
+			}
+			var blocks []string
+			for i := start; i < start+2 && i < 5; i++ {
+				blocks = append(blocks, fmt.Sprintf(`{"object": "block", "id": "block-%d", "type": "paragraph", "paragraph": {"text": []}}`, i))
+			}
+			next := start + 2
+			hasMore := next < 5
+			nextCursor := "null"
+			if hasMore {
+				nextCursor = fmt.Sprintf(`"cursor-%d"`, next)
+			}
+			body := fmt.Sprintf(`{"object": "list", "results": [%s], "next_cursor": %s, "has_more": %v}`, strings.Join(blocks, ","), nextCursor, hasMore)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		}},
+	}
+}
+
+func TestBlockIterator(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	opts := notion.ClientOptions{
+		HTTPClient: blockChildrenPages(t, &requests),
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	ctx := context.Background()
+
+	blocks, err := client.GetBlockChildrenIterator("parent", nil).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, b := range blocks {
+		ids = append(ids, b.ID)
+	}
+	if got, exp := strings.Join(ids, ","), "block-0,block-1,block-2,block-3,block-4"; got != exp {
+		t.Fatalf("blocks not equal (expected: %s, got: %s)", exp, got)
+	}
+	if got, exp := strings.Join(requests, ","), ",cursor-2,cursor-4"; got != exp {
+		t.Fatalf("cursors not equal (expected: %s, got: %s)", exp, got)
+	}
+}
+
+func TestBlockIteratorResume(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	opts := notion.ClientOptions{
+		HTTPClient: blockChildrenPages(t, &requests),
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	ctx := context.Background()
+
+	it := client.GetBlockChildrenIterator("parent", nil)
+	for i := 0; i < 2; i++ {
+		if !it.Next(ctx) {
+			t.Fatalf("unexpected end of iteration: %v", it.Err())
+		}
+	}
+	cursor := it.Cursor()
+	if cursor != "cursor-2" {
+		t.Fatalf("cursor not equal (expected: cursor-2, got: %s)", cursor)
+	}
+
+	it = client.GetBlockChildrenIterator("parent", &notion.PaginationQuery{StartCursor: cursor})
+	blocks, err := it.All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(blocks) != 3 || blocks[0].ID != "block-2" {
+		t.Fatalf("unexpected blocks after resume: %+v", blocks)
+	}
+	if it.Cursor() != "" {
+		t.Fatalf("expected empty cursor at the end, got %q", it.Cursor())
+	}
+}
+
+func TestPageIteratorError(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Status:     http.StatusText(http.StatusNotFound),
+				Body: ioutil.NopCloser(strings.NewReader(`{
+					"object": "error",
+					"status": 404,
+					"code": "object_not_found",
+					"message": "foobar"
+				}`)),
+			}, nil
+		}},
+	}
+	opts := notion.ClientOptions{
+		HTTPClient: httpClient,
+	}
+	client := notion.NewClient("secret-api-key", &opts)
+	it := client.QueryDatabaseIterator("00000000-0000-0000-0000-000000000000", nil)
+	if it.Next(context.Background()) {
+		t.Fatalf("expected no results")
+	}
+	if !errors.Is(it.Err(), notion.ErrObjectNotFound) {
+		t.Fatalf("error not equal (expected: %v, got: %v)", notion.ErrObjectNotFound, it.Err())
+	}
+}
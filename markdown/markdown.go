@@ -0,0 +1,370 @@
+// Package markdown converts Notion pages to Markdown and Markdown to Notion
+// blocks.
+//
+// Rendering produces CommonMark with GitHub Flavored Markdown extensions
+// (tables, task lists, strikethrough). Blocks that have no Markdown
+// equivalent are rendered as inline HTML (toggles, underline) or skipped
+// (table of contents, breadcrumb).
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+// Renderer renders Notion blocks as Markdown.
+type Renderer struct {
+	// PageURL returns URL of a page or database with a given ID. It's used
+	// for child_page, child_database and link_to_page blocks.
+	// Default links to notion.so.
+	PageURL func(id string) string
+}
+
+// PageToMarkdown renders a page with its blocks, as returned by
+// Client.GetBlockTree, using default Renderer.
+func PageToMarkdown(page *notion.Page, blocks []notion.Block) []byte {
+	var r Renderer
+	return r.RenderPage(page, blocks)
+}
+
+// BlocksToMarkdown renders blocks using default Renderer.
+func BlocksToMarkdown(blocks []notion.Block) []byte {
+	var r Renderer
+	return r.RenderBlocks(blocks)
+}
+
+// RichTextToMarkdown renders rich text using default Renderer.
+func RichTextToMarkdown(rts []notion.RichText) string {
+	var r Renderer
+	return r.RenderRichText(rts)
+}
+
+// RenderPage renders page title as a top-level heading followed by blocks.
+func (r *Renderer) RenderPage(page *notion.Page, blocks []notion.Block) []byte {
+	var sb strings.Builder
+	if title := r.renderLine(page.Title(), " "); title != "" {
+		sb.WriteString("# " + title + "\n\n")
+	}
+	sb.Write(r.RenderBlocks(blocks))
+	return []byte(sb.String())
+}
+
+// RenderBlocks renders blocks and their children.
+func (r *Renderer) RenderBlocks(blocks []notion.Block) []byte {
+	s := r.renderBlocks(blocks)
+	if s == "" {
+		return nil
+	}
+	return []byte(s + "\n")
+}
+
+func (r *Renderer) pageURL(id string) string {
+	if r.PageURL != nil {
+		return r.PageURL(id)
+	}
+	return "https://www.notion.so/" + strings.Replace(id, "-", "", -1)
+}
+
+func isListItem(b *notion.Block) bool {
+	switch b.Type {
+	case notion.BlockTypeBulletedListItem, notion.BlockTypeNumberedListItem, notion.BlockTypeToDo:
+		return true
+	}
+	return false
+}
+
+// renderBlocks renders blocks separated by blank lines, except for items of
+// the same list. The result has no trailing newline.
+func (r *Renderer) renderBlocks(blocks []notion.Block) string {
+	var sb strings.Builder
+	var prev *notion.Block
+	listNo := 0
+	for i := range blocks {
+		b := &blocks[i]
+		if b.Type == notion.BlockTypeNumberedListItem {
+			if prev != nil && prev.Type == notion.BlockTypeNumberedListItem {
+				listNo++
+			} else {
+				listNo = 1
+			}
+		}
+		s := r.renderBlock(b, listNo)
+		if s == "" {
+			continue
+		}
+		if prev != nil {
+			if isListItem(prev) && prev.Type == b.Type {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(s)
+		prev = b
+	}
+	return sb.String()
+}
+
+// indent prefixes every non-empty line, except the first one, with prefix.
+func indent(s string, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prefixLines prefixes every line with prefix, trimming trailing whitespace
+// for empty lines.
+func prefixLines(s string, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withChildren appends rendered children of b, indented with prefix.
+func (r *Renderer) withChildren(s string, b *notion.Block, prefix string) string {
+	blocks := b.ChildBlocks()
+	children := r.renderBlocks(blocks)
+	if children == "" {
+		return s
+	}
+	sep := "\n\n"
+	if isListItem(&blocks[0]) {
+		// keeps the list tight
+		sep = "\n"
+	}
+	return indent(s+sep+children, prefix)
+}
+
+func (r *Renderer) renderBlock(b *notion.Block, listNo int) string {
+	switch b.Type {
+	case notion.BlockTypeParagraph:
+		if b.Paragraph == nil {
+			return ""
+		}
+		s := escapeLineStart(r.RenderRichText(b.Paragraph.Text))
+		// indented paragraph would be a code block, so children of
+		// a paragraph are rendered after it
+		if children := r.renderBlocks(b.ChildBlocks()); children != "" {
+			s += "\n\n" + children
+		}
+		return s
+	case notion.BlockTypeHeading1:
+		if b.Heading1 == nil {
+			return ""
+		}
+		return "# " + r.renderLine(b.Heading1.Text, " ")
+	case notion.BlockTypeHeading2:
+		if b.Heading2 == nil {
+			return ""
+		}
+		return "## " + r.renderLine(b.Heading2.Text, " ")
+	case notion.BlockTypeHeading3:
+		if b.Heading3 == nil {
+			return ""
+		}
+		return "### " + r.renderLine(b.Heading3.Text, " ")
+	case notion.BlockTypeBulletedListItem:
+		if b.BulletedListItem == nil {
+			return ""
+		}
+		return r.withChildren("- "+r.RenderRichText(b.BulletedListItem.Text), b, "  ")
+	case notion.BlockTypeNumberedListItem:
+		if b.NumberedListItem == nil {
+			return ""
+		}
+		marker := fmt.Sprintf("%d. ", listNo)
+		s := marker + r.RenderRichText(b.NumberedListItem.Text)
+		return r.withChildren(s, b, strings.Repeat(" ", len(marker)))
+	case notion.BlockTypeToDo:
+		if b.ToDo == nil {
+			return ""
+		}
+		marker := "- [ ] "
+		if b.ToDo.Checked != nil && *b.ToDo.Checked {
+			marker = "- [x] "
+		}
+		return r.withChildren(marker+r.RenderRichText(b.ToDo.Text), b, "  ")
+	case notion.BlockTypeToggle:
+		if b.Toggle == nil {
+			return ""
+		}
+		s := "<details>\n<summary>" + r.RenderRichText(b.Toggle.Text) + "</summary>"
+		if children := r.renderBlocks(b.ChildBlocks()); children != "" {
+			s += "\n\n" + children
+		}
+		return s + "\n\n</details>"
+	case notion.BlockTypeQuote:
+		if b.Quote == nil {
+			return ""
+		}
+		s := r.RenderRichText(b.Quote.Text)
+		if children := r.renderBlocks(b.ChildBlocks()); children != "" {
+			s += "\n\n" + children
+		}
+		return prefixLines(s, "> ")
+	case notion.BlockTypeCallout:
+		if b.Callout == nil {
+			return ""
+		}
+		s := r.RenderRichText(b.Callout.Text)
+		if b.Callout.Icon != nil && b.Callout.Icon.Emoji != "" {
+			s = b.Callout.Icon.Emoji + " " + s
+		}
+		if children := r.renderBlocks(b.ChildBlocks()); children != "" {
+			s += "\n\n" + children
+		}
+		return prefixLines(s, "> ")
+	case notion.BlockTypeCode:
+		if b.Code == nil {
+			return ""
+		}
+		return codeFence(notion.PlainText(b.Code.Text), b.Code.Language)
+	case notion.BlockTypeEquation:
+		if b.Equation == nil {
+			return ""
+		}
+		return "$$\n" + b.Equation.Expression + "\n$$"
+	case notion.BlockTypeDivider:
+		return "---"
+	case notion.BlockTypeBookmark:
+		if b.Bookmark == nil {
+			return ""
+		}
+		return r.link(b.Bookmark.Caption, b.Bookmark.URL)
+	case notion.BlockTypeEmbed:
+		if b.Embed == nil {
+			return ""
+		}
+		return r.link(b.Embed.Caption, b.Embed.URL)
+	case notion.BlockTypeImage:
+		if b.Image == nil {
+			return ""
+		}
+		alt := escapeText(notion.PlainText(b.Image.Caption))
+		return "![" + alt + "](" + escapeURL(b.Image.URL()) + ")"
+	case notion.BlockTypeVideo:
+		if b.Video == nil {
+			return ""
+		}
+		return r.link(b.Video.Caption, b.Video.URL())
+	case notion.BlockTypeFile:
+		if b.File == nil {
+			return ""
+		}
+		return r.link(b.File.Caption, b.File.URL())
+	case notion.BlockTypePDF:
+		if b.PDF == nil {
+			return ""
+		}
+		return r.link(b.PDF.Caption, b.PDF.URL())
+	case notion.BlockTypeChildPage:
+		title := ""
+		if b.ChildPage != nil {
+			title = escapeText(b.ChildPage.Title)
+		}
+		return "[" + title + "](" + escapeURL(r.pageURL(b.ID)) + ")"
+	case notion.BlockTypeChildDatabase:
+		title := ""
+		if b.ChildDatabase != nil {
+			title = escapeText(b.ChildDatabase.Title)
+		}
+		return "[" + title + "](" + escapeURL(r.pageURL(b.ID)) + ")"
+	case notion.BlockTypeLinkToPage:
+		if b.LinkToPage == nil {
+			return ""
+		}
+		id := b.LinkToPage.PageID
+		if b.LinkToPage.Type == notion.LinkToPageTypeDatabase {
+			id = b.LinkToPage.DatabaseID
+		}
+		u := r.pageURL(id)
+		return "[" + escapeText(u) + "](" + escapeURL(u) + ")"
+	case notion.BlockTypeTable:
+		if b.Table == nil {
+			return ""
+		}
+		return r.table(b)
+	case notion.BlockTypeColumnList, notion.BlockTypeColumn, notion.BlockTypeSyncedBlock:
+		return r.renderBlocks(b.ChildBlocks())
+	}
+	// table_of_contents, breadcrumb, template and unsupported blocks have
+	// no Markdown equivalent
+	return ""
+}
+
+func (r *Renderer) link(caption []notion.RichText, url string) string {
+	text := r.RenderRichText(caption)
+	if text == "" {
+		text = escapeText(url)
+	}
+	return "[" + text + "](" + escapeURL(url) + ")"
+}
+
+// codeFence returns code in a fenced block. The fence is longer than any
+// run of backticks in the code.
+func codeFence(code string, language string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if language == "plain text" {
+		language = ""
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+// table renders a table block as GFM table. GFM tables require a header
+// row, so the first row is used as header even if the table doesn't have
+// a column header.
+func (r *Renderer) table(b *notion.Block) string {
+	var rows [][]string
+	width := b.Table.TableWidth
+	for _, child := range b.ChildBlocks() {
+		if child.Type != notion.BlockTypeTableRow || child.TableRow == nil {
+			continue
+		}
+		var row []string
+		for _, cell := range child.TableRow.Cells {
+			s := r.renderLine(cell, "<br>")
+			s = strings.Replace(s, "|", `\|`, -1)
+			row = append(row, s)
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 || width == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		sb.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+	}
+	writeRow(rows[0])
+	sb.WriteString("\n|")
+	for i := 0; i < width; i++ {
+		sb.WriteString(" --- |")
+	}
+	for _, row := range rows[1:] {
+		sb.WriteString("\n")
+		writeRow(row)
+	}
+	return sb.String()
+}
@@ -0,0 +1,386 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/markdown"
+)
+
+func text(s string) notion.RichText {
+	return notion.RichText{
+		Type: notion.RichTextTypeText,
+		Text: &notion.Text{Content: s},
+	}
+}
+
+func styled(s string, a notion.Annotations) notion.RichText {
+	rt := text(s)
+	rt.Annotations = &a
+	return rt
+}
+
+func texts(rts ...notion.RichText) []notion.RichText {
+	return rts
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestRichTextToMarkdown(t *testing.T) {
+	t.Parallel()
+
+	href := "https://www.notion.so/b0668f488d6647339bdb2f82215707f7"
+	tests := []struct {
+		name string
+		rts  []notion.RichText
+		exp  string
+	}{
+		{
+			name: "plain text is escaped",
+			rts:  texts(text("a*b_c [d] `e` $5")),
+			exp:  "a\\*b\\_c \\[d\\] \\`e\\` \\$5",
+		},
+		{
+			name: "annotations",
+			rts: texts(
+				styled("bold", notion.Annotations{Bold: true}),
+				text(" "),
+				styled("italic", notion.Annotations{Italic: true}),
+				text(" "),
+				styled("strike", notion.Annotations{Strikethrough: true}),
+				text(" "),
+				styled("under", notion.Annotations{Underline: true}),
+				text(" "),
+				styled("a*b", notion.Annotations{Code: true}),
+				text(" "),
+				styled("all", notion.Annotations{Bold: true, Italic: true}),
+			),
+			exp: "**bold** *italic* ~~strike~~ <u>under</u> `a*b` ***all***",
+		},
+		{
+			name: "whitespace is moved outside of delimiters",
+			rts:  texts(text("a"), styled(" bold ", notion.Annotations{Bold: true}), text("b")),
+			exp:  "a **bold** b",
+		},
+		{
+			name: "adjacent runs with the same formatting are merged",
+			rts:  texts(styled("foo", notion.Annotations{Bold: true, Color: notion.ColorRed}), styled("bar", notion.Annotations{Bold: true})),
+			exp:  "**foobar**",
+		},
+		{
+			name: "code with backticks",
+			rts:  texts(styled("a`b", notion.Annotations{Code: true})),
+			exp:  "``a`b``",
+		},
+		{
+			name: "link",
+			rts: texts(notion.RichText{
+				Type: notion.RichTextTypeText,
+				Text: &notion.Text{Content: "Go", Link: &notion.Link{URL: "https://golang.org"}},
+			}),
+			exp: "[Go](https://golang.org)",
+		},
+		{
+			name: "mentions",
+			rts: texts(
+				notion.RichText{
+					Type:      notion.RichTextTypeMention,
+					PlainText: "Lorem ipsum",
+					HRef:      &href,
+					Mention: &notion.Mention{
+						Type: notion.MentionTypePage,
+						Page: &notion.ID{ID: "b0668f48-8d66-4733-9bdb-2f82215707f7"},
+					},
+				},
+				text(" by "),
+				notion.RichText{
+					Type: notion.RichTextTypeMention,
+					Mention: &notion.Mention{
+						Type: notion.MentionTypeUser,
+						User: &notion.User{Name: "John"},
+					},
+				},
+			),
+			exp: "[Lorem ipsum](https://www.notion.so/b0668f488d6647339bdb2f82215707f7) by @John",
+		},
+		{
+			name: "equations",
+			rts: texts(
+				notion.RichText{Type: notion.RichTextTypeEquation, Equation: &notion.Equation{Expression: "a^2"}},
+				text(" + "),
+				notion.RichText{Type: notion.RichTextTypeEquation, Equation: &notion.Equation{Expression: "b^2"}},
+			),
+			exp: "$a^2$ + $b^2$",
+		},
+		{
+			name: "mentions and equations in code",
+			rts: texts(
+				notion.RichText{
+					Type:        notion.RichTextTypeMention,
+					PlainText:   "@John",
+					Annotations: &notion.Annotations{Code: true},
+					Mention: &notion.Mention{
+						Type: notion.MentionTypeUser,
+						User: &notion.User{Name: "John"},
+					},
+				},
+				text(" "),
+				notion.RichText{
+					Type:        notion.RichTextTypeEquation,
+					Annotations: &notion.Annotations{Code: true},
+					Equation:    &notion.Equation{Expression: "a*b"},
+				},
+			),
+			exp: "`@John` `a*b`",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := markdown.RichTextToMarkdown(tt.rts)
+			if diff := cmp.Diff(tt.exp, got); diff != "" {
+				t.Fatalf("markdown not equal (-exp, +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestBlocksToMarkdown(t *testing.T) {
+	t.Parallel()
+
+	blocks := []notion.Block{
+		{
+			Type:     notion.BlockTypeHeading1,
+			Heading1: &notion.Heading{Text: texts(text("Title"))},
+		},
+		{
+			Type:      notion.BlockTypeParagraph,
+			Paragraph: &notion.RichTextBlock{Text: texts(text("1. not a list"))},
+		},
+		{
+			Type:             notion.BlockTypeBulletedListItem,
+			BulletedListItem: &notion.RichTextBlock{Text: texts(text("one"))},
+			Children: []notion.Block{
+				{
+					Type:             notion.BlockTypeBulletedListItem,
+					BulletedListItem: &notion.RichTextBlock{Text: texts(text("nested"))},
+				},
+			},
+		},
+		{
+			Type:             notion.BlockTypeBulletedListItem,
+			BulletedListItem: &notion.RichTextBlock{Text: texts(text("two"))},
+		},
+		{
+			Type:             notion.BlockTypeNumberedListItem,
+			NumberedListItem: &notion.RichTextBlock{Text: texts(text("first"))},
+		},
+		{
+			Type: notion.BlockTypeNumberedListItem,
+			NumberedListItem: &notion.RichTextBlock{
+				Text: texts(text("second")),
+				Children: []notion.Block{
+					{
+						Type:      notion.BlockTypeParagraph,
+						Paragraph: &notion.RichTextBlock{Text: texts(text("details"))},
+					},
+				},
+			},
+		},
+		{
+			Type: notion.BlockTypeToDo,
+			ToDo: &notion.ToDo{RichTextBlock: notion.RichTextBlock{Text: texts(text("done"))}, Checked: boolPtr(true)},
+		},
+		{
+			Type: notion.BlockTypeToDo,
+			ToDo: &notion.ToDo{RichTextBlock: notion.RichTextBlock{Text: texts(text("todo"))}},
+		},
+		{
+			Type:   notion.BlockTypeToggle,
+			Toggle: &notion.RichTextBlock{Text: texts(text("More"))},
+			Children: []notion.Block{
+				{
+					Type:      notion.BlockTypeParagraph,
+					Paragraph: &notion.RichTextBlock{Text: texts(text("hidden"))},
+				},
+			},
+		},
+		{
+			Type: notion.BlockTypeCode,
+			Code: &notion.Code{Text: texts(text("fmt.Println(\"*\")")), Language: "go"},
+		},
+		{
+			Type:  notion.BlockTypeQuote,
+			Quote: &notion.RichTextBlock{Text: texts(text("line 1\nline 2"))},
+		},
+		{
+			Type: notion.BlockTypeCallout,
+			Callout: &notion.Callout{
+				RichTextBlock: notion.RichTextBlock{Text: texts(text("Note"))},
+				Icon:          &notion.Icon{Type: notion.IconTypeEmoji, Emoji: "💡"},
+			},
+		},
+		{
+			Type:     notion.BlockTypeEquation,
+			Equation: &notion.Equation{Expression: "e=mc^2"},
+		},
+		{
+			Type:    notion.BlockTypeDivider,
+			Divider: &notion.Divider{},
+		},
+		{
+			Type: notion.BlockTypeImage,
+			Image: &notion.FileObject{
+				Type:     notion.FileTypeExternal,
+				External: &notion.ExternalFile{URL: "https://example.com/a b.png"},
+				Caption:  texts(text("diagram")),
+			},
+		},
+		{
+			Type:      notion.BlockTypeChildPage,
+			ID:        "b0668f48-8d66-4733-9bdb-2f82215707f7",
+			ChildPage: &notion.ChildPage{Title: "Sub-page"},
+		},
+		{
+			Type:  notion.BlockTypeTable,
+			Table: &notion.Table{TableWidth: 2, HasColumnHeader: true},
+			Children: []notion.Block{
+				{
+					Type:     notion.BlockTypeTableRow,
+					TableRow: &notion.TableRow{Cells: [][]notion.RichText{texts(text("Name")), texts(text("Value"))}},
+				},
+				{
+					Type:     notion.BlockTypeTableRow,
+					TableRow: &notion.TableRow{Cells: [][]notion.RichText{texts(text("a|b")), texts(styled("1", notion.Annotations{Bold: true}))}},
+				},
+			},
+		},
+		{
+			Type:            notion.BlockTypeTableOfContents,
+			TableOfContents: &notion.TableOfContents{},
+		},
+	}
+
+	exp := "# Title\n" +
+		"\n" +
+		"1\\. not a list\n" +
+		"\n" +
+		"- one\n" +
+		"  - nested\n" +
+		"- two\n" +
+		"\n" +
+		"1. first\n" +
+		"2. second\n" +
+		"\n" +
+		"   details\n" +
+		"\n" +
+		"- [x] done\n" +
+		"- [ ] todo\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>More</summary>\n" +
+		"\n" +
+		"hidden\n" +
+		"\n" +
+		"</details>\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"*\")\n" +
+		"```\n" +
+		"\n" +
+		"> line 1\\\n" +
+		"> line 2\n" +
+		"\n" +
+		"> 💡 Note\n" +
+		"\n" +
+		"$$\n" +
+		"e=mc^2\n" +
+		"$$\n" +
+		"\n" +
+		"---\n" +
+		"\n" +
+		"![diagram](https://example.com/a%20b.png)\n" +
+		"\n" +
+		"[Sub-page](https://www.notion.so/b0668f488d6647339bdb2f82215707f7)\n" +
+		"\n" +
+		"| Name | Value |\n" +
+		"| --- | --- |\n" +
+		"| a\\|b | **1** |\n"
+
+	got := string(markdown.BlocksToMarkdown(blocks))
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("markdown not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestPageToMarkdown(t *testing.T) {
+	t.Parallel()
+
+	page := &notion.Page{
+		Properties: notion.DatabasePageProperties{
+			"Name": notion.DatabasePageProperty{
+				Type:  notion.DBPropTypeTitle,
+				Title: texts(text("My page")),
+			},
+		},
+	}
+	blocks := []notion.Block{
+		{
+			Type:      notion.BlockTypeParagraph,
+			Paragraph: &notion.RichTextBlock{Text: texts(text("Hello"))},
+		},
+	}
+	got := string(markdown.PageToMarkdown(page, blocks))
+	if diff := cmp.Diff("# My page\n\nHello\n", got); diff != "" {
+		t.Fatalf("markdown not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestBlocksToMarkdownLineBreaks(t *testing.T) {
+	t.Parallel()
+
+	blocks := []notion.Block{
+		{
+			Type:     notion.BlockTypeHeading2,
+			Heading2: &notion.Heading{Text: texts(text("Two\nlines "), styled("code\nspan", notion.Annotations{Code: true}))},
+		},
+		{
+			Type:      notion.BlockTypeParagraph,
+			Paragraph: &notion.RichTextBlock{Text: texts(text("line 1\nline 2"))},
+		},
+		{
+			Type:  notion.BlockTypeTable,
+			Table: &notion.Table{TableWidth: 2, HasColumnHeader: true},
+			Children: []notion.Block{
+				{
+					Type:     notion.BlockTypeTableRow,
+					TableRow: &notion.TableRow{Cells: [][]notion.RichText{texts(text("Name")), texts(text("Notes"))}},
+				},
+				{
+					Type:     notion.BlockTypeTableRow,
+					TableRow: &notion.TableRow{Cells: [][]notion.RichText{texts(text("a")), texts(text("first\nsecond*"))}},
+				},
+			},
+		},
+	}
+
+	exp := "## Two lines `code span`\n" +
+		"\n" +
+		"line 1\\\n" +
+		"line 2\n" +
+		"\n" +
+		"| Name | Notes |\n" +
+		"| --- | --- |\n" +
+		"| a | first<br>second\\* |\n"
+
+	got := string(markdown.BlocksToMarkdown(blocks))
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("markdown not equal (-exp, +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,700 @@
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/kjk/notion"
+)
+
+const (
+	// MaxTextLength is the maximum length of text content of a single rich
+	// text run accepted by Notion API, in UTF-16 code units.
+	MaxTextLength = 2000
+	// MaxChildrenPerRequest is the maximum number of blocks that can be
+	// sent in a single create page or append block children request.
+	MaxChildrenPerRequest = 100
+)
+
+// ToBlocks converts Markdown to Notion blocks. Nested lists and content of
+// quotes are stored as children in the type-specific object (e.g.
+// RichTextBlock.Children), which is how they are sent to the API.
+//
+// Text runs longer than MaxTextLength are split. Use Append to send the
+// result in requests within the limits of the API.
+func ToBlocks(md []byte) []notion.Block {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.MathJax)
+	doc := p.Parse(md)
+	return convertBlocks(doc.GetChildren())
+}
+
+// Chunk splits blocks into chunks of at most size blocks. If size is <= 0,
+// MaxChildrenPerRequest is used.
+//
+// Notion limits the number of blocks sent in one request, so e.g. the first
+// chunk can be sent with Client.CreatePage and the rest with Append. Only
+// top-level blocks are split; Append also handles nested children.
+func Chunk(blocks []notion.Block, size int) [][]notion.Block {
+	if size <= 0 {
+		size = MaxChildrenPerRequest
+	}
+	var res [][]notion.Block
+	for len(blocks) > size {
+		res = append(res, blocks[:size])
+		blocks = blocks[size:]
+	}
+	if len(blocks) > 0 {
+		res = append(res, blocks)
+	}
+	return res
+}
+
+// maxNesting is the number of levels of children that can be sent in one
+// request, below top-level blocks.
+const maxNesting = 2
+
+// Append appends blocks to a page or a block, in chunks of
+// MaxChildrenPerRequest blocks. Children that can't be sent in the same
+// request as their parent, because there are more than
+// MaxChildrenPerRequest of them or they are nested too deep, are appended
+// after the parent is created.
+//
+// Rows of a table must be sent with the table, so tables with more than
+// MaxChildrenPerRequest rows are split into several tables, with the
+// column header repeated in each.
+func Append(ctx context.Context, c *notion.Client, blockID string, blocks []notion.Block) error {
+	for _, chunk := range Chunk(splitTables(blocks), MaxChildrenPerRequest) {
+		sent := make([]notion.Block, len(chunk))
+		deferred := false
+		for i, b := range chunk {
+			sent[i] = trimChildren(b, maxNesting)
+			deferred = deferred || hasDeferred(b, sent[i])
+		}
+		created, err := c.AppendBlockChildrenList(ctx, blockID, sent)
+		if err != nil {
+			return err
+		}
+		if !deferred {
+			continue
+		}
+		if len(created) != len(sent) {
+			return fmt.Errorf("markdown: expected %d appended blocks in %s, got %d", len(sent), blockID, len(created))
+		}
+		for i := range chunk {
+			if err := appendDeferred(ctx, c, created[i].ID, chunk[i], sent[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// appendDeferred appends children of block b that were trimmed from sent,
+// the block created with ID id.
+func appendDeferred(ctx context.Context, c *notion.Client, id string, b, sent notion.Block) error {
+	children, sentChildren := b.ChildBlocks(), sent.ChildBlocks()
+	if len(children) == 0 {
+		return nil
+	}
+	if len(sentChildren) == 0 {
+		return Append(ctx, c, id, children)
+	}
+	if !hasDeferred(b, sent) {
+		return nil
+	}
+	created, err := c.GetBlockChildrenIterator(id, nil).All(ctx)
+	if err != nil {
+		return err
+	}
+	if len(created) != len(children) {
+		return fmt.Errorf("markdown: expected %d children of %s, found %d", len(children), id, len(created))
+	}
+	for i := range children {
+		if err := appendDeferred(ctx, c, created[i].ID, children[i], sentChildren[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trimChildren returns a copy of b without children that can't be sent in
+// the same request: more than MaxChildrenPerRequest children or children
+// nested more than levels deep. Rows of a table are never trimmed, instead
+// children are trimmed one level higher, so that the table is appended
+// later with its rows.
+func trimChildren(b notion.Block, levels int) notion.Block {
+	children := b.ChildBlocks()
+	if len(children) == 0 || b.Table != nil {
+		return b
+	}
+	if levels <= 1 && hasTable(children) {
+		return withChildren(b, nil)
+	}
+	if levels == 0 || len(children) > MaxChildrenPerRequest {
+		return withChildren(b, nil)
+	}
+	trimmed := make([]notion.Block, len(children))
+	for i, child := range children {
+		trimmed[i] = trimChildren(child, levels-1)
+	}
+	return withChildren(b, trimmed)
+}
+
+func hasTable(blocks []notion.Block) bool {
+	for _, b := range blocks {
+		if b.Table != nil && len(b.ChildBlocks()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTables returns blocks with tables that have more than
+// MaxChildrenPerRequest rows split into several tables. Tables in children
+// are split too.
+func splitTables(blocks []notion.Block) []notion.Block {
+	var res []notion.Block
+	for _, b := range blocks {
+		rows := b.ChildBlocks()
+		if b.Table == nil {
+			if len(rows) > 0 {
+				b = withChildren(b, splitTables(rows))
+			}
+			res = append(res, b)
+			continue
+		}
+		var header []notion.Block
+		if b.Table.HasColumnHeader && len(rows) > 0 {
+			header, rows = rows[:1], rows[1:]
+		}
+		size := MaxChildrenPerRequest - len(header)
+		for {
+			n := size
+			if n > len(rows) {
+				n = len(rows)
+			}
+			part := append(append([]notion.Block{}, header...), rows[:n]...)
+			res = append(res, withChildren(b, part))
+			rows = rows[n:]
+			if len(rows) == 0 {
+				break
+			}
+		}
+	}
+	return res
+}
+
+// hasDeferred returns true if sent, b trimmed by trimChildren, is missing
+// some of the children of b.
+func hasDeferred(b, sent notion.Block) bool {
+	children, sentChildren := b.ChildBlocks(), sent.ChildBlocks()
+	if len(children) != len(sentChildren) {
+		return true
+	}
+	for i := range children {
+		if hasDeferred(children[i], sentChildren[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// withChildren returns a copy of b with children set in the type-specific
+// object, the counterpart of Block.ChildBlocks.
+func withChildren(b notion.Block, children []notion.Block) notion.Block {
+	b.Children = nil
+	switch {
+	case b.Paragraph != nil:
+		c := *b.Paragraph
+		c.Children, b.Paragraph = children, &c
+	case b.BulletedListItem != nil:
+		c := *b.BulletedListItem
+		c.Children, b.BulletedListItem = children, &c
+	case b.NumberedListItem != nil:
+		c := *b.NumberedListItem
+		c.Children, b.NumberedListItem = children, &c
+	case b.ToDo != nil:
+		c := *b.ToDo
+		c.Children, b.ToDo = children, &c
+	case b.Toggle != nil:
+		c := *b.Toggle
+		c.Children, b.Toggle = children, &c
+	case b.Callout != nil:
+		c := *b.Callout
+		c.Children, b.Callout = children, &c
+	case b.Quote != nil:
+		c := *b.Quote
+		c.Children, b.Quote = children, &c
+	case b.Template != nil:
+		c := *b.Template
+		c.Children, b.Template = children, &c
+	case b.ColumnList != nil:
+		c := *b.ColumnList
+		c.Children, b.ColumnList = children, &c
+	case b.Column != nil:
+		c := *b.Column
+		c.Children, b.Column = children, &c
+	case b.SyncedBlock != nil:
+		c := *b.SyncedBlock
+		c.Children, b.SyncedBlock = children, &c
+	case b.Table != nil:
+		c := *b.Table
+		c.Children, b.Table = children, &c
+	}
+	return b
+}
+
+func convertBlocks(nodes []ast.Node) []notion.Block {
+	var res []notion.Block
+	for _, node := range nodes {
+		res = append(res, convertBlock(node)...)
+	}
+	return res
+}
+
+func convertBlock(node ast.Node) []notion.Block {
+	switch n := node.(type) {
+	case *ast.Heading:
+		h := &notion.Heading{Text: convertInline(n)}
+		switch n.Level {
+		case 1:
+			return []notion.Block{{Object: "block", Type: notion.BlockTypeHeading1, Heading1: h}}
+		case 2:
+			return []notion.Block{{Object: "block", Type: notion.BlockTypeHeading2, Heading2: h}}
+		default:
+			// Notion only has 3 levels of headings
+			return []notion.Block{{Object: "block", Type: notion.BlockTypeHeading3, Heading3: h}}
+		}
+	case *ast.Paragraph:
+		if img := onlyImage(n); img != nil {
+			return []notion.Block{{
+				Object: "block",
+				Type:   notion.BlockTypeImage,
+				Image: &notion.FileObject{
+					Type:     notion.FileTypeExternal,
+					External: &notion.ExternalFile{URL: string(img.Destination)},
+					Caption:  convertInline(img),
+				},
+			}}
+		}
+		return []notion.Block{{
+			Object:    "block",
+			Type:      notion.BlockTypeParagraph,
+			Paragraph: &notion.RichTextBlock{Text: convertInline(n)},
+		}}
+	case *ast.List:
+		var res []notion.Block
+		for _, item := range n.Children {
+			if li, ok := item.(*ast.ListItem); ok {
+				res = append(res, convertListItem(li))
+			}
+		}
+		return res
+	case *ast.BlockQuote:
+		text, children := splitFirstParagraph(n.Children)
+		return []notion.Block{{
+			Object: "block",
+			Type:   notion.BlockTypeQuote,
+			Quote:  &notion.RichTextBlock{Text: text, Children: children},
+		}}
+	case *ast.CodeBlock:
+		code := strings.TrimSuffix(string(n.Literal), "\n")
+		return []notion.Block{{
+			Object: "block",
+			Type:   notion.BlockTypeCode,
+			Code: &notion.Code{
+				Text:     splitLongRuns([]notion.RichText{textRun(code, notion.Annotations{}, "")}),
+				Language: notionLanguage(string(n.Info)),
+			},
+		}}
+	case *ast.MathBlock:
+		return []notion.Block{{
+			Object:   "block",
+			Type:     notion.BlockTypeEquation,
+			Equation: &notion.Equation{Expression: strings.TrimSpace(string(n.Literal))},
+		}}
+	case *ast.HorizontalRule:
+		return []notion.Block{{Object: "block", Type: notion.BlockTypeDivider, Divider: &notion.Divider{}}}
+	case *ast.Table:
+		return []notion.Block{convertTable(n)}
+	case *ast.HTMLBlock:
+		if b, ok := convertDetails(n.Literal); ok {
+			return []notion.Block{b}
+		}
+		text := strings.TrimSpace(string(n.Literal))
+		if text == "" {
+			return nil
+		}
+		return []notion.Block{{
+			Object:    "block",
+			Type:      notion.BlockTypeParagraph,
+			Paragraph: &notion.RichTextBlock{Text: splitLongRuns([]notion.RichText{textRun(text, notion.Annotations{}, "")})},
+		}}
+	}
+	return nil
+}
+
+// onlyImage returns the image if it's the only content of a paragraph.
+func onlyImage(p *ast.Paragraph) *ast.Image {
+	var img *ast.Image
+	for _, child := range p.Children {
+		switch c := child.(type) {
+		case *ast.Image:
+			if img != nil {
+				return nil
+			}
+			img = c
+		case *ast.Text:
+			if strings.TrimSpace(string(c.Literal)) != "" {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return img
+}
+
+// splitFirstParagraph returns text of the first paragraph and the rest of
+// nodes as blocks.
+func splitFirstParagraph(nodes []ast.Node) ([]notion.RichText, []notion.Block) {
+	if len(nodes) == 0 {
+		return []notion.RichText{}, nil
+	}
+	p, ok := nodes[0].(*ast.Paragraph)
+	if !ok {
+		return []notion.RichText{}, convertBlocks(nodes)
+	}
+	return convertInline(p), convertBlocks(nodes[1:])
+}
+
+var taskRx = regexp.MustCompile(`^\[([ xX])\]\s+`)
+
+func convertListItem(li *ast.ListItem) notion.Block {
+	text, children := splitFirstParagraph(li.Children)
+
+	// task list items start with "[ ] " or "[x] "
+	if len(text) > 0 && text[0].Text != nil {
+		if m := taskRx.FindStringSubmatch(text[0].Text.Content); m != nil {
+			checked := m[1] != " "
+			text[0].Text.Content = text[0].Text.Content[len(m[0]):]
+			return notion.Block{
+				Object: "block",
+				Type:   notion.BlockTypeToDo,
+				ToDo: &notion.ToDo{
+					RichTextBlock: notion.RichTextBlock{Text: text, Children: children},
+					Checked:       &checked,
+				},
+			}
+		}
+	}
+
+	content := &notion.RichTextBlock{Text: text, Children: children}
+	if li.ListFlags&ast.ListTypeOrdered != 0 {
+		return notion.Block{Object: "block", Type: notion.BlockTypeNumberedListItem, NumberedListItem: content}
+	}
+	return notion.Block{Object: "block", Type: notion.BlockTypeBulletedListItem, BulletedListItem: content}
+}
+
+func convertTable(t *ast.Table) notion.Block {
+	var rows []notion.Block
+	width := 0
+	hasHeader := false
+	ast.WalkFunc(t, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if _, ok := row.Parent.(*ast.TableHeader); ok {
+			hasHeader = true
+		}
+		var cells [][]notion.RichText
+		for _, cell := range row.Children {
+			cells = append(cells, convertInline(cell))
+		}
+		if len(cells) > width {
+			width = len(cells)
+		}
+		rows = append(rows, notion.Block{
+			Object:   "block",
+			Type:     notion.BlockTypeTableRow,
+			TableRow: &notion.TableRow{Cells: cells},
+		})
+		return ast.SkipChildren
+	})
+	return notion.Block{
+		Object: "block",
+		Type:   notion.BlockTypeTable,
+		Table: &notion.Table{
+			TableWidth:      width,
+			HasColumnHeader: hasHeader,
+			Children:        rows,
+		},
+	}
+}
+
+var detailsRx = regexp.MustCompile(`(?s)^\s*<details>\s*<summary>(.*?)</summary>(.*?)</details>\s*$`)
+
+// convertDetails converts <details> HTML block, which is how toggles are
+// rendered as Markdown, back to a toggle block.
+func convertDetails(html []byte) (notion.Block, bool) {
+	m := detailsRx.FindSubmatch(html)
+	if m == nil {
+		return notion.Block{}, false
+	}
+	var text []notion.RichText
+	if blocks := ToBlocks(m[1]); len(blocks) > 0 && blocks[0].Paragraph != nil {
+		text = blocks[0].Paragraph.Text
+	}
+	return notion.Block{
+		Object: "block",
+		Type:   notion.BlockTypeToggle,
+		Toggle: &notion.RichTextBlock{
+			Text:     text,
+			Children: ToBlocks(bytes.TrimSpace(m[2])),
+		},
+	}, true
+}
+
+// inlineConverter converts inline Markdown nodes to rich text runs.
+type inlineConverter struct {
+	annotations notion.Annotations
+	link        string
+	res         []notion.RichText
+}
+
+// convertInline returns rich text of inline children of a node.
+func convertInline(node ast.Node) []notion.RichText {
+	c := &inlineConverter{}
+	for _, child := range node.GetChildren() {
+		c.convert(child)
+	}
+	if c.res == nil {
+		return []notion.RichText{}
+	}
+	return splitLongRuns(c.res)
+}
+
+func (c *inlineConverter) add(rt notion.RichText) {
+	if n := len(c.res); n > 0 && rt.Text != nil {
+		prev := &c.res[n-1]
+		if prev.Text != nil && sameFormatting(prev, &rt) {
+			prev.Text.Content += rt.Text.Content
+			return
+		}
+	}
+	c.res = append(c.res, rt)
+}
+
+func sameFormatting(a, b *notion.RichText) bool {
+	var annA, annB notion.Annotations
+	if a.Annotations != nil {
+		annA = *a.Annotations
+	}
+	if b.Annotations != nil {
+		annB = *b.Annotations
+	}
+	return annA == annB && linkOf(a) == linkOf(b)
+}
+
+func linkOf(rt *notion.RichText) string {
+	if rt.Text != nil && rt.Text.Link != nil {
+		return rt.Text.Link.URL
+	}
+	return ""
+}
+
+func (c *inlineConverter) convertChildren(node ast.Node) {
+	for _, child := range node.GetChildren() {
+		c.convert(child)
+	}
+}
+
+func (c *inlineConverter) convert(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Text:
+		// soft line breaks are rendered as spaces
+		s := strings.Replace(string(n.Literal), "\n", " ", -1)
+		if s != "" {
+			c.add(textRun(s, c.annotations, c.link))
+		}
+	case *ast.Softbreak:
+		c.add(textRun(" ", c.annotations, c.link))
+	case *ast.Hardbreak:
+		c.add(textRun("\n", c.annotations, c.link))
+	case *ast.Code:
+		a := c.annotations
+		a.Code = true
+		c.add(textRun(string(n.Literal), a, c.link))
+	case *ast.Math:
+		c.add(notion.RichText{
+			Type:     notion.RichTextTypeEquation,
+			Equation: &notion.Equation{Expression: string(n.Literal)},
+		})
+	case *ast.Strong:
+		prev := c.annotations
+		c.annotations.Bold = true
+		c.convertChildren(n)
+		c.annotations = prev
+	case *ast.Emph:
+		prev := c.annotations
+		c.annotations.Italic = true
+		c.convertChildren(n)
+		c.annotations = prev
+	case *ast.Del:
+		prev := c.annotations
+		c.annotations.Strikethrough = true
+		c.convertChildren(n)
+		c.annotations = prev
+	case *ast.Link:
+		prev := c.link
+		c.link = string(n.Destination)
+		c.convertChildren(n)
+		c.link = prev
+	case *ast.Image:
+		// images can't be inline in Notion, link to them instead
+		prev := c.link
+		c.link = string(n.Destination)
+		c.convertChildren(n)
+		c.link = prev
+	case *ast.HTMLSpan:
+		switch strings.ToLower(string(n.Literal)) {
+		case "<u>":
+			c.annotations.Underline = true
+		case "</u>":
+			c.annotations.Underline = false
+		case "<br>", "<br/>", "<br />":
+			c.add(textRun("\n", c.annotations, c.link))
+		default:
+			c.add(textRun(string(n.Literal), c.annotations, c.link))
+		}
+	default:
+		c.convertChildren(n)
+	}
+}
+
+func textRun(s string, a notion.Annotations, link string) notion.RichText {
+	rt := notion.RichText{
+		Type: notion.RichTextTypeText,
+		Text: &notion.Text{Content: s},
+	}
+	if a != (notion.Annotations{}) {
+		rt.Annotations = &a
+	}
+	if link != "" {
+		rt.Text.Link = &notion.Link{URL: link}
+	}
+	return rt
+}
+
+// splitLongRuns splits text runs longer than MaxTextLength. Like in the
+// API, length is in UTF-16 code units, so e.g. an emoji counts as 2.
+func splitLongRuns(rts []notion.RichText) []notion.RichText {
+	var res []notion.RichText
+	for _, rt := range rts {
+		if rt.Text == nil || textLength(rt.Text.Content) <= MaxTextLength {
+			res = append(res, rt)
+			continue
+		}
+		content := rt.Text.Content
+		for content != "" {
+			// end of the longest prefix within MaxTextLength
+			end, n := len(content), 0
+			for i, r := range content {
+				if n += utf16Len(r); n > MaxTextLength {
+					end = i
+					break
+				}
+			}
+			part := rt
+			text := *rt.Text
+			text.Content = content[:end]
+			part.Text = &text
+			res = append(res, part)
+			content = content[end:]
+		}
+	}
+	return res
+}
+
+// textLength returns the length of s in UTF-16 code units.
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16Len(r)
+	}
+	return n
+}
+
+// utf16Len returns the number of UTF-16 code units of r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// languages supported by Notion code blocks.
+var languages = map[string]bool{
+	"abap": true, "arduino": true, "bash": true, "basic": true, "c": true,
+	"clojure": true, "coffeescript": true, "c++": true, "c#": true,
+	"css": true, "dart": true, "diff": true, "docker": true, "elixir": true,
+	"elm": true, "erlang": true, "flow": true, "fortran": true, "f#": true,
+	"gherkin": true, "glsl": true, "go": true, "graphql": true,
+	"groovy": true, "haskell": true, "html": true, "java": true,
+	"javascript": true, "json": true, "julia": true, "kotlin": true,
+	"latex": true, "less": true, "lisp": true, "livescript": true,
+	"lua": true, "makefile": true, "markdown": true, "markup": true,
+	"matlab": true, "mermaid": true, "nix": true, "objective-c": true,
+	"ocaml": true, "pascal": true, "perl": true, "php": true,
+	"plain text": true, "powershell": true, "prolog": true,
+	"protobuf": true, "python": true, "r": true, "reason": true,
+	"ruby": true, "rust": true, "sass": true, "scala": true, "scheme": true,
+	"scss": true, "shell": true, "sql": true, "swift": true,
+	"typescript": true, "vb.net": true, "verilog": true, "vhdl": true,
+	"visual basic": true, "webassembly": true, "xml": true, "yaml": true,
+}
+
+var languageAliases = map[string]string{
+	"golang":     "go",
+	"js":         "javascript",
+	"ts":         "typescript",
+	"py":         "python",
+	"rb":         "ruby",
+	"rs":         "rust",
+	"sh":         "shell",
+	"zsh":        "shell",
+	"console":    "shell",
+	"yml":        "yaml",
+	"cpp":        "c++",
+	"csharp":     "c#",
+	"cs":         "c#",
+	"fsharp":     "f#",
+	"dockerfile": "docker",
+	"objc":       "objective-c",
+	"md":         "markdown",
+	"tex":        "latex",
+	"text":       "plain text",
+	"txt":        "plain text",
+}
+
+// notionLanguage maps info string of a fenced code block to a language
+// supported by Notion. Unknown languages are "plain text".
+func notionLanguage(info string) string {
+	lang := strings.ToLower(strings.TrimSpace(info))
+	if i := strings.IndexAny(lang, " \t{"); i >= 0 {
+		lang = lang[:i]
+	}
+	if alias, ok := languageAliases[lang]; ok {
+		return alias
+	}
+	if languages[lang] {
+		return lang
+	}
+	return "plain text"
+}
@@ -0,0 +1,366 @@
+package markdown_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/markdown"
+	"github.com/kjk/notion/notiontest"
+)
+
+func linked(s string, url string) notion.RichText {
+	rt := text(s)
+	rt.Text.Link = &notion.Link{URL: url}
+	return rt
+}
+
+func TestToBlocks(t *testing.T) {
+	t.Parallel()
+
+	md := "# Runbook\n" +
+		"\n" +
+		"Restart **the *service*** with `systemctl` and\n" +
+		"see [docs](https://example.com) $x^2$.\n" +
+		"\n" +
+		"- step one\n" +
+		"  - nested\n" +
+		"- [x] done\n" +
+		"\n" +
+		"1. first\n" +
+		"\n" +
+		"```sh\n" +
+		"make deploy\n" +
+		"```\n" +
+		"\n" +
+		"> careful\n" +
+		"\n" +
+		"---\n"
+
+	exp := []notion.Block{
+		{
+			Object:   "block",
+			Type:     notion.BlockTypeHeading1,
+			Heading1: &notion.Heading{Text: texts(text("Runbook"))},
+		},
+		{
+			Object: "block",
+			Type:   notion.BlockTypeParagraph,
+			Paragraph: &notion.RichTextBlock{
+				Text: texts(
+					text("Restart "),
+					styled("the ", notion.Annotations{Bold: true}),
+					styled("service", notion.Annotations{Bold: true, Italic: true}),
+					text(" with "),
+					styled("systemctl", notion.Annotations{Code: true}),
+					text(" and see "),
+					linked("docs", "https://example.com"),
+					text(" "),
+					notion.RichText{Type: notion.RichTextTypeEquation, Equation: &notion.Equation{Expression: "x^2"}},
+					text("."),
+				),
+			},
+		},
+		{
+			Object: "block",
+			Type:   notion.BlockTypeBulletedListItem,
+			BulletedListItem: &notion.RichTextBlock{
+				Text: texts(text("step one")),
+				Children: []notion.Block{
+					{
+						Object:           "block",
+						Type:             notion.BlockTypeBulletedListItem,
+						BulletedListItem: &notion.RichTextBlock{Text: texts(text("nested"))},
+					},
+				},
+			},
+		},
+		{
+			Object: "block",
+			Type:   notion.BlockTypeToDo,
+			ToDo: &notion.ToDo{
+				RichTextBlock: notion.RichTextBlock{Text: texts(text("done"))},
+				Checked:       boolPtr(true),
+			},
+		},
+		{
+			Object:           "block",
+			Type:             notion.BlockTypeNumberedListItem,
+			NumberedListItem: &notion.RichTextBlock{Text: texts(text("first"))},
+		},
+		{
+			Object: "block",
+			Type:   notion.BlockTypeCode,
+			Code:   &notion.Code{Text: texts(text("make deploy")), Language: "shell"},
+		},
+		{
+			Object: "block",
+			Type:   notion.BlockTypeQuote,
+			Quote:  &notion.RichTextBlock{Text: texts(text("careful"))},
+		},
+		{
+			Object:  "block",
+			Type:    notion.BlockTypeDivider,
+			Divider: &notion.Divider{},
+		},
+	}
+
+	got := markdown.ToBlocks([]byte(md))
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("blocks not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestToBlocksRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	md := "## Title\n" +
+		"\n" +
+		"Some **bold** and ~~deleted~~ <u>underlined</u> text.\n" +
+		"\n" +
+		"- a\n" +
+		"  - b\n" +
+		"\n" +
+		"<details>\n" +
+		"<summary>More</summary>\n" +
+		"\n" +
+		"hidden\n" +
+		"\n" +
+		"</details>\n" +
+		"\n" +
+		"$$\n" +
+		"e=mc^2\n" +
+		"$$\n" +
+		"\n" +
+		"![diagram](https://example.com/a.png)\n" +
+		"\n" +
+		"| Name | Value |\n" +
+		"| --- | --- |\n" +
+		"| a | 1 |\n"
+
+	got := string(markdown.BlocksToMarkdown(markdown.ToBlocks([]byte(md))))
+	if diff := cmp.Diff(md, got); diff != "" {
+		t.Fatalf("markdown not equal after round-trip (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestToBlocksSplitsLongText(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("ą", markdown.MaxTextLength+10)
+	blocks := markdown.ToBlocks([]byte(long))
+	if len(blocks) != 1 || blocks[0].Paragraph == nil {
+		t.Fatalf("expected a single paragraph, got %+v", blocks)
+	}
+	rts := blocks[0].Paragraph.Text
+	if len(rts) != 2 {
+		t.Fatalf("expected 2 text runs, got %d", len(rts))
+	}
+	if n := utf8.RuneCountInString(rts[0].Text.Content); n != markdown.MaxTextLength {
+		t.Fatalf("expected first run of %d characters, got %d", markdown.MaxTextLength, n)
+	}
+	if notion.PlainText(rts) != long {
+		t.Fatalf("text changed after splitting")
+	}
+
+	// the limit is in UTF-16 code units, an emoji is 2 of them
+	emoji := strings.Repeat("😀", markdown.MaxTextLength/2+1)
+	blocks = markdown.ToBlocks([]byte(emoji))
+	rts = blocks[0].Paragraph.Text
+	if len(rts) != 2 || utf8.RuneCountInString(rts[0].Text.Content) != markdown.MaxTextLength/2 {
+		t.Fatalf("expected a first run of %d emoji, got %d runs", markdown.MaxTextLength/2, len(rts))
+	}
+	if notion.PlainText(rts) != emoji {
+		t.Fatalf("text changed after splitting")
+	}
+}
+
+func TestChunk(t *testing.T) {
+	t.Parallel()
+
+	blocks := make([]notion.Block, 250)
+	chunks := markdown.Chunk(blocks, 0)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	for i, exp := range []int{100, 100, 50} {
+		if len(chunks[i]) != exp {
+			t.Fatalf("expected chunk %d to have %d blocks, got %d", i, exp, len(chunks[i]))
+		}
+	}
+	if chunks := markdown.Chunk(nil, 0); len(chunks) != 0 {
+		t.Fatalf("expected no chunks, got %d", len(chunks))
+	}
+}
+
+// limitsTransport fails requests that append more blocks than the API
+// accepts in one request or tables without rows. It counts requests.
+type limitsTransport struct {
+	t         *testing.T
+	transport http.RoundTripper
+	requests  map[string]int
+}
+
+func (l *limitsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	l.requests[r.Method]++
+	if r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/children") {
+		d, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(d))
+		var body struct {
+			Children []json.RawMessage `json:"children"`
+		}
+		if err := json.Unmarshal(d, &body); err != nil {
+			return nil, err
+		}
+		l.checkChildren(body.Children, 0)
+	}
+	return l.transport.RoundTrip(r)
+}
+
+func (l *limitsTransport) checkChildren(children []json.RawMessage, depth int) {
+	if len(children) > markdown.MaxChildrenPerRequest {
+		l.t.Errorf("request has %d children at depth %d", len(children), depth)
+	}
+	if depth > 2 {
+		l.t.Errorf("request has children at depth %d", depth)
+	}
+	for _, child := range children {
+		var b map[string]json.RawMessage
+		if err := json.Unmarshal(child, &b); err != nil {
+			l.t.Fatal(err)
+		}
+		var typ string
+		json.Unmarshal(b["type"], &typ)
+		var content struct {
+			Children []json.RawMessage `json:"children"`
+		}
+		json.Unmarshal(b[typ], &content)
+		if typ == string(notion.BlockTypeTable) && len(content.Children) == 0 {
+			l.t.Errorf("request has a table without rows at depth %d", depth)
+		}
+		if len(content.Children) > 0 {
+			l.checkChildren(content.Children, depth+1)
+		}
+	}
+}
+
+// outline returns plain text of blocks indented by their depth.
+func outline(blocks []notion.Block, indent string) string {
+	var sb strings.Builder
+	for _, b := range blocks {
+		text := ""
+		if b.BulletedListItem != nil {
+			text = notion.PlainText(b.BulletedListItem.Text)
+		}
+		sb.WriteString(indent + text + "\n")
+		sb.WriteString(outline(b.ChildBlocks(), indent+"  "))
+	}
+	return sb.String()
+}
+
+func TestAppendNested(t *testing.T) {
+	t.Parallel()
+
+	var md strings.Builder
+	md.WriteString("- many\n")
+	for i := 0; i < 150; i++ {
+		fmt.Fprintf(&md, "  - item %d\n", i)
+	}
+	md.WriteString("- level 0\n  - level 1\n    - level 2\n      - level 3\n        - level 4\n")
+	blocks := markdown.ToBlocks([]byte(md.String()))
+
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	page := srv.AddPage(notion.Page{Parent: notion.PageParent{Type: "workspace"}})
+	opts := srv.ClientOptions()
+	limits := &limitsTransport{t: t, transport: opts.HTTPClient.Transport, requests: map[string]int{}}
+	opts.HTTPClient = &http.Client{Transport: limits}
+	client := notion.NewClient("secret-api-key", opts)
+
+	ctx := context.Background()
+	if err := markdown.Append(ctx, client, page.ID, blocks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// created blocks are returned by append requests, so only blocks with
+	// deferred grandchildren are listed
+	if n := limits.requests[http.MethodGet]; n > 2 {
+		t.Fatalf("expected at most 2 list requests, got %d", n)
+	}
+	tree, err := client.GetBlockTree(ctx, page.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(outline(blocks, ""), outline(tree, "")); diff != "" {
+		t.Fatalf("blocks not equal (-exp, +got):\n%v", diff)
+	}
+	if n := len(tree[0].ChildBlocks()); n != 150 {
+		t.Fatalf("expected 150 nested items, got %d", n)
+	}
+}
+
+func TestAppendTables(t *testing.T) {
+	t.Parallel()
+
+	var md strings.Builder
+	md.WriteString("| Name |\n| --- |\n")
+	for i := 0; i < 250; i++ {
+		fmt.Fprintf(&md, "| row %d |\n", i)
+	}
+	table := markdown.ToBlocks([]byte(md.String()))[0]
+	item := func(text string, children ...notion.Block) notion.Block {
+		return notion.Block{
+			Type: notion.BlockTypeBulletedListItem,
+			BulletedListItem: &notion.RichTextBlock{
+				Text:     []notion.RichText{{Type: notion.RichTextTypeText, Text: &notion.Text{Content: text}}},
+				Children: children,
+			},
+		}
+	}
+	// a large table and a table nested too deep to be sent with its rows
+	small := markdown.ToBlocks([]byte("| a | b |\n| --- | --- |\n| 1 | 2 |\n"))[0]
+	blocks := []notion.Block{table, item("level 0", item("level 1", small))}
+
+	srv := notiontest.NewServer()
+	defer srv.Close()
+	page := srv.AddPage(notion.Page{Parent: notion.PageParent{Type: "workspace"}})
+	opts := srv.ClientOptions()
+	opts.HTTPClient = &http.Client{Transport: &limitsTransport{t: t, transport: opts.HTTPClient.Transport, requests: map[string]int{}}}
+	client := notion.NewClient("secret-api-key", opts)
+
+	ctx := context.Background()
+	if err := markdown.Append(ctx, client, page.ID, blocks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tree, err := client.GetBlockTree(ctx, page.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var rows []int
+	for _, b := range tree {
+		if b.Table != nil {
+			header := notion.PlainText(b.ChildBlocks()[0].TableRow.Cells[0])
+			if header != "Name" {
+				t.Errorf("expected header row in every table, got %q", header)
+			}
+			rows = append(rows, len(b.ChildBlocks()))
+		}
+	}
+	if diff := cmp.Diff([]int{100, 100, 53}, rows); diff != "" {
+		t.Fatalf("table rows not equal (-exp, +got):\n%v", diff)
+	}
+	nested := tree[len(tree)-1].ChildBlocks()[0].ChildBlocks()
+	if len(nested) != 1 || nested[0].Table == nil || len(nested[0].ChildBlocks()) != 2 {
+		t.Fatalf("expected nested table with 2 rows, got %+v", nested)
+	}
+}
@@ -0,0 +1,215 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+// RenderRichText renders rich text as inline Markdown. Newlines are
+// rendered as hard line breaks.
+func (r *Renderer) RenderRichText(rts []notion.RichText) string {
+	return r.renderLine(rts, "")
+}
+
+// renderLine renders rich text as inline Markdown. If lineBreak is not
+// empty, newlines are replaced with it, e.g. "<br>" in table cells, so that
+// the text stays on one line.
+func (r *Renderer) renderLine(rts []notion.RichText, lineBreak string) string {
+	var sb strings.Builder
+	for _, run := range mergeRuns(rts, lineBreak) {
+		sb.WriteString(r.renderRun(run))
+	}
+	return sb.String()
+}
+
+// run is a piece of rich text with the same formatting.
+type run struct {
+	text        string // already escaped Markdown
+	code        string // raw text of a code run
+	annotations notion.Annotations
+	href        string
+}
+
+func annotationsOf(rt *notion.RichText) notion.Annotations {
+	if rt.Annotations == nil {
+		return notion.Annotations{}
+	}
+	a := *rt.Annotations
+	// colors are not supported in Markdown
+	a.Color = ""
+	return a
+}
+
+func hrefOf(rt *notion.RichText) string {
+	if rt.Text != nil && rt.Text.Link != nil {
+		return rt.Text.Link.URL
+	}
+	if rt.HRef != nil {
+		return *rt.HRef
+	}
+	return ""
+}
+
+// mergeRuns merges adjacent rich text runs with the same formatting because
+// e.g. "**foo****bar**" doesn't render as bold in Markdown. See renderLine
+// for lineBreak.
+func mergeRuns(rts []notion.RichText, lineBreak string) []run {
+	escape := escapeText
+	if lineBreak != "" {
+		escape = func(s string) string {
+			lines := strings.Split(s, "\n")
+			for i, line := range lines {
+				lines[i] = escapeText(line)
+			}
+			return strings.Join(lines, lineBreak)
+		}
+	}
+	var res []run
+	for i := range rts {
+		rt := &rts[i]
+		cur := run{
+			annotations: annotationsOf(rt),
+			href:        hrefOf(rt),
+		}
+		// text is the raw text of the run, markdown the text in Markdown
+		var text, markdown string
+		switch {
+		case rt.Type == notion.RichTextTypeEquation || (rt.Type == "" && rt.Equation != nil):
+			if rt.Equation == nil {
+				continue
+			}
+			text = rt.Equation.Expression
+			if lineBreak != "" {
+				text = strings.Replace(text, "\n", " ", -1)
+			}
+			markdown = "$" + text + "$"
+		case rt.Type == notion.RichTextTypeMention || (rt.Type == "" && rt.Mention != nil):
+			text = rt.PlainText
+			if text == "" && rt.Mention != nil && rt.Mention.User != nil {
+				text = "@" + rt.Mention.User.Name
+			}
+			markdown = escape(text)
+		default:
+			text = notion.PlainText(rts[i : i+1])
+			markdown = escape(text)
+		}
+		if cur.annotations.Code {
+			if lineBreak != "" {
+				// a code span can't contain a line break
+				text = strings.Replace(text, "\n", " ", -1)
+			}
+			cur.code = text
+		} else {
+			cur.text = markdown
+		}
+
+		if n := len(res); n > 0 {
+			prev := &res[n-1]
+			if prev.annotations == cur.annotations && prev.href == cur.href {
+				prev.text += cur.text
+				prev.code += cur.code
+				continue
+			}
+		}
+		res = append(res, cur)
+	}
+	return res
+}
+
+func (r *Renderer) renderRun(run run) string {
+	s := run.text
+	a := run.annotations
+	if a.Code {
+		s = codeSpan(run.code)
+	}
+	if s == "" {
+		return ""
+	}
+
+	// delimiters must not be next to whitespace e.g. "**foo **" is not bold
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	before, after := s[:start], s[start+len(trimmed):]
+	s = trimmed
+
+	if a.Strikethrough {
+		s = "~~" + s + "~~"
+	}
+	if a.Italic {
+		s = "*" + s + "*"
+	}
+	if a.Bold {
+		s = "**" + s + "**"
+	}
+	if a.Underline {
+		s = "<u>" + s + "</u>"
+	}
+	if run.href != "" {
+		s = "[" + s + "](" + escapeURL(run.href) + ")"
+	}
+	return before + s + after
+}
+
+// codeSpan returns code in backticks. The number of backticks is higher
+// than in any run of backticks in the code.
+func codeSpan(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`~`, `\~`,
+	`$`, `\$`,
+	// hard line break
+	"\n", "\\\n",
+)
+
+// escapeText escapes characters that have special meaning in inline Markdown.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var blockStartRx = regexp.MustCompile(`^(#|=|-|\+|\d+[.)]( |$))`)
+
+// escapeLineStart escapes characters that would start a different block
+// if they appeared at the beginning of a paragraph e.g. "1. " or "# ".
+func escapeLineStart(s string) string {
+	if blockStartRx.MatchString(s) {
+		if s[0] >= '0' && s[0] <= '9' {
+			// escape the '.' or ')' after the number
+			i := strings.IndexAny(s, ".)")
+			return s[:i] + `\` + s[i:]
+		}
+		return `\` + s
+	}
+	return s
+}
+
+var urlEscaper = strings.NewReplacer(
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+)
+
+func escapeURL(s string) string {
+	return urlEscaper.Replace(s)
+}
@@ -0,0 +1,213 @@
+package notion
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CodeRequestFailed is the error code passed to Metrics for requests that
+// failed without an error response from the API, e.g. network errors.
+const CodeRequestFailed = "request_failed"
+
+// Metrics receives measurements of API usage of a Client, per operation
+// e.g. "query database", to find out what uses the rate budget of an
+// integration. Implementations must be safe for concurrent use.
+//
+// ExpvarMetrics publishes metrics with expvar. An adapter for Prometheus
+// can observe them in a CounterVec and a HistogramVec labeled with op and
+// code.
+type Metrics interface {
+	// ObserveRequest is called after every HTTP request. code is "" for
+	// successful requests, APIError.Code for errors returned by the API or
+	// CodeRequestFailed.
+	ObserveRequest(op, code string, latency time.Duration)
+	// ObserveRetry is called before a failed request is retried, with the
+	// code of its error and the delay before the retry.
+	ObserveRetry(op, code string, delay time.Duration)
+	// ObserveRateLimitWait is called when a request waited for the
+	// RateLimiter of the Client.
+	ObserveRateLimitWait(op string, wait time.Duration)
+}
+
+// NopMetrics is a Metrics that does nothing. It's used when
+// ClientOptions.Metrics is not set.
+type NopMetrics struct{}
+
+// ObserveRequest implements Metrics.
+func (NopMetrics) ObserveRequest(op, code string, latency time.Duration) {}
+
+// ObserveRetry implements Metrics.
+func (NopMetrics) ObserveRetry(op, code string, delay time.Duration) {}
+
+// ObserveRateLimitWait implements Metrics.
+func (NopMetrics) ObserveRateLimitWait(op string, wait time.Duration) {}
+
+// errorCode returns the code of err of a request for Metrics.
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		return apiErr.Code
+	}
+	return CodeRequestFailed
+}
+
+// DefaultLatencyBuckets are upper bounds of latency histogram buckets of
+// ExpvarMetrics.
+var DefaultLatencyBuckets = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ExpvarMetrics is a Metrics that is an expvar.Var, so it can be published
+// and served by expvar's /debug/vars handler:
+//
+//	metrics := notion.NewExpvarMetrics(nil)
+//	expvar.Publish("notion", metrics)
+//	client := notion.NewClient(apiKey, &notion.ClientOptions{Metrics: metrics})
+//
+// Its value is a JSON object with an object of every operation:
+//
+//	{"query database": {
+//		"requests": 12,
+//		"errors": {"rate_limited": 2},
+//		"retries": {"rate_limited": 2},
+//		"retry_delay_seconds": 3,
+//		"rate_limit_waits": 3,
+//		"rate_limit_wait_seconds": 1.2,
+//		"latency_seconds": {"buckets": {"0.05": 0, "0.1": 4, ..., "+Inf": 12}, "count": 12, "sum": 2.4}
+//	}}
+//
+// Like in Prometheus, histogram buckets are cumulative.
+type ExpvarMetrics struct {
+	buckets []time.Duration
+
+	mu  sync.Mutex
+	ops expvar.Map
+}
+
+// NewExpvarMetrics returns a new ExpvarMetrics with latency histograms with
+// given bucket upper bounds, in increasing order. If buckets is nil,
+// DefaultLatencyBuckets are used.
+func NewExpvarMetrics(buckets []time.Duration) *ExpvarMetrics {
+	if buckets == nil {
+		buckets = DefaultLatencyBuckets
+	}
+	return &ExpvarMetrics{buckets: buckets}
+}
+
+type opMetrics struct {
+	expvar.Map
+	requests             expvar.Int
+	errors               expvar.Map
+	retries              expvar.Map
+	retryDelaySeconds    expvar.Float
+	rateLimitWaits       expvar.Int
+	rateLimitWaitSeconds expvar.Float
+	latency              *histogram
+}
+
+func (m *ExpvarMetrics) op(op string) *opMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.ops.Get(op).(*opMetrics); ok {
+		return v
+	}
+	v := &opMetrics{latency: newHistogram(m.buckets)}
+	v.Set("requests", &v.requests)
+	v.Set("errors", &v.errors)
+	v.Set("retries", &v.retries)
+	v.Set("retry_delay_seconds", &v.retryDelaySeconds)
+	v.Set("rate_limit_waits", &v.rateLimitWaits)
+	v.Set("rate_limit_wait_seconds", &v.rateLimitWaitSeconds)
+	v.Set("latency_seconds", v.latency)
+	m.ops.Set(op, v)
+	return v
+}
+
+// ObserveRequest implements Metrics.
+func (m *ExpvarMetrics) ObserveRequest(op, code string, latency time.Duration) {
+	v := m.op(op)
+	v.requests.Add(1)
+	if code != "" {
+		v.errors.Add(code, 1)
+	}
+	v.latency.observe(latency)
+}
+
+// ObserveRetry implements Metrics.
+func (m *ExpvarMetrics) ObserveRetry(op, code string, delay time.Duration) {
+	v := m.op(op)
+	v.retries.Add(code, 1)
+	v.retryDelaySeconds.Add(delay.Seconds())
+}
+
+// ObserveRateLimitWait implements Metrics.
+func (m *ExpvarMetrics) ObserveRateLimitWait(op string, wait time.Duration) {
+	v := m.op(op)
+	v.rateLimitWaits.Add(1)
+	v.rateLimitWaitSeconds.Add(wait.Seconds())
+}
+
+// String implements expvar.Var.
+func (m *ExpvarMetrics) String() string {
+	return m.ops.String()
+}
+
+// histogram is a latency histogram in seconds, as an expvar.Var.
+type histogram struct {
+	bounds []time.Duration
+
+	mu     sync.Mutex
+	counts []int64 // the last one is +Inf
+	sum    time.Duration
+}
+
+func newHistogram(bounds []time.Duration) *histogram {
+	return &histogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += d
+	h.mu.Unlock()
+}
+
+func (h *histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(`{"buckets": {`)
+	var total int64
+	for i, n := range h.counts {
+		total += n
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i].Seconds(), 'g', -1, 64)
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%q: %d", le, total)
+	}
+	fmt.Fprintf(&b, `}, "count": %d, "sum": %s}`, total, strconv.FormatFloat(h.sum.Seconds(), 'g', -1, 64))
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+type observation struct {
+	Kind string
+	Op   string
+	Code string
+}
+
+type recordingMetrics struct {
+	mu           sync.Mutex
+	observations []observation
+}
+
+func (m *recordingMetrics) add(o observation) {
+	m.mu.Lock()
+	m.observations = append(m.observations, o)
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) ObserveRequest(op, code string, latency time.Duration) {
+	m.add(observation{"request", op, code})
+}
+
+func (m *recordingMetrics) ObserveRetry(op, code string, delay time.Duration) {
+	m.add(observation{"retry", op, code})
+}
+
+func (m *recordingMetrics) ObserveRateLimitWait(op string, wait time.Duration) {
+	m.add(observation{"rate limit wait", op, ""})
+}
+
+func TestClientMetrics(t *testing.T) {
+	responses := []mockResponse{
+		{status: http.StatusTooManyRequests, body: `{"object":"error","status":429,"code":"rate_limited","message":"Rate limited."}`},
+		{status: http.StatusOK, body: `{"object":"user","id":"be32e790-8292-46df-a248-b784fdf483cf","type":"person"}`},
+		{status: http.StatusBadRequest, body: `{"object":"error","status":400,"code":"validation_error","message":"Invalid."}`},
+		{err: errors.New("connection reset")},
+	}
+	httpClient := &http.Client{Transport: queueTransport(responses, nil)}
+	metrics := &recordingMetrics{}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{
+		HTTPClient:  httpClient,
+		Retry:       &notion.RetryPolicy{MaxAttempts: 2, RetryableCodes: []string{"rate_limited"}},
+		RateLimiter: notion.NewRateLimiter(20, 1),
+		Metrics:     metrics,
+	})
+
+	ctx := context.Background()
+	if _, err := client.GetUser(ctx, "be32e790-8292-46df-a248-b784fdf483cf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetPage(ctx, "b0668f48-8d66-4733-9bdb-2f82215707f7"); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := client.Search(ctx, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// the limiter allows 1 request without waiting
+	want := []observation{
+		{"request", "find user", "rate_limited"},
+		{"retry", "find user", "rate_limited"},
+		{"rate limit wait", "find user", ""},
+		{"request", "find user", ""},
+		{"rate limit wait", "find page", ""},
+		{"request", "find page", "validation_error"},
+		{"rate limit wait", "search", ""},
+		{"request", "search", notion.CodeRequestFailed},
+	}
+	if diff := cmp.Diff(want, metrics.observations); diff != "" {
+		t.Errorf("observations not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	m := notion.NewExpvarMetrics([]time.Duration{100 * time.Millisecond, time.Second})
+	m.ObserveRequest("query database", "", 50*time.Millisecond)
+	m.ObserveRequest("query database", "rate_limited", 100*time.Millisecond)
+	m.ObserveRequest("query database", "", 2*time.Second)
+	m.ObserveRetry("query database", "rate_limited", time.Second)
+	m.ObserveRetry("query database", "service_unavailable", 500*time.Millisecond)
+	m.ObserveRateLimitWait("query database", 250*time.Millisecond)
+	m.ObserveRateLimitWait("query database", 250*time.Millisecond)
+	m.ObserveRequest("find page", "", 500*time.Millisecond)
+
+	var got interface{}
+	if err := json.Unmarshal([]byte(m.String()), &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", m.String(), err)
+	}
+	var want interface{}
+	err := json.Unmarshal([]byte(`{
+		"find page": {
+			"requests": 1,
+			"errors": {},
+			"retries": {},
+			"retry_delay_seconds": 0,
+			"rate_limit_waits": 0,
+			"rate_limit_wait_seconds": 0,
+			"latency_seconds": {"buckets": {"0.1": 0, "1": 1, "+Inf": 1}, "count": 1, "sum": 0.5}
+		},
+		"query database": {
+			"requests": 3,
+			"errors": {"rate_limited": 1},
+			"retries": {"rate_limited": 1, "service_unavailable": 1},
+			"retry_delay_seconds": 1.5,
+			"rate_limit_waits": 2,
+			"rate_limit_wait_seconds": 0.5,
+			"latency_seconds": {"buckets": {"0.1": 2, "1": 2, "+Inf": 3}, "count": 3, "sum": 2.15}
+		}
+	}`), &want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("metrics not equal (-exp, +got):\n%v", diff)
+	}
+}
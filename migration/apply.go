@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kjk/notion"
+)
+
+// Options are options of Diff, Apply and Migrate.
+type Options struct {
+	// DryRun only computes the plan, without updating the database.
+	DryRun bool
+	// Prune removes properties and select options that are not in the
+	// desired schema. By default they are kept, so that data in a database
+	// is never lost, and marked as kept in the plan.
+	Prune bool
+}
+
+// Migrate changes the schema of a database to match desired properties and
+// returns the plan of changes. With opts.DryRun the database is not
+// changed.
+func Migrate(ctx context.Context, client *notion.Client, databaseID string, desired notion.DatabaseProperties, opts Options) (*Plan, error) {
+	db, err := client.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := Diff(db.Properties, desired, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Apply(ctx, client, databaseID, plan, opts); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// Apply updates a database with changes of the plan. It returns the updated
+// database or nil if nothing was updated, because of opts.DryRun or no
+// changes to apply. Removals are applied if the plan was made with
+// Options.Prune, and opts.Prune must match plan.Prune.
+func Apply(ctx context.Context, client *notion.Client, databaseID string, plan *Plan, opts Options) (*notion.Database, error) {
+	if opts.Prune != plan.Prune {
+		return nil, fmt.Errorf("migration: plan was made with Prune %v, but Apply was called with Prune %v", plan.Prune, opts.Prune)
+	}
+	params := plan.UpdateParams()
+	if opts.DryRun || len(params.Properties) == 0 {
+		return nil, nil
+	}
+	return client.UpdateDatabase(ctx, databaseID, params)
+}
+
+// UpdateParams returns params of Client.UpdateDatabase that apply the plan.
+// Kept removals are not included.
+func (p *Plan) UpdateParams() notion.UpdateDatabaseParams {
+	props := map[string]*notion.DatabaseProperty{}
+	for _, c := range p.Changes {
+		switch c.Kind {
+		case ChangeRemove:
+			if !c.Kept {
+				props[c.Property] = nil
+			}
+		case ChangeRename:
+			props[c.Property] = &notion.DatabaseProperty{Name: c.NewName}
+		case ChangeOptions:
+			if c.Kept && len(c.AddedOptions)+len(c.RecoloredOptions) == 0 {
+				continue
+			}
+			props[c.Property] = mergeOptions(c, !c.Kept)
+		default:
+			prop := *c.Desired
+			prop.ID, prop.Name = "", ""
+			props[c.Property] = &prop
+		}
+	}
+	return notion.UpdateDatabaseParams{Properties: props}
+}
+
+// mergeOptions returns a property with options of the desired property.
+// Unless prune is set, options that are only in the database are kept.
+func mergeOptions(c Change, prune bool) *notion.DatabaseProperty {
+	current := map[string]notion.SelectOptions{}
+	for _, o := range options(*c.Current) {
+		current[o.Name] = o
+	}
+	var res []notion.SelectOptions
+	if !prune {
+		for _, o := range options(*c.Current) {
+			res = append(res, notion.SelectOptions{ID: o.ID, Name: o.Name, Color: o.Color})
+		}
+	}
+	for _, o := range options(*c.Desired) {
+		cur, ok := current[o.Name]
+		if o.Color == "" {
+			o.Color = cur.Color
+		}
+		if !ok {
+			res = append(res, notion.SelectOptions{Name: o.Name, Color: o.Color})
+			continue
+		}
+		if prune {
+			res = append(res, notion.SelectOptions{ID: cur.ID, Name: o.Name, Color: o.Color})
+			continue
+		}
+		for i := range res {
+			if res[i].Name == o.Name {
+				res[i].Color = o.Color
+			}
+		}
+	}
+	if res == nil {
+		res = []notion.SelectOptions{}
+	}
+
+	prop := &notion.DatabaseProperty{Type: c.Desired.Type}
+	meta := &notion.SelectMetadata{Options: res}
+	if prop.Type == notion.DBPropTypeSelect {
+		prop.Select = meta
+	} else {
+		prop.MultiSelect = meta
+	}
+	return prop
+}
@@ -0,0 +1,344 @@
+// Package migration compares the schema of a Notion database to a desired
+// schema and updates the database to match it.
+//
+// Desired schema is notion.DatabaseProperties, e.g. built with
+// notion.NewSchema or loaded from a YAML or JSON file with LoadSchema:
+//
+//	desired, err := migration.LoadSchemaFile("tasks.yaml")
+//	...
+//	plan, err := migration.Migrate(ctx, client, databaseID, desired, migration.Options{DryRun: true})
+//	...
+//	fmt.Print(plan)
+//
+// Properties are matched by name. A renamed property is seen as a removed
+// and an added property, except for the title property which is renamed.
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+// ChangeKind is a kind of change of a property.
+type ChangeKind string
+
+const (
+	// ChangeAdd adds a property missing in the database.
+	ChangeAdd ChangeKind = "add"
+	// ChangeRemove removes a property missing in the desired schema.
+	ChangeRemove ChangeKind = "remove"
+	// ChangeRename renames the title property.
+	ChangeRename ChangeKind = "rename"
+	// ChangeType changes the type of a property.
+	ChangeType ChangeKind = "type"
+	// ChangeOptions changes options of a select or multi_select property.
+	ChangeOptions ChangeKind = "options"
+	// ChangeMetadata changes other metadata of a property e.g. number format.
+	ChangeMetadata ChangeKind = "metadata"
+)
+
+// Change is a difference between a property in the database and in the
+// desired schema.
+type Change struct {
+	Kind ChangeKind
+	// Property is the name of the property in the database or, for added
+	// properties, in the desired schema.
+	Property string
+	// Current is nil for added properties.
+	Current *notion.DatabaseProperty
+	// Desired is nil for removed properties.
+	Desired *notion.DatabaseProperty
+	// NewName is the desired name of a renamed property.
+	NewName string
+
+	// Names of options, for ChangeOptions.
+	AddedOptions     []string
+	RemovedOptions   []string
+	RecoloredOptions []string
+
+	// Kept is true if a removed property or RemovedOptions are not removed
+	// because Options.Prune is not set.
+	Kept bool
+
+	// Details describes a ChangeMetadata change e.g.
+	// `number format "dollar" -> "euro"`.
+	Details string
+}
+
+// Plan is a list of changes that makes a database match a desired schema.
+type Plan struct {
+	Changes []Change
+	// Prune is Options.Prune of Diff. Without it removals are listed in
+	// Changes, but not applied.
+	Prune bool
+}
+
+// IsEmpty returns true if the database already matches the desired schema.
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Diff returns changes that make properties of a database, as returned by
+// Client.GetDatabase, match the desired properties. Metadata not set in
+// desired (e.g. nil Select) is not compared. Unless opts.Prune is set,
+// removals are marked as Kept. It returns an error if the title property
+// would be renamed to the name of another property in the database.
+func Diff(current, desired notion.DatabaseProperties, opts Options) (*Plan, error) {
+	plan := &Plan{Prune: opts.Prune}
+	currentTitle, desiredTitle := titleProperty(current), titleProperty(desired)
+	renameTitle := currentTitle != "" && desiredTitle != "" && currentTitle != desiredTitle
+	if other, ok := current[desiredTitle]; renameTitle && ok {
+		return nil, fmt.Errorf("migration: can't rename title property %q to %q, the database has a %s property with that name", currentTitle, desiredTitle, other.Type)
+	}
+
+	for _, name := range sortedNames(current, desired) {
+		cur, hasCur := current[name]
+		want, hasWant := desired[name]
+		switch {
+		case renameTitle && name == currentTitle:
+			plan.add(Change{Kind: ChangeRename, Property: name, Current: &cur, Desired: ptr(desired[desiredTitle]), NewName: desiredTitle})
+		case renameTitle && name == desiredTitle && !hasCur:
+			// renamed above
+		case !hasCur:
+			plan.add(Change{Kind: ChangeAdd, Property: name, Desired: &want})
+		case !hasWant:
+			plan.add(Change{Kind: ChangeRemove, Property: name, Current: &cur, Kept: !opts.Prune})
+		case cur.Type != want.Type:
+			plan.add(Change{Kind: ChangeType, Property: name, Current: &cur, Desired: &want})
+		default:
+			plan.diffMetadata(name, cur, want)
+		}
+	}
+	return plan, nil
+}
+
+func ptr(prop notion.DatabaseProperty) *notion.DatabaseProperty {
+	return &prop
+}
+
+func (p *Plan) add(c Change) {
+	p.Changes = append(p.Changes, c)
+}
+
+func (p *Plan) diffMetadata(name string, cur, want notion.DatabaseProperty) {
+	change := Change{Kind: ChangeMetadata, Property: name, Current: &cur, Desired: &want}
+	switch want.Type {
+	case notion.DBPropTypeNumber:
+		if want.Number != nil && (cur.Number == nil || cur.Number.Format != want.Number.Format) {
+			var from notion.NumberFormat
+			if cur.Number != nil {
+				from = cur.Number.Format
+			}
+			change.Details = fmt.Sprintf("number format %q -> %q", from, want.Number.Format)
+			p.add(change)
+		}
+	case notion.DBPropTypeSelect, notion.DBPropTypeMultiSelect:
+		curOpts, wantOpts := options(cur), options(want)
+		if wantOpts == nil {
+			return
+		}
+		change.Kind = ChangeOptions
+		curByName := map[string]notion.SelectOptions{}
+		for _, o := range curOpts {
+			curByName[o.Name] = o
+		}
+		wantNames := map[string]bool{}
+		for _, o := range wantOpts {
+			wantNames[o.Name] = true
+			c, ok := curByName[o.Name]
+			switch {
+			case !ok:
+				change.AddedOptions = append(change.AddedOptions, o.Name)
+			case o.Color != "" && o.Color != c.Color:
+				change.RecoloredOptions = append(change.RecoloredOptions, o.Name)
+			}
+		}
+		for _, o := range curOpts {
+			if !wantNames[o.Name] {
+				change.RemovedOptions = append(change.RemovedOptions, o.Name)
+				change.Kept = !p.Prune
+			}
+		}
+		if len(change.AddedOptions)+len(change.RemovedOptions)+len(change.RecoloredOptions) > 0 {
+			p.add(change)
+		}
+	case notion.DBPropTypeFormula:
+		if want.Formula != nil && (cur.Formula == nil || cur.Formula.Expression != want.Formula.Expression) {
+			var from string
+			if cur.Formula != nil {
+				from = cur.Formula.Expression
+			}
+			change.Details = fmt.Sprintf("formula %q -> %q", from, want.Formula.Expression)
+			p.add(change)
+		}
+	case notion.DBPropTypeRelation:
+		if want.Relation != nil && (cur.Relation == nil || normalizeID(cur.Relation.DatabaseID) != normalizeID(want.Relation.DatabaseID)) {
+			var from string
+			if cur.Relation != nil {
+				from = cur.Relation.DatabaseID
+			}
+			change.Details = fmt.Sprintf("relation database %s -> %s", from, want.Relation.DatabaseID)
+			p.add(change)
+		}
+	case notion.DBPropTypeRollup:
+		if want.Rollup != nil && (cur.Rollup == nil || !rollupEqual(*cur.Rollup, *want.Rollup)) {
+			var from string
+			if cur.Rollup != nil {
+				from = describeRollup(*cur.Rollup)
+			}
+			change.Details = fmt.Sprintf("rollup %s -> %s", from, describeRollup(*want.Rollup))
+			p.add(change)
+		}
+	}
+}
+
+func options(prop notion.DatabaseProperty) []notion.SelectOptions {
+	meta := prop.Select
+	if prop.Type == notion.DBPropTypeMultiSelect {
+		meta = prop.MultiSelect
+	}
+	if meta == nil {
+		return nil
+	}
+	if meta.Options == nil {
+		return []notion.SelectOptions{}
+	}
+	return meta.Options
+}
+
+// rollupEqual compares rollups by names of properties, if set in desired,
+// otherwise by IDs.
+func rollupEqual(cur, want notion.RollupMetadata) bool {
+	if want.Function != cur.Function {
+		return false
+	}
+	if want.RelationPropName != "" && want.RelationPropName != cur.RelationPropName ||
+		want.RelationPropName == "" && want.RelationPropID != cur.RelationPropID {
+		return false
+	}
+	if want.RollupPropName != "" && want.RollupPropName != cur.RollupPropName ||
+		want.RollupPropName == "" && want.RollupPropID != cur.RollupPropID {
+		return false
+	}
+	return true
+}
+
+func describeRollup(r notion.RollupMetadata) string {
+	relation, prop := r.RelationPropName, r.RollupPropName
+	if relation == "" {
+		relation = r.RelationPropID
+	}
+	if prop == "" {
+		prop = r.RollupPropID
+	}
+	return fmt.Sprintf("%s of %s.%s", r.Function, relation, prop)
+}
+
+func normalizeID(id string) string {
+	return strings.ToLower(strings.Replace(id, "-", "", -1))
+}
+
+func titleProperty(props notion.DatabaseProperties) string {
+	for name, prop := range props {
+		if prop.Type == notion.DBPropTypeTitle {
+			return name
+		}
+	}
+	return ""
+}
+
+func sortedNames(a, b notion.DatabaseProperties) []string {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns a human readable description of the plan, one change per
+// line, e.g.:
+//
+//	~ Due: type rich_text -> date
+//	~ Name: rename to Task
+//	- Notes: rich_text (kept, use Prune)
+//	~ Price: number format "dollar" -> "euro"
+//	~ Status: options +Blocked -Archived (kept, use Prune) color of Done
+func (p *Plan) String() string {
+	if p.IsEmpty() {
+		return "no changes\n"
+	}
+	var sb strings.Builder
+	for _, c := range p.Changes {
+		sb.WriteString(c.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// keptNote follows removals that are not applied.
+const keptNote = " (kept, use Prune)"
+
+// String returns a human readable description of the change.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdd:
+		return fmt.Sprintf("+ %s: %s", c.Property, describe(*c.Desired))
+	case ChangeRemove:
+		s := fmt.Sprintf("- %s: %s", c.Property, describe(*c.Current))
+		if c.Kept {
+			s += keptNote
+		}
+		return s
+	case ChangeRename:
+		return fmt.Sprintf("~ %s: rename to %s", c.Property, c.NewName)
+	case ChangeType:
+		return fmt.Sprintf("~ %s: type %s -> %s", c.Property, describe(*c.Current), describe(*c.Desired))
+	case ChangeOptions:
+		var parts []string
+		for _, o := range c.AddedOptions {
+			parts = append(parts, "+"+o)
+		}
+		for _, o := range c.RemovedOptions {
+			if c.Kept {
+				o += keptNote
+			}
+			parts = append(parts, "-"+o)
+		}
+		for _, o := range c.RecoloredOptions {
+			parts = append(parts, "color of "+o)
+		}
+		return fmt.Sprintf("~ %s: options %s", c.Property, strings.Join(parts, " "))
+	}
+	return fmt.Sprintf("~ %s: %s", c.Property, c.Details)
+}
+
+// describe returns type of a property with its main metadata.
+func describe(prop notion.DatabaseProperty) string {
+	s := string(prop.Type)
+	switch {
+	case prop.Number != nil:
+		s += fmt.Sprintf(" (%s)", prop.Number.Format)
+	case prop.Select != nil || prop.MultiSelect != nil:
+		var names []string
+		for _, o := range options(prop) {
+			names = append(names, o.Name)
+		}
+		s += fmt.Sprintf(" (%s)", strings.Join(names, ", "))
+	case prop.Formula != nil:
+		s += fmt.Sprintf(" (%s)", prop.Formula.Expression)
+	case prop.Relation != nil:
+		s += fmt.Sprintf(" (%s)", prop.Relation.DatabaseID)
+	case prop.Rollup != nil:
+		s += fmt.Sprintf(" (%s)", describeRollup(*prop.Rollup))
+	}
+	return s
+}
@@ -0,0 +1,295 @@
+package migration_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/migration"
+)
+
+type mockRoundtripper struct {
+	fn func(*http.Request) (*http.Response, error)
+}
+
+func (m *mockRoundtripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return m.fn(r)
+}
+
+const databaseID = "bc1211ca-e3f1-4939-ae34-5260b16f627c"
+
+const databaseJSON = `{
+	"object": "database",
+	"id": "bc1211ca-e3f1-4939-ae34-5260b16f627c",
+	"properties": {
+		"Name": {"id": "title", "name": "Name", "type": "title", "title": {}},
+		"Status": {"id": "a", "name": "Status", "type": "select", "select": {"options": [
+			{"id": "1", "name": "To do", "color": "red"},
+			{"id": "2", "name": "Done", "color": "gray"},
+			{"id": "3", "name": "Archived", "color": "default"}
+		]}},
+		"Price": {"id": "b", "name": "Price", "type": "number", "number": {"format": "dollar"}},
+		"Due": {"id": "c", "name": "Due", "type": "rich_text", "rich_text": {}},
+		"Notes": {"id": "d", "name": "Notes", "type": "rich_text", "rich_text": {}},
+		"Days left": {"id": "e", "name": "Days left", "type": "formula", "formula": {"expression": "dateBetween(prop(\"Due\"), now(), \"days\")"}},
+		"Project": {"id": "f", "name": "Project", "type": "relation", "relation": {"database_id": "668d797c76fa49349b05ad288df2d136", "synced_property_name": "Tasks", "synced_property_id": "g"}},
+		"Budget": {"id": "h", "name": "Budget", "type": "rollup", "rollup": {"relation_property_name": "Project", "relation_property_id": "f", "rollup_property_name": "Budget", "rollup_property_id": "i", "function": "sum"}}
+	}
+}`
+
+func loadSchema(t *testing.T) notion.DatabaseProperties {
+	t.Helper()
+	desired, err := migration.LoadSchemaFile("testdata/tasks.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return desired
+}
+
+func currentProperties(t *testing.T) notion.DatabaseProperties {
+	t.Helper()
+	var db notion.Database
+	if err := json.Unmarshal([]byte(databaseJSON), &db); err != nil {
+		t.Fatal(err)
+	}
+	return db.Properties
+}
+
+func diff(t *testing.T, current, desired notion.DatabaseProperties, opts migration.Options) *migration.Plan {
+	t.Helper()
+	plan, err := migration.Diff(current, desired, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return plan
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	plan := diff(t, currentProperties(t), loadSchema(t), migration.Options{Prune: true})
+	exp := `~ Due: type rich_text -> date
+~ Name: rename to Task
+- Notes: rich_text
+~ Price: number format "dollar" -> "euro"
+~ Status: options +Blocked -Archived color of Done
+`
+	if diff := cmp.Diff(exp, plan.String()); diff != "" {
+		t.Fatalf("plan not equal (-exp, +got):\n%v", diff)
+	}
+
+	// without Prune, removals are kept
+	plan = diff(t, currentProperties(t), loadSchema(t), migration.Options{})
+	exp = `~ Due: type rich_text -> date
+~ Name: rename to Task
+- Notes: rich_text (kept, use Prune)
+~ Price: number format "dollar" -> "euro"
+~ Status: options +Blocked -Archived (kept, use Prune) color of Done
+`
+	if diff := cmp.Diff(exp, plan.String()); diff != "" {
+		t.Fatalf("plan not equal (-exp, +got):\n%v", diff)
+	}
+
+	plan = diff(t, currentProperties(t), currentProperties(t), migration.Options{})
+	if !plan.IsEmpty() || plan.String() != "no changes\n" {
+		t.Fatalf("expected empty plan, got:\n%v", plan)
+	}
+
+	plan = diff(t, notion.DatabaseProperties{}, notion.DatabaseProperties{
+		"Price": {Type: notion.DBPropTypeNumber, Number: &notion.NumberMetadata{Format: notion.NumberFormatEuro}},
+	}, migration.Options{})
+	if got, exp := plan.String(), "+ Price: number (euro)\n"; got != exp {
+		t.Fatalf("plan not equal (expected: %q, got: %q)", exp, got)
+	}
+}
+
+func TestDiffRenameTitleToExistingProperty(t *testing.T) {
+	t.Parallel()
+
+	desired := loadSchema(t)
+	delete(desired, "Task")
+	desired["Notes"] = notion.DatabaseProperty{Type: notion.DBPropTypeTitle}
+	_, err := migration.Diff(currentProperties(t), desired, migration.Options{})
+	exp := `migration: can't rename title property "Name" to "Notes", the database has a rich_text property with that name`
+	if err == nil || err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+}
+
+func TestApplyPruneMismatch(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			return nil, context.Canceled
+		}},
+	}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{HTTPClient: httpClient})
+
+	plan := diff(t, currentProperties(t), loadSchema(t), migration.Options{})
+	_, err := migration.Apply(context.Background(), client, databaseID, plan, migration.Options{Prune: true})
+	exp := "migration: plan was made with Prune false, but Apply was called with Prune true"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	statusOptions := []interface{}{
+		map[string]interface{}{"id": "1", "name": "To do", "color": "red"},
+		map[string]interface{}{"id": "2", "name": "Done", "color": "green"},
+		map[string]interface{}{"id": "3", "name": "Archived", "color": "default"},
+		map[string]interface{}{"name": "Blocked", "color": "red"},
+	}
+	pruneStatusOptions := []interface{}{
+		map[string]interface{}{"id": "1", "name": "To do", "color": "red"},
+		map[string]interface{}{"id": "2", "name": "Done", "color": "green"},
+		map[string]interface{}{"name": "Blocked", "color": "red"},
+	}
+	properties := func(statusOptions []interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"Due":   map[string]interface{}{"type": "date", "date": map[string]interface{}{}},
+			"Name":  map[string]interface{}{"name": "Task"},
+			"Price": map[string]interface{}{"type": "number", "number": map[string]interface{}{"format": "euro"}},
+			"Status": map[string]interface{}{
+				"type":   "select",
+				"select": map[string]interface{}{"options": statusOptions},
+			},
+		}
+	}
+	pruneProperties := properties(pruneStatusOptions)
+	pruneProperties["Notes"] = nil
+
+	tests := []struct {
+		name        string
+		opts        migration.Options
+		expPostBody map[string]interface{}
+	}{
+		{
+			name:        "keep properties and options",
+			expPostBody: map[string]interface{}{"properties": properties(statusOptions)},
+		},
+		{
+			name:        "prune",
+			opts:        migration.Options{Prune: true},
+			expPostBody: map[string]interface{}{"properties": pruneProperties},
+		},
+		{
+			name: "dry run",
+			opts: migration.Options{DryRun: true, Prune: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var patched bool
+			httpClient := &http.Client{
+				Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+					if r.URL.Path != "/v1/databases/"+databaseID {
+						t.Errorf("unexpected path: %s", r.URL.Path)
+					}
+					if r.Method == http.MethodPatch {
+						patched = true
+						postBody := make(map[string]interface{})
+						if err := json.NewDecoder(r.Body).Decode(&postBody); err != nil {
+							t.Fatal(err)
+						}
+						if diff := cmp.Diff(tt.expPostBody, postBody); diff != "" {
+							t.Errorf("post body not equal (-exp, +got):\n%v", diff)
+						}
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Status:     http.StatusText(http.StatusOK),
+						Body:       ioutil.NopCloser(strings.NewReader(databaseJSON)),
+					}, nil
+				}},
+			}
+			client := notion.NewClient("secret-api-key", &notion.ClientOptions{HTTPClient: httpClient})
+
+			plan, err := migration.Migrate(context.Background(), client, databaseID, loadSchema(t), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(plan.Changes) != 5 {
+				t.Errorf("expected 5 changes, got:\n%v", plan)
+			}
+			if patched != (tt.expPostBody != nil) {
+				t.Errorf("database updated: %v, expected: %v", patched, tt.expPostBody != nil)
+			}
+		})
+	}
+}
+
+func TestLoadSchemaDefaults(t *testing.T) {
+	t.Parallel()
+
+	schema := "properties:\n  Price: {type: number}\n  Status: {type: select}\n  Tags: {type: multi_select, options: []}"
+	got, err := migration.LoadSchema(strings.NewReader(schema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := notion.DatabaseProperties{
+		"Price":  {Type: notion.DBPropTypeNumber, Number: &notion.NumberMetadata{Format: notion.NumberFormatNumber}},
+		"Status": {Type: notion.DBPropTypeSelect},
+		"Tags":   {Type: notion.DBPropTypeMultiSelect, MultiSelect: &notion.SelectMetadata{Options: []notion.SelectOptions{}}},
+	}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("properties not equal (-exp, +got):\n%v", diff)
+	}
+
+	// without options, options in the database are not changed
+	plan := diff(t, currentProperties(t), got, migration.Options{Prune: true})
+	if strings.Contains(plan.String(), "Status") {
+		t.Fatalf("unexpected change of Status:\n%v", plan)
+	}
+}
+
+func TestLoadSchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema string
+		expErr string
+	}{
+		{
+			name:   "unknown field",
+			schema: "properties:\n  Price: {type: number, currency: euro}",
+			expErr: "migration: failed to parse schema: yaml: unmarshal errors:\n  line 2: field currency not found in type migration.schemaProperty",
+		},
+		{
+			name:   "invalid number format",
+			schema: `{"properties": {"Price": {"type": "number", "format": "bitcoin"}}}`,
+			expErr: `notion: invalid schema: property "Price": invalid number format "bitcoin"`,
+		},
+		{
+			name:   "invalid option color",
+			schema: "properties:\n  Status: {type: select, options: [{name: Done, color: red_background}]}",
+			expErr: `notion: invalid schema: property "Status": invalid color "red_background" of option "Done"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := migration.LoadSchema(strings.NewReader(tt.schema))
+			if err == nil || err.Error() != tt.expErr {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kjk/notion"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaFile is the format of a schema file e.g.:
+//
+//	properties:
+//	  Name:
+//	    type: title
+//	  Status:
+//	    type: select
+//	    options: [To do, {name: Done, color: green}]
+//	  Price:
+//	    type: number
+//	    format: dollar
+//	  Days left:
+//	    type: formula
+//	    expression: dateBetween(prop("Due"), now(), "days")
+//	  Project:
+//	    type: relation
+//	    database_id: 668d797c-76fa-4934-9b05-ad288df2d136
+//	  Budget:
+//	    type: rollup
+//	    relation: Project
+//	    property: Budget
+//	    function: sum
+type schemaFile struct {
+	Properties map[string]schemaProperty `yaml:"properties"`
+}
+
+type schemaProperty struct {
+	Type       notion.DatabasePropertyType `yaml:"type"`
+	Format     notion.NumberFormat         `yaml:"format"`
+	Options    []schemaOption              `yaml:"options"`
+	Expression string                      `yaml:"expression"`
+	DatabaseID string                      `yaml:"database_id"`
+	Relation   string                      `yaml:"relation"`
+	Property   string                      `yaml:"property"`
+	Function   string                      `yaml:"function"`
+}
+
+// schemaOption is an option of a select property, either a name or a map
+// with name and color.
+type schemaOption notion.SelectOptions
+
+func (o *schemaOption) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&o.Name)
+	}
+	var opt struct {
+		Name  string       `yaml:"name"`
+		Color notion.Color `yaml:"color"`
+	}
+	if err := node.Decode(&opt); err != nil {
+		return err
+	}
+	o.Name, o.Color = opt.Name, opt.Color
+	return nil
+}
+
+// LoadSchema reads database properties from a YAML or JSON schema with a
+// "properties" object mapping names of properties to their type and
+// metadata, e.g.:
+//
+//	properties:
+//	  Name: {type: title}
+//	  Status: {type: select, options: [To do, {name: Done, color: green}]}
+//	  Price: {type: number, format: dollar}
+//
+// Number properties have a "format", "number" by default. Select and
+// multi_select properties without "options" keep options of the database.
+// Formula properties have an "expression", relation properties a
+// "database_id" and rollup properties "relation", "property" and "function".
+func LoadSchema(r io.Reader) (notion.DatabaseProperties, error) {
+	var file schemaFile
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("migration: failed to parse schema: %w", err)
+	}
+
+	schema := notion.NewSchema()
+	for name, p := range file.Properties {
+		switch p.Type {
+		case notion.DBPropTypeNumber:
+			format := p.Format
+			if format == "" {
+				format = notion.NumberFormatNumber
+			}
+			schema.Number(name, format)
+		case notion.DBPropTypeSelect, notion.DBPropTypeMultiSelect:
+			if p.Options == nil {
+				// options of the database are not compared
+				schema.Property(name, notion.DatabaseProperty{Type: p.Type})
+				continue
+			}
+			opts := make([]notion.SelectOptions, len(p.Options))
+			for i, o := range p.Options {
+				opts[i] = notion.SelectOptions(o)
+			}
+			if p.Type == notion.DBPropTypeSelect {
+				schema.Select(name, opts...)
+			} else {
+				schema.MultiSelect(name, opts...)
+			}
+		case notion.DBPropTypeFormula:
+			schema.Formula(name, p.Expression)
+		case notion.DBPropTypeRelation:
+			schema.Relation(name, p.DatabaseID)
+		case notion.DBPropTypeRollup:
+			schema.Rollup(name, p.Relation, p.Property, p.Function)
+		default:
+			schema.Property(name, notion.DatabaseProperty{Type: p.Type})
+		}
+	}
+	return schema.Build()
+}
+
+// LoadSchemaFile reads database properties from a YAML or JSON file. See
+// LoadSchema for the format.
+func LoadSchemaFile(path string) (notion.DatabaseProperties, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("migration: failed to open schema: %w", err)
+	}
+	defer f.Close()
+	return LoadSchema(f)
+}
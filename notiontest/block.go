@@ -0,0 +1,255 @@
+package notiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kjk/notion"
+)
+
+// addBlock stores a block as the last child of a page or a block with
+// a given ID, or without a parent if parentID is empty.
+func (s *Server) addBlock(parentID string, b *notion.Block) {
+	b.Object = "block"
+	now := s.now()
+	if b.CreatedTime == nil {
+		b.CreatedTime = &now
+	}
+	if b.LastEditedTime == nil {
+		b.LastEditedTime = &now
+	}
+	b.RawJSON = nil
+	s.blocks[key(b.ID)] = b
+	if parentID != "" {
+		s.children[key(parentID)] = append(s.children[key(parentID)], b.ID)
+		s.blocks[key(parentID)].HasChildren = true
+	}
+}
+
+// appendBlocks adds blocks, with their children, as children of a page or
+// a block. Nothing is added if a block is invalid.
+func (s *Server) appendBlocks(parentID string, blocks []notion.Block) error {
+	parent, ok := s.blocks[key(parentID)]
+	if !ok || parent.Archived {
+		return notFound(parentID)
+	}
+	if err := validateBlocks("body.children", blocks); err != nil {
+		return err
+	}
+	s.addBlocks(parent.ID, blocks)
+	return nil
+}
+
+// validateBlocks returns an error if a block, or one of its children, has
+// no type. path is the path of blocks in the request body.
+func validateBlocks(path string, blocks []notion.Block) error {
+	for i := range blocks {
+		b := blocks[i]
+		p := fmt.Sprintf("%s[%d]", path, i)
+		if b.Type == "" {
+			return validationError("%s.type should be defined.", p)
+		}
+		if err := validateBlocks(p+"."+string(b.Type)+".children", takeChildren(&b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addBlocks adds valid blocks, with their children, as children of a page
+// or a block.
+func (s *Server) addBlocks(parentID string, blocks []notion.Block) {
+	for i := range blocks {
+		b := blocks[i]
+		if b.ID == "" || s.exists(b.ID) {
+			b.ID = s.newID()
+		}
+		b.HasChildren, b.Archived = false, false
+		b.CreatedTime, b.LastEditedTime = nil, nil
+		children := takeChildren(&b)
+		s.addBlock(parentID, &b)
+		s.addBlocks(b.ID, children)
+	}
+}
+
+// takeChildren removes children of a block, which are nested in the
+// type-specific object in requests, and returns them.
+func takeChildren(b *notion.Block) []notion.Block {
+	children := b.ChildBlocks()
+	b.Children = nil
+	switch {
+	case b.Paragraph != nil:
+		c := *b.Paragraph
+		c.Children, b.Paragraph = nil, &c
+	case b.BulletedListItem != nil:
+		c := *b.BulletedListItem
+		c.Children, b.BulletedListItem = nil, &c
+	case b.NumberedListItem != nil:
+		c := *b.NumberedListItem
+		c.Children, b.NumberedListItem = nil, &c
+	case b.ToDo != nil:
+		c := *b.ToDo
+		c.Children, b.ToDo = nil, &c
+	case b.Toggle != nil:
+		c := *b.Toggle
+		c.Children, b.Toggle = nil, &c
+	case b.Callout != nil:
+		c := *b.Callout
+		c.Children, b.Callout = nil, &c
+	case b.Quote != nil:
+		c := *b.Quote
+		c.Children, b.Quote = nil, &c
+	case b.Template != nil:
+		c := *b.Template
+		c.Children, b.Template = nil, &c
+	case b.ColumnList != nil:
+		c := *b.ColumnList
+		c.Children, b.ColumnList = nil, &c
+	case b.Column != nil:
+		c := *b.Column
+		c.Children, b.Column = nil, &c
+	case b.SyncedBlock != nil:
+		c := *b.SyncedBlock
+		c.Children, b.SyncedBlock = nil, &c
+	case b.Table != nil:
+		c := *b.Table
+		c.Children, b.Table = nil, &c
+	}
+	return children
+}
+
+func (s *Server) block(id string) (*notion.Block, error) {
+	b, ok := s.blocks[key(id)]
+	if !ok {
+		return nil, notFound(id)
+	}
+	return b, nil
+}
+
+func (s *Server) getBlock(r *http.Request, ids []string) (interface{}, error) {
+	return s.block(ids[0])
+}
+
+func (s *Server) updateBlock(r *http.Request, ids []string) (interface{}, error) {
+	b, err := s.block(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]json.RawMessage
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	var archived *bool
+	if d, ok := body["archived"]; ok {
+		if err := json.Unmarshal(d, &archived); err != nil {
+			return nil, validationError("body.archived should be a boolean.")
+		}
+	}
+	if b.Archived && (archived == nil || *archived) {
+		return nil, validationError("Can't edit block that is archived. You must unarchive the block before editing.")
+	}
+	for field := range body {
+		if field != "archived" && field != string(b.Type) {
+			return nil, validationError("Block of type %s can't be updated with %s.", b.Type, field)
+		}
+	}
+
+	// the type-specific object replaces the current one
+	d, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(d, &fields); err != nil {
+		return nil, err
+	}
+	for field, v := range body {
+		fields[field] = v
+	}
+	if d, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+	var updated notion.Block
+	if err := json.Unmarshal(d, &updated); err != nil {
+		return nil, validationError("Invalid block: %v.", err)
+	}
+	takeChildren(&updated)
+	now := s.now()
+	updated.LastEditedTime = &now
+	*b = updated
+	if archived != nil {
+		s.archive(b.ID, *archived)
+	}
+	return b, nil
+}
+
+// archive archives or restores a block and the page or database with the
+// same ID.
+func (s *Server) archive(id string, archived bool) {
+	s.blocks[key(id)].Archived = archived
+	if p, ok := s.pages[key(id)]; ok {
+		p.Archived = archived
+	}
+}
+
+func (s *Server) deleteBlock(r *http.Request, ids []string) (interface{}, error) {
+	b, err := s.block(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	b.LastEditedTime = &now
+	s.archive(b.ID, true)
+	return b, nil
+}
+
+func (s *Server) getBlockChildren(r *http.Request, ids []string) (interface{}, error) {
+	parent, err := s.block(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	cursor, pageSize, err := paginationQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	var children []*notion.Block
+	var childIDs []string
+	for _, id := range s.children[key(parent.ID)] {
+		if b := s.blocks[key(id)]; !b.Archived {
+			children = append(children, b)
+			childIDs = append(childIDs, b.ID)
+		}
+	}
+	return paginate(childIDs, cursor, pageSize, func(i int) (json.RawMessage, error) {
+		return json.Marshal(children[i])
+	})
+}
+
+// appendChildrenVersion is the first Notion-Version in which append block
+// children responds with a list of the created blocks instead of the parent.
+const appendChildrenVersion = "2021-08-16"
+
+func (s *Server) appendBlockChildren(r *http.Request, ids []string) (interface{}, error) {
+	var body struct {
+		Children []notion.Block `json:"children"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	parent, err := s.block(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	n := len(s.children[key(parent.ID)])
+	if err := s.appendBlocks(parent.ID, body.Children); err != nil {
+		return nil, err
+	}
+	if r.Header.Get("Notion-Version") < appendChildrenVersion {
+		return parent, nil
+	}
+	created := s.children[key(parent.ID)][n:]
+	return paginate(created, "", len(created), func(i int) (json.RawMessage, error) {
+		return json.Marshal(s.blocks[key(created[i])])
+	})
+}
@@ -0,0 +1,277 @@
+package notiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+// key returns a key of an ID in maps of the server. The API accepts IDs
+// with and without dashes.
+func key(id string) string {
+	return strings.ToLower(strings.Replace(id, "-", "", -1))
+}
+
+func (s *Server) insertDatabase(parentPageID string, db notion.Database) (*notion.Database, error) {
+	parent, ok := s.pages[key(parentPageID)]
+	if !ok || parent.Archived {
+		return nil, notFound(parentPageID)
+	}
+	if db.ID == "" {
+		db.ID = s.newID()
+	}
+	if s.exists(db.ID) {
+		return nil, validationError("Object with ID %s already exists.", db.ID)
+	}
+	params := notion.CreateDatabaseParams{ParentPageID: parentPageID, Title: db.Title, Properties: db.Properties}
+	if err := params.Validate(); err != nil {
+		return nil, validationError("%v.", err)
+	}
+
+	now := s.now()
+	if db.CreatedTime.IsZero() {
+		db.CreatedTime = now
+	}
+	if db.LastEditedTime.IsZero() {
+		db.LastEditedTime = now
+	}
+	db.Title = richText(db.Title)
+	props := notion.DatabaseProperties{}
+	for name, prop := range db.Properties {
+		props[name] = s.newProperty(name, prop)
+	}
+	db.Properties = props
+	db.RawJSON = nil
+
+	s.databases[key(db.ID)] = &db
+	s.objects = append(s.objects, db.ID)
+	s.addBlock(parent.ID, &notion.Block{
+		ID:            db.ID,
+		Type:          notion.BlockTypeChildDatabase,
+		ChildDatabase: &notion.ChildDatabase{Title: notion.PlainText(db.Title)},
+	})
+	return &db, nil
+}
+
+func (s *Server) exists(id string) bool {
+	_, isPage := s.pages[key(id)]
+	_, isDatabase := s.databases[key(id)]
+	_, isBlock := s.blocks[key(id)]
+	return isPage || isDatabase || isBlock
+}
+
+// newProperty returns a property of a database schema, with IDs of the
+// property and its select options.
+func (s *Server) newProperty(name string, prop notion.DatabaseProperty) notion.DatabaseProperty {
+	if prop.ID == "" {
+		if prop.Type == notion.DBPropTypeTitle {
+			prop.ID = "title"
+		} else {
+			s.lastID++
+			prop.ID = fmt.Sprintf("p%d", s.lastID)
+		}
+	}
+	prop.Name = name
+	prop.Select = s.newOptions(prop.Select)
+	prop.MultiSelect = s.newOptions(prop.MultiSelect)
+	return prop
+}
+
+func (s *Server) newOptions(meta *notion.SelectMetadata) *notion.SelectMetadata {
+	if meta == nil {
+		return nil
+	}
+	options := make([]notion.SelectOptions, len(meta.Options))
+	for i, o := range meta.Options {
+		if o.ID == "" {
+			s.lastID++
+			o.ID = fmt.Sprintf("o%d", s.lastID)
+		}
+		if o.Color == "" {
+			o.Color = notion.ColorDefault
+		}
+		options[i] = o
+	}
+	return &notion.SelectMetadata{Options: options}
+}
+
+// richText returns a copy of rich text with plain text set, as in API
+// responses.
+func richText(rts []notion.RichText) []notion.RichText {
+	if rts == nil {
+		return nil
+	}
+	res := make([]notion.RichText, len(rts))
+	for i, rt := range rts {
+		if rt.Type == "" && rt.Text != nil {
+			rt.Type = notion.RichTextTypeText
+		}
+		rt.PlainText = notion.PlainText(rts[i : i+1])
+		res[i] = rt
+	}
+	return res
+}
+
+func (s *Server) database(id string) (*notion.Database, error) {
+	db, ok := s.databases[key(id)]
+	if !ok || s.blocks[key(id)].Archived {
+		return nil, notFound(id)
+	}
+	return db, nil
+}
+
+func (s *Server) createDatabase(r *http.Request, ids []string) (interface{}, error) {
+	var body struct {
+		Parent struct {
+			PageID string `json:"page_id"`
+		} `json:"parent"`
+		Title      []notion.RichText         `json:"title"`
+		Properties notion.DatabaseProperties `json:"properties"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	db, err := s.insertDatabase(body.Parent.PageID, notion.Database{Title: body.Title, Properties: body.Properties})
+	if err != nil {
+		return nil, err
+	}
+	return withObject("database", db)
+}
+
+func (s *Server) getDatabase(r *http.Request, ids []string) (interface{}, error) {
+	db, err := s.database(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	return withObject("database", db)
+}
+
+func (s *Server) updateDatabase(r *http.Request, ids []string) (interface{}, error) {
+	db, err := s.database(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Title      []notion.RichText                   `json:"title"`
+		Properties map[string]*notion.DatabaseProperty `json:"properties"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	params := notion.UpdateDatabaseParams{Title: body.Title, Properties: body.Properties}
+	if err := params.Validate(); err != nil {
+		return nil, validationError("%v.", err)
+	}
+
+	props, err := s.updateSchema(db.Properties, body.Properties)
+	if err != nil {
+		return nil, err
+	}
+	db.Properties = props
+	if body.Title != nil {
+		db.Title = richText(body.Title)
+		s.blocks[key(db.ID)].ChildDatabase.Title = notion.PlainText(db.Title)
+	}
+	db.LastEditedTime = s.now()
+	for _, p := range s.pages {
+		if p.Parent.DatabaseID != nil && key(*p.Parent.DatabaseID) == key(db.ID) {
+			p.Properties = pageProperties(db.Properties, p, valuesByID(p.Properties.(notion.DatabasePageProperties)))
+		}
+	}
+	return withObject("database", db)
+}
+
+// updateSchema returns a copy of schema with changes of an update database
+// request, with properties identified by name or ID.
+func (s *Server) updateSchema(schema notion.DatabaseProperties, changes map[string]*notion.DatabaseProperty) (notion.DatabaseProperties, error) {
+	res := notion.DatabaseProperties{}
+	for name, prop := range schema {
+		res[name] = prop
+	}
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		change := changes[name]
+		cur, exists := res[name]
+		if !exists {
+			for n, prop := range res {
+				if prop.ID == name {
+					name, cur, exists = n, prop, true
+				}
+			}
+		}
+		if !exists && (change == nil || change.Type == "") {
+			return nil, validationError("%s is not a property that exists.", name)
+		}
+
+		newName := name
+		if change != nil && change.Name != "" {
+			newName = change.Name
+		}
+		if _, taken := res[newName]; taken && newName != name {
+			return nil, validationError("Property %s already exists.", newName)
+		}
+		delete(res, name)
+		switch {
+		case change == nil:
+			// removed
+		case change.Type == "":
+			res[newName] = s.newProperty(newName, cur)
+		default:
+			prop := *change
+			prop.ID = cur.ID
+			res[newName] = s.newProperty(newName, prop)
+		}
+	}
+
+	titles := 0
+	for _, prop := range res {
+		if prop.Type == notion.DBPropTypeTitle {
+			titles++
+		}
+	}
+	if titles != 1 {
+		return nil, validationError("Database must have exactly one title property, would have %d.", titles)
+	}
+	return res, nil
+}
+
+func (s *Server) queryDatabase(r *http.Request, ids []string) (interface{}, error) {
+	db, err := s.database(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	var query notion.DatabaseQuery
+	if err := decodeBody(r, &query); err != nil {
+		return nil, err
+	}
+
+	var pages []notion.Page
+	for _, id := range s.objects {
+		p, ok := s.pages[key(id)]
+		if ok && !p.Archived && p.Parent.DatabaseID != nil && key(*p.Parent.DatabaseID) == key(db.ID) {
+			pages = append(pages, *p)
+		}
+	}
+	e := &notion.Evaluator{Now: s.now}
+	pages, err = e.Query(pages, &query)
+	if err != nil {
+		return nil, validationError("%v.", err)
+	}
+
+	pageIDs := make([]string, len(pages))
+	for i, p := range pages {
+		pageIDs[i] = p.ID
+	}
+	return paginate(pageIDs, query.StartCursor, query.PageSize, func(i int) (json.RawMessage, error) {
+		return withObject("page", &pages[i])
+	})
+}
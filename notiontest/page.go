@@ -0,0 +1,239 @@
+package notiontest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kjk/notion"
+)
+
+func (s *Server) insertPage(page notion.Page, children []notion.Block) (*notion.Page, error) {
+	if page.ID == "" {
+		page.ID = s.newID()
+	}
+	if s.exists(page.ID) {
+		return nil, validationError("Object with ID %s already exists.", page.ID)
+	}
+	now := s.now()
+	if page.CreatedTime.IsZero() {
+		page.CreatedTime = now
+	}
+	if page.LastEditedTime.IsZero() {
+		page.LastEditedTime = now
+	}
+	page.RawJSON = nil
+
+	var parentID string
+	switch {
+	case page.Parent.DatabaseID != nil:
+		db, err := s.database(*page.Parent.DatabaseID)
+		if err != nil {
+			return nil, err
+		}
+		props, _ := page.Properties.(notion.DatabasePageProperties)
+		values, err := propertyValues(db.Properties, props)
+		if err != nil {
+			return nil, err
+		}
+		page.Parent = notion.PageParent{Type: notion.ParentTypeDatabase, DatabaseID: &db.ID}
+		page.Properties = pageProperties(db.Properties, &page, values)
+	case page.Parent.PageID != nil:
+		parent, ok := s.pages[key(*page.Parent.PageID)]
+		if !ok || parent.Archived {
+			return nil, notFound(*page.Parent.PageID)
+		}
+		parentID = parent.ID
+		page.Parent = notion.PageParent{Type: notion.ParentTypePage, PageID: &parent.ID}
+		page.Properties = titleProperties(page.Title())
+	case page.Parent.Type == "workspace":
+		page.Properties = titleProperties(page.Title())
+	default:
+		return nil, validationError("Page parent must be a page, a database or the workspace.")
+	}
+	// validate children before the page is stored, so that an invalid
+	// request doesn't leave a page without them
+	if err := validateBlocks("body.children", children); err != nil {
+		return nil, err
+	}
+
+	s.pages[key(page.ID)] = &page
+	s.objects = append(s.objects, page.ID)
+	block := &notion.Block{
+		ID:             page.ID,
+		Type:           notion.BlockTypeChildPage,
+		CreatedTime:    &page.CreatedTime,
+		LastEditedTime: &page.LastEditedTime,
+		ChildPage:      &notion.ChildPage{Title: notion.PlainText(page.Title())},
+	}
+	s.addBlock(parentID, block)
+	s.addBlocks(page.ID, children)
+	return &page, nil
+}
+
+func titleProperties(title []notion.RichText) notion.PageProperties {
+	return notion.PageProperties{Title: notion.PageTitle{Title: richText(title)}}
+}
+
+// propertyValues returns values of properties of a page, by property ID,
+// with types from the database schema. Properties are identified by name
+// or ID.
+func propertyValues(schema notion.DatabaseProperties, props notion.DatabasePageProperties) (map[string]notion.DatabasePageProperty, error) {
+	res := map[string]notion.DatabasePageProperty{}
+	for name, v := range props {
+		prop, ok := schema[name]
+		if !ok {
+			for _, p := range schema {
+				if p.ID == name {
+					prop, ok = p, true
+				}
+			}
+		}
+		if !ok {
+			return nil, validationError("%s is not a property that exists.", name)
+		}
+		if v.Type != "" && v.Type != prop.Type {
+			return nil, validationError("%s is expected to be %s.", name, prop.Type)
+		}
+		v.ID, v.Type = prop.ID, prop.Type
+		v.Title, v.RichText = richText(v.Title), richText(v.RichText)
+		v.RawJSON = nil
+		res[prop.ID] = v
+	}
+	return res, nil
+}
+
+// valuesByID returns property values of a page by property ID.
+func valuesByID(props notion.DatabasePageProperties) map[string]notion.DatabasePageProperty {
+	res := map[string]notion.DatabasePageProperty{}
+	for _, v := range props {
+		res[v.ID] = v
+	}
+	return res
+}
+
+// pageProperties returns values of all properties of a page in a database
+// with a given schema. Values are taken from values by property ID. New
+// properties and properties that changed type are empty.
+func pageProperties(schema notion.DatabaseProperties, page *notion.Page, values map[string]notion.DatabasePageProperty) notion.DatabasePageProperties {
+	res := notion.DatabasePageProperties{}
+	for name, prop := range schema {
+		v, ok := values[prop.ID]
+		if !ok || v.Type != prop.Type {
+			v = notion.DatabasePageProperty{ID: prop.ID, Type: prop.Type}
+		}
+		switch prop.Type {
+		case notion.DBPropTypeCheckbox:
+			if v.Checkbox == nil {
+				v.Checkbox = new(bool)
+			}
+		case notion.DBPropTypeCreatedTime:
+			t := page.CreatedTime
+			v.CreatedTime = &t
+		case notion.DBPropTypeLastEditedTime:
+			t := page.LastEditedTime
+			v.LastEditedTime = &t
+		}
+		res[name] = v
+	}
+	return res
+}
+
+func (s *Server) createPage(r *http.Request, ids []string) (interface{}, error) {
+	var body struct {
+		Parent     notion.PageParent `json:"parent"`
+		Properties json.RawMessage   `json:"properties"`
+		Children   []notion.Block    `json:"children"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	page := notion.Page{Parent: body.Parent}
+	props, err := decodeProperties(body.Parent, body.Properties)
+	if err != nil {
+		return nil, err
+	}
+	page.Properties = props
+
+	p, err := s.insertPage(page, body.Children)
+	if err != nil {
+		return nil, err
+	}
+	return withObject("page", p)
+}
+
+// decodeProperties decodes properties of a page in request body, which are
+// either values of database properties or a title.
+func decodeProperties(parent notion.PageParent, d json.RawMessage) (interface{}, error) {
+	if parent.DatabaseID != nil {
+		var props notion.DatabasePageProperties
+		if len(d) > 0 {
+			if err := json.Unmarshal(d, &props); err != nil {
+				return nil, validationError("Invalid properties: %v.", err)
+			}
+		}
+		return props, nil
+	}
+	var title notion.PageTitle
+	if len(d) > 0 {
+		if err := json.Unmarshal(d, &title); err != nil {
+			return nil, validationError("Invalid properties: %v.", err)
+		}
+	}
+	return notion.PageProperties{Title: title}, nil
+}
+
+func (s *Server) getPage(r *http.Request, ids []string) (interface{}, error) {
+	page, ok := s.pages[key(ids[0])]
+	if !ok {
+		return nil, notFound(ids[0])
+	}
+	return withObject("page", page)
+}
+
+func (s *Server) updatePage(r *http.Request, ids []string) (interface{}, error) {
+	page, ok := s.pages[key(ids[0])]
+	if !ok {
+		return nil, notFound(ids[0])
+	}
+	var body struct {
+		Properties json.RawMessage `json:"properties"`
+		Archived   *bool           `json:"archived"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	if page.Archived && (body.Archived == nil || *body.Archived) {
+		return nil, validationError("Can't edit page that is archived. You must unarchive the page before editing.")
+	}
+	props, err := decodeProperties(page.Parent, body.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	block := s.blocks[key(page.ID)]
+	page.LastEditedTime = s.now()
+	block.LastEditedTime = &page.LastEditedTime
+	switch props := props.(type) {
+	case notion.DatabasePageProperties:
+		db := s.databases[key(*page.Parent.DatabaseID)]
+		values, err := propertyValues(db.Properties, props)
+		if err != nil {
+			return nil, err
+		}
+		merged := valuesByID(page.Properties.(notion.DatabasePageProperties))
+		for id, v := range values {
+			merged[id] = v
+		}
+		page.Properties = pageProperties(db.Properties, page, merged)
+	case notion.PageProperties:
+		if props.Title.Title != nil {
+			page.Properties = titleProperties(props.Title.Title)
+		}
+	}
+	block.ChildPage.Title = notion.PlainText(page.Title())
+	if body.Archived != nil {
+		page.Archived = *body.Archived
+		block.Archived = *body.Archived
+	}
+	return withObject("page", page)
+}
@@ -0,0 +1,313 @@
+package notiontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// Mode is a mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay responds with responses from the cassette, without
+	// sending requests. Requests that are not in the cassette fail.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and writes them with their responses to
+	// the cassette on Close, replacing its content.
+	ModeRecord
+)
+
+// RecorderOptions are options of a Recorder.
+type RecorderOptions struct {
+	// Transport sends requests in ModeRecord. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+	// ScrubHeaders are names of headers that are not written to the
+	// cassette. Authorization header is never written.
+	ScrubHeaders []string
+	// ScrubFields are names of JSON fields, at any depth of request and
+	// response bodies, whose values are replaced with "[scrubbed]" e.g.
+	// "email". Requests are matched after scrubbing.
+	ScrubFields []string
+}
+
+// Recorder is an http.RoundTripper that records requests to the Notion API
+// and their responses to a cassette file and replays them, for
+// deterministic tests of code that uses the API without network access:
+//
+//	mode := notiontest.ModeReplay
+//	if os.Getenv("NOTION_RECORD") != "" {
+//		mode = notiontest.ModeRecord
+//	}
+//	rec, err := notiontest.NewRecorder("testdata/query.json", mode, nil)
+//	...
+//	defer rec.Close()
+//	client := notion.NewClient(os.Getenv("NOTION_TOKEN"), &notion.ClientOptions{HTTPClient: rec.HTTPClient()})
+//
+// Requests are matched on method, path, query and JSON body, ignoring
+// formatting and order of fields. Each recorded interaction is replayed
+// once, so repeated requests get responses in the order they were recorded.
+type Recorder struct {
+	path string
+	mode Mode
+	opts RecorderOptions
+
+	mu           sync.Mutex
+	interactions []*interaction
+	used         []bool
+}
+
+// cassette is the format of a cassette file.
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	recordedBody
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	recordedBody
+}
+
+// recordedBody is a body in JSON, so that it's readable in the cassette,
+// or as text if it's not JSON.
+type recordedBody struct {
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"body_text,omitempty"`
+}
+
+func (b recordedBody) bytes() []byte {
+	if b.Body != nil {
+		return b.Body
+	}
+	return []byte(b.BodyText)
+}
+
+// NewRecorder returns a Recorder using a cassette file at path. In
+// ModeReplay the cassette is read and must exist.
+func NewRecorder(path string, mode Mode, opts *RecorderOptions) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Transport == nil {
+		r.opts.Transport = http.DefaultTransport
+	}
+	if mode == ModeReplay {
+		d, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("notiontest: failed to read cassette: %w", err)
+		}
+		var c cassette
+		if err := json.Unmarshal(d, &c); err != nil {
+			return nil, fmt.Errorf("notiontest: failed to parse cassette %s: %w", path, err)
+		}
+		r.interactions = c.Interactions
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// HTTPClient returns an HTTP client using the Recorder, for
+// notion.ClientOptions.HTTPClient.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("notiontest: failed to read request body: %w", err)
+		}
+	}
+	recReq := recordedRequest{
+		Method:       req.Method,
+		URL:          req.URL.String(),
+		Header:       r.scrubHeader(req.Header),
+		recordedBody: r.scrubBody(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recReq)
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := r.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("notiontest: failed to read response body: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, &interaction{
+		Request: recReq,
+		Response: recordedResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       r.scrubHeader(resp.Header),
+			recordedBody: r.scrubBody(respBody),
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recReq recordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.interactions {
+		if r.used[i] || !requestsMatch(in.Request, recReq) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			StatusCode: in.Response.StatusCode,
+			Status:     fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     in.Response.Header.Clone(),
+			Body:       ioutil.NopCloser(bytes.NewReader(in.Response.bytes())),
+			Request:    req,
+		}, nil
+	}
+	return nil, fmt.Errorf("notiontest: no unused interaction in cassette %s matches request %s %s with body %s",
+		r.path, recReq.Method, recReq.URL, recReq.bytes())
+}
+
+// requestsMatch returns true if requests have the same method, path,
+// query and body.
+func requestsMatch(a, b recordedRequest) bool {
+	if a.Method != b.Method {
+		return false
+	}
+	ua, errA := url.Parse(a.URL)
+	ub, errB := url.Parse(b.URL)
+	if errA != nil || errB != nil || ua.Path != ub.Path || !reflect.DeepEqual(ua.Query(), ub.Query()) {
+		return false
+	}
+	if a.Body == nil || b.Body == nil {
+		return bytes.Equal(a.bytes(), b.bytes())
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a.Body, &va) != nil || json.Unmarshal(b.Body, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	h = h.Clone()
+	h.Del("Authorization")
+	for _, name := range r.opts.ScrubHeaders {
+		h.Del(name)
+	}
+	if len(h) == 0 {
+		return nil
+	}
+	return h
+}
+
+func (r *Recorder) scrubBody(body []byte) recordedBody {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return recordedBody{}
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return recordedBody{BodyText: string(body)}
+	}
+	fields := map[string]bool{}
+	for _, f := range r.opts.ScrubFields {
+		fields[f] = true
+	}
+	d, err := json.Marshal(scrub(v, fields))
+	if err != nil {
+		return recordedBody{BodyText: string(body)}
+	}
+	return recordedBody{Body: d}
+}
+
+// scrub replaces values of fields in JSON value v.
+func scrub(v interface{}, fields map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if fields[k] {
+				v[k] = "[scrubbed]"
+			} else {
+				v[k] = scrub(fv, fields)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = scrub(v[i], fields)
+		}
+	}
+	return v
+}
+
+// Close writes the cassette in ModeRecord.
+func (r *Recorder) Close() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("notiontest: failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("notiontest: failed to write cassette: %w", err)
+	}
+	if err := ioutil.WriteFile(r.path, append(d, '\n'), 0644); err != nil {
+		return fmt.Errorf("notiontest: failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Unused returns descriptions of interactions in the cassette that were not
+// replayed, e.g. to check that code makes all recorded requests.
+func (r *Recorder) Unused() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []string
+	for i, in := range r.interactions {
+		if r.mode == ModeReplay && !r.used[i] {
+			res = append(res, in.Request.Method+" "+in.Request.URL)
+		}
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+package notiontest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/notiontest"
+)
+
+// recordedCalls makes requests recorded in TestRecorder and returns IDs of
+// queried pages and the email of the user.
+func recordedCalls(t *testing.T, client *notion.Client, rootID string) ([]string, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := client.CreateDatabase(ctx, notion.CreateDatabaseParams{
+		ParentPageID: rootID,
+		Properties:   notion.DatabaseProperties{"Name": {Type: notion.DBPropTypeTitle}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		_, err := client.CreatePage(ctx, notion.CreatePageParams{
+			ParentType:             notion.ParentTypeDatabase,
+			ParentID:               db.ID,
+			DatabasePageProperties: &notion.DatabasePageProperties{"Name": {Type: notion.DBPropTypeTitle, Title: text(name)}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	pages, err := client.QueryDatabaseIterator(db.ID, &notion.DatabaseQuery{PageSize: 1}).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users, err := client.ListUsers(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return pageIDs(pages), users.Results[0].Person.Email
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "testdata", "cassette.json")
+	srv, root := newServer(t)
+	srv.AddUser(notion.User{Name: "Ann", Person: &notion.Person{Email: "ann@example.com"}})
+	baseURL := srv.ClientOptions().BaseURL
+
+	rec, err := notiontest.NewRecorder(path, notiontest.ModeRecord, &notiontest.RecorderOptions{
+		Transport:   srv.ClientOptions().HTTPClient.Transport,
+		ScrubFields: []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := notion.NewClient("secret-api-key", &notion.ClientOptions{HTTPClient: rec.HTTPClient(), BaseURL: baseURL})
+	recordedIDs, email := recordedCalls(t, client, root.ID)
+	if email != "ann@example.com" {
+		t.Fatalf("expected real response when recording, got email %q", email)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv.Close()
+
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-api-key", "ann@example.com"} {
+		if strings.Contains(string(d), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	rec, err = notiontest.NewRecorder(path, notiontest.ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client = notion.NewClient("other-api-key", &notion.ClientOptions{HTTPClient: rec.HTTPClient(), BaseURL: baseURL})
+	replayedIDs, email := recordedCalls(t, client, root.ID)
+	if strings.Join(replayedIDs, ",") != strings.Join(recordedIDs, ",") || len(replayedIDs) != 2 {
+		t.Errorf("replayed results not equal (expected: %v, got: %v)", recordedIDs, replayedIDs)
+	}
+	if email != "[scrubbed]" {
+		t.Errorf("expected scrubbed email, got %q", email)
+	}
+	if unused := rec.Unused(); len(unused) != 0 {
+		t.Errorf("expected all interactions to be replayed, unused: %v", unused)
+	}
+
+	_, err = client.GetUser(context.Background(), "be32e790-8292-46df-a248-b784fdf483cf")
+	exp := "notiontest: no unused interaction in cassette " + path + " matches request GET " + baseURL + "/users/be32e790-8292-46df-a248-b784fdf483cf"
+	if err == nil || !strings.Contains(err.Error(), exp) {
+		t.Errorf("expected error containing %q, got %v", exp, err)
+	}
+}
+
+func TestRecorderMatchesNormalizedBody(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cassette := `{
+		"interactions": [{
+			"request": {
+				"method": "POST",
+				"url": "https://api.notion.com/v1/search?x=1&y=2",
+				"body": {"query": "tasks", "page_size": 10}
+			},
+			"response": {"status_code": 200, "body": {"object": "list", "results": []}}
+		}]
+	}`
+	if err := ioutil.WriteFile(path, []byte(cassette), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := notiontest.NewRecorder(path, notiontest.ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body := "{\n  \"page_size\": 10,\n  \"query\": \"tasks\"\n}"
+	resp, err := rec.HTTPClient().Post("https://api.notion.com/v1/search?y=2&x=1", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %v", resp.Status)
+	}
+
+	_, err = rec.HTTPClient().Post("https://api.notion.com/v1/search?y=2&x=1", "application/json", strings.NewReader(body))
+	if err == nil || !strings.Contains(err.Error(), "no unused interaction") {
+		t.Fatalf("expected error for a request replayed twice, got %v", err)
+	}
+}
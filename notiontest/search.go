@@ -0,0 +1,80 @@
+package notiontest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/kjk/notion"
+)
+
+func (s *Server) search(r *http.Request, ids []string) (interface{}, error) {
+	var body struct {
+		Query  string `json:"query"`
+		Filter *struct {
+			Property string `json:"property"`
+			Value    string `json:"value"`
+		} `json:"filter"`
+		Sort *struct {
+			Direction notion.SortDirection `json:"direction"`
+			Timestamp string               `json:"timestamp"`
+		} `json:"sort"`
+		StartCursor string `json:"start_cursor"`
+		PageSize    int    `json:"page_size"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	object := ""
+	if f := body.Filter; f != nil {
+		if f.Property != "object" || (f.Value != "page" && f.Value != "database") {
+			return nil, validationError("body.filter should be an object filter with value page or database.")
+		}
+		object = f.Value
+	}
+
+	type result struct {
+		id, object     string
+		lastEditedTime int64
+		v              interface{}
+	}
+	var results []result
+	query := strings.ToLower(body.Query)
+	for _, id := range s.objects {
+		res := result{id: id}
+		var title []notion.RichText
+		if p, ok := s.pages[key(id)]; ok {
+			res.object, res.lastEditedTime, res.v = "page", p.LastEditedTime.UnixNano(), p
+			title = p.Title()
+		} else {
+			db := s.databases[key(id)]
+			res.object, res.lastEditedTime, res.v = "database", db.LastEditedTime.UnixNano(), db
+			title = db.Title
+		}
+		if s.blocks[key(id)].Archived || object != "" && res.object != object {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(notion.PlainText(title)), query) {
+			continue
+		}
+		results = append(results, res)
+	}
+	if body.Sort != nil {
+		desc := body.Sort.Direction == notion.SortDirDesc
+		sort.SliceStable(results, func(i, j int) bool {
+			if desc {
+				return results[i].lastEditedTime > results[j].lastEditedTime
+			}
+			return results[i].lastEditedTime < results[j].lastEditedTime
+		})
+	}
+
+	resultIDs := make([]string, len(results))
+	for i, res := range results {
+		resultIDs[i] = res.id
+	}
+	return paginate(resultIDs, body.StartCursor, body.PageSize, func(i int) (json.RawMessage, error) {
+		return withObject(results[i].object, results[i].v)
+	})
+}
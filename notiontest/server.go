@@ -0,0 +1,382 @@
+// Package notiontest provides a fake Notion API server for tests.
+//
+// Server stores pages, databases, blocks and users in memory and implements
+// the endpoints used by notion.Client, so code that makes several requests
+// (e.g. creates a page and then queries a database) can be tested without
+// stubbing each response:
+//
+//	srv := notiontest.NewServer()
+//	defer srv.Close()
+//	root := srv.AddPage(notion.Page{Parent: notion.PageParent{Type: "workspace"}})
+//	client := srv.Client()
+//	db, err := client.CreateDatabase(ctx, notion.CreateDatabaseParams{ParentPageID: root.ID, ...})
+//
+// Database queries are evaluated with notion.Evaluator. Formula and rollup
+// values are not computed.
+//
+// Recorder records requests to the real API and replays them, for tests of
+// behavior the fake doesn't implement.
+package notiontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kjk/notion"
+)
+
+// Server is a fake Notion API server.
+type Server struct {
+	// URL is the base URL of the server, e.g. "http://127.0.0.1:1234".
+	URL string
+
+	// Now returns current time, used for created and last edited times and
+	// by relative date filters. Defaults to time.Now. Set it before making
+	// requests.
+	Now func() time.Time
+
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	lastID    int
+	databases map[string]*notion.Database
+	pages     map[string]*notion.Page
+	// objects are IDs of pages and databases in order of creation.
+	objects []string
+	// blocks has a child_page block for every page and a child_database
+	// block for every database, like the API.
+	blocks map[string]*notion.Block
+	// children are IDs of child blocks of pages and blocks.
+	children map[string][]string
+	users    map[string]*notion.User
+	userIDs  []string
+}
+
+// NewServer starts and returns a new Server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		databases: map[string]*notion.Database{},
+		pages:     map[string]*notion.Page{},
+		blocks:    map[string]*notion.Block{},
+		children:  map[string][]string{},
+		users:     map[string]*notion.User{},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a notion.Client that sends requests to the server.
+func (s *Server) Client() *notion.Client {
+	return notion.NewClient("secret-api-key", s.ClientOptions())
+}
+
+// ClientOptions returns options of a notion.Client that sends requests to
+// the server, to combine the server with other client options.
+func (s *Server) ClientOptions() *notion.ClientOptions {
+	return &notion.ClientOptions{
+		HTTPClient: s.srv.Client(),
+		BaseURL:    s.URL + "/v1",
+	}
+}
+
+// AddUser adds a user. An ID is generated if not set.
+func (s *Server) AddUser(user notion.User) notion.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID == "" {
+		user.ID = s.newID()
+	}
+	if user.Type == "" {
+		user.Type = "person"
+	}
+	s.users[key(user.ID)] = &user
+	s.userIDs = append(s.userIDs, user.ID)
+	return user
+}
+
+// AddPage adds a page, e.g. a workspace page to create other pages and
+// databases in. An ID is generated if not set. Pages in a database get
+// properties of the database schema. It panics if the parent doesn't exist
+// or properties don't match the schema.
+func (s *Server) AddPage(page notion.Page, children ...notion.Block) notion.Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.insertPage(page, children)
+	if err != nil {
+		panic(fmt.Sprintf("notiontest: failed to add page: %v", err))
+	}
+	var res notion.Page
+	mustClone(p, &res)
+	return res
+}
+
+// AddDatabase adds a database as a child of a page. An ID is generated if
+// not set. It panics if the page doesn't exist or the schema is invalid.
+func (s *Server) AddDatabase(parentPageID string, db notion.Database) notion.Database {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, err := s.insertDatabase(parentPageID, db)
+	if err != nil {
+		panic(fmt.Sprintf("notiontest: failed to add database: %v", err))
+	}
+	var res notion.Database
+	mustClone(d, &res)
+	return res
+}
+
+// AppendBlocks adds blocks as children of a page or a block. It panics if
+// the parent doesn't exist.
+func (s *Server) AppendBlocks(parentID string, blocks ...notion.Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.appendBlocks(parentID, blocks); err != nil {
+		panic(fmt.Sprintf("notiontest: failed to append blocks: %v", err))
+	}
+}
+
+func mustClone(src, dst interface{}) {
+	d, err := json.Marshal(src)
+	if err == nil {
+		err = json.Unmarshal(d, dst)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("notiontest: failed to copy %T: %v", src, err))
+	}
+}
+
+func (s *Server) now() time.Time {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	return now().UTC().Truncate(time.Millisecond)
+}
+
+// newID returns a new ID in the format of Notion IDs. IDs are sequential,
+// so that they are the same in every run of a test.
+func (s *Server) newID() string {
+	s.lastID++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.lastID)
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func notFound(id string) error {
+	return &apiError{
+		status:  http.StatusNotFound,
+		code:    "object_not_found",
+		message: fmt.Sprintf("Could not find object with ID: %s.", id),
+	}
+}
+
+func validationError(format string, args ...interface{}) error {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    "validation_error",
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+type route struct {
+	method string
+	// pattern is a path with "*" matching an ID.
+	pattern string
+	handle  func(s *Server, r *http.Request, ids []string) (interface{}, error)
+}
+
+var routes = []route{
+	{http.MethodPost, "/v1/databases", (*Server).createDatabase},
+	{http.MethodGet, "/v1/databases/*", (*Server).getDatabase},
+	{http.MethodPatch, "/v1/databases/*", (*Server).updateDatabase},
+	{http.MethodPost, "/v1/databases/*/query", (*Server).queryDatabase},
+	{http.MethodPost, "/v1/pages", (*Server).createPage},
+	{http.MethodGet, "/v1/pages/*", (*Server).getPage},
+	{http.MethodPatch, "/v1/pages/*", (*Server).updatePage},
+	{http.MethodGet, "/v1/blocks/*", (*Server).getBlock},
+	{http.MethodPatch, "/v1/blocks/*", (*Server).updateBlock},
+	{http.MethodDelete, "/v1/blocks/*", (*Server).deleteBlock},
+	{http.MethodGet, "/v1/blocks/*/children", (*Server).getBlockChildren},
+	{http.MethodPatch, "/v1/blocks/*/children", (*Server).appendBlockChildren},
+	{http.MethodGet, "/v1/users", (*Server).listUsers},
+	{http.MethodGet, "/v1/users/*", (*Server).getUser},
+	{http.MethodPost, "/v1/search", (*Server).search},
+}
+
+// match returns IDs in path matched by "*" in pattern.
+func match(pattern, path string) ([]string, bool) {
+	want, got := strings.Split(pattern, "/"), strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+	var ids []string
+	for i := range want {
+		switch {
+		case want[i] == "*" && got[i] != "":
+			ids = append(ids, got[i])
+		case want[i] != got[i]:
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	res, err := s.handle(r)
+	if err != nil {
+		apiErr, ok := err.(*apiError)
+		if !ok {
+			apiErr = &apiError{status: http.StatusInternalServerError, code: "internal_server_error", message: err.Error()}
+		}
+		writeJSON(w, apiErr.status, notion.APIError{
+			Object:  "error",
+			Status:  apiErr.status,
+			Code:    apiErr.code,
+			Message: apiErr.message,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handle(r *http.Request) (interface{}, error) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || len(r.Header.Get("Authorization")) == len("Bearer ") {
+		return nil, &apiError{status: http.StatusUnauthorized, code: "unauthorized", message: "API token is invalid."}
+	}
+	for _, rt := range routes {
+		ids, ok := match(rt.pattern, r.URL.Path)
+		if !ok || rt.method != r.Method {
+			continue
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return rt.handle(s, r, ids)
+	}
+	return nil, &apiError{
+		status:  http.StatusBadRequest,
+		code:    "invalid_request_url",
+		message: fmt.Sprintf("Invalid request URL: %s %s.", r.Method, r.URL.Path),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	d, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(d)
+}
+
+// decodeBody decodes JSON body of a request. An empty body is valid.
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    "invalid_json",
+			message: fmt.Sprintf("Error parsing JSON body: %v.", err),
+		}
+	}
+	return nil
+}
+
+// withObject returns v encoded as JSON with the "object" field, which is
+// included in API responses.
+func withObject(object string, v interface{}) (json.RawMessage, error) {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(d, &fields); err != nil {
+		return nil, err
+	}
+	fields["object"], _ = json.Marshal(object)
+	return json.Marshal(fields)
+}
+
+// list is a paginated list of results.
+type list struct {
+	Object     string            `json:"object"`
+	Results    []json.RawMessage `json:"results"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+const maxPageSize = 100
+
+// paginate returns a list of results with a given IDs, starting at cursor.
+// result returns the JSON of a result.
+func paginate(ids []string, cursor string, pageSize int, result func(i int) (json.RawMessage, error)) (*list, error) {
+	start := 0
+	if cursor != "" {
+		start = -1
+		for i, id := range ids {
+			if id == cursor {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, validationError("start_cursor %s is not valid.", cursor)
+		}
+	}
+	if pageSize <= 0 || pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	end := start + pageSize
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	res := &list{Object: "list", Results: []json.RawMessage{}}
+	for i := start; i < end; i++ {
+		d, err := result(i)
+		if err != nil {
+			return nil, err
+		}
+		res.Results = append(res.Results, d)
+	}
+	if end < len(ids) {
+		res.HasMore = true
+		res.NextCursor = &ids[end]
+	}
+	return res, nil
+}
+
+// paginationQuery returns pagination params from the URL of a GET request.
+func paginationQuery(r *http.Request) (cursor string, pageSize int, err error) {
+	q := r.URL.Query()
+	if v := q.Get("page_size"); v != "" {
+		if _, err := fmt.Sscan(v, &pageSize); err != nil {
+			return "", 0, validationError("page_size should be a number, instead was %q.", v)
+		}
+	}
+	return q.Get("start_cursor"), pageSize, nil
+}
@@ -0,0 +1,390 @@
+package notiontest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/notiontest"
+)
+
+func newServer(t *testing.T) (*notiontest.Server, notion.Page) {
+	t.Helper()
+	srv := notiontest.NewServer()
+	t.Cleanup(srv.Close)
+	now := time.Date(2021, 7, 8, 12, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	root := srv.AddPage(notion.Page{
+		Parent:     notion.PageParent{Type: "workspace"},
+		Properties: notion.PageProperties{Title: notion.PageTitle{Title: []notion.RichText{{Text: &notion.Text{Content: "Root"}}}}},
+	})
+	return srv, root
+}
+
+func text(s string) []notion.RichText {
+	return []notion.RichText{{Text: &notion.Text{Content: s}}}
+}
+
+func pageIDs(pages []notion.Page) []string {
+	ids := []string{}
+	for _, p := range pages {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestDatabaseWorkflow(t *testing.T) {
+	t.Parallel()
+
+	srv, root := newServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+
+	props, err := notion.NewSchema().
+		Title("Name").
+		Select("Status", notion.SelectOptions{Name: "To do"}, notion.SelectOptions{Name: "Done", Color: notion.ColorGreen}).
+		Number("Price", notion.NumberFormatDollar).
+		Checkbox("Urgent").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := client.CreateDatabase(ctx, notion.CreateDatabaseParams{
+		ParentPageID: root.ID,
+		Title:        text("Tasks"),
+		Properties:   props,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.Properties["Name"].ID != "title" || db.Properties["Status"].Select.Options[1].Color != notion.ColorGreen {
+		t.Fatalf("unexpected properties: %+v", db.Properties)
+	}
+
+	var ids []string
+	for i, price := range []float64{5, 20, 12.5} {
+		price := price
+		page, err := client.CreatePage(ctx, notion.CreatePageParams{
+			ParentType: notion.ParentTypeDatabase,
+			ParentID:   db.ID,
+			DatabasePageProperties: &notion.DatabasePageProperties{
+				"Name":  {Type: notion.DBPropTypeTitle, Title: text([]string{"Write", "Test", "Ship"}[i])},
+				"Price": {Type: notion.DBPropTypeNumber, Number: &price},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, page.ID)
+	}
+
+	page, err := client.GetPage(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pageProps := page.Properties.(notion.DatabasePageProperties)
+	if urgent, ok := pageProps.Checkbox("Urgent"); !ok || urgent {
+		t.Fatalf("expected empty Urgent checkbox, got %+v", pageProps["Urgent"])
+	}
+	if notion.PlainText(page.Title()) != "Write" {
+		t.Fatalf("unexpected title: %+v", page.Title())
+	}
+
+	yes := true
+	_, err = client.UpdatePageProps(ctx, ids[2], notion.UpdatePageParams{
+		DatabasePageProperties: &notion.DatabasePageProperties{
+			"Urgent": {Type: notion.DBPropTypeCheckbox, Checkbox: &yes},
+			"Status": {Type: notion.DBPropTypeSelect, Select: &notion.SelectOptions{Name: "Done"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	filter, err := notion.Filter.Prop("Price").Number().GreaterThan(10).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := &notion.DatabaseQuery{
+		Filter:   filter,
+		Sorts:    []notion.DatabaseQuerySort{{Property: "Price", Direction: notion.SortDirDesc}},
+		PageSize: 1,
+	}
+	res, err := client.QueryDatabase(ctx, db.ID, query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{ids[1]}, pageIDs(res.Results)); diff != "" || !res.HasMore {
+		t.Fatalf("first page not equal (has more: %v) (-exp, +got):\n%v", res.HasMore, diff)
+	}
+	pages, err := client.QueryDatabaseIterator(db.ID, query).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{ids[1], ids[2]}, pageIDs(pages)); diff != "" {
+		t.Fatalf("results not equal (-exp, +got):\n%v", diff)
+	}
+
+	filter, err = notion.Filter.Prop("Urgent").Checkbox().Equals(true).
+		And(notion.Filter.Prop("Status").Select().Equals("Done")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, err = client.QueryDatabaseIterator(db.ID, &notion.DatabaseQuery{Filter: filter}).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{ids[2]}, pageIDs(pages)); diff != "" {
+		t.Fatalf("results not equal (-exp, +got):\n%v", diff)
+	}
+
+	db, err = client.UpdateDatabase(ctx, db.ID, notion.UpdateDatabaseParams{
+		Properties: map[string]*notion.DatabaseProperty{
+			"Price":  {Name: "Cost"},
+			"Urgent": nil,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := db.Properties["Cost"]; !ok {
+		t.Fatalf("expected Cost property, got %+v", db.Properties)
+	}
+	page, err = client.GetPage(ctx, ids[2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pageProps = page.Properties.(notion.DatabasePageProperties)
+	if cost, _ := pageProps.Number("Cost"); cost != 12.5 {
+		t.Fatalf("expected renamed Cost property, got %+v", pageProps)
+	}
+	if _, ok := pageProps["Urgent"]; ok {
+		t.Fatalf("expected Urgent property to be removed, got %+v", pageProps)
+	}
+
+	if _, err := client.DeleteBlock(ctx, ids[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pages, err = client.QueryDatabaseIterator(db.ID, nil).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{ids[0], ids[2]}, pageIDs(pages)); diff != "" {
+		t.Fatalf("results not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	t.Parallel()
+
+	srv, root := newServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+
+	page, err := client.CreatePage(ctx, notion.CreatePageParams{
+		ParentType: notion.ParentTypePage,
+		ParentID:   root.ID,
+		Title:      text("Notes"),
+		Children: []notion.Block{
+			{Type: notion.BlockTypeHeading1, Heading1: &notion.Heading{Text: text("Intro")}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var children []notion.Block
+	for i := 0; i < 3; i++ {
+		children = append(children, notion.Block{Type: notion.BlockTypeParagraph, Paragraph: &notion.RichTextBlock{Text: text("paragraph")}})
+	}
+	children[1].Paragraph.Children = []notion.Block{
+		{Type: notion.BlockTypeToDo, ToDo: &notion.ToDo{RichTextBlock: notion.RichTextBlock{Text: text("nested")}}},
+	}
+	if _, err := client.AppendBlockChildren(ctx, page.ID, children); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := client.GetBlockChildren(ctx, page.ID, &notion.PaginationQuery{PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Results) != 2 || !res.HasMore || res.Results[0].Type != notion.BlockTypeHeading1 {
+		t.Fatalf("unexpected first page of children: %+v", res)
+	}
+
+	tree, err := client.GetBlockTree(ctx, root.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree) != 1 || tree[0].ChildPage == nil || tree[0].ChildPage.Title != "Notes" {
+		t.Fatalf("expected child page, got %+v", tree)
+	}
+	blocks := tree[0].Children
+	if len(blocks) != 4 || len(blocks[2].Children) != 1 || blocks[2].Children[0].Type != notion.BlockTypeToDo {
+		t.Fatalf("unexpected blocks: %+v", blocks)
+	}
+	if blocks[2].Paragraph.Children != nil {
+		t.Fatalf("expected children not to be nested in paragraph, got %+v", blocks[2].Paragraph)
+	}
+
+	updated, err := client.UpdateBlock(ctx, blocks[1].ID, notion.Block{
+		Type:      notion.BlockTypeParagraph,
+		Paragraph: &notion.RichTextBlock{Text: text("updated")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notion.PlainText(updated.Paragraph.Text) != "updated" {
+		t.Fatalf("unexpected block: %+v", updated.Paragraph)
+	}
+	_, err = client.UpdateBlock(ctx, blocks[1].ID, notion.Block{
+		Type:     notion.BlockTypeHeading2,
+		Heading2: &notion.Heading{Text: text("heading")},
+	})
+	if !errors.Is(err, notion.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	if _, err := client.DeleteBlock(ctx, blocks[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	all, err := client.GetBlockChildrenIterator(page.ID, nil).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != blocks[1].ID {
+		t.Fatalf("expected deleted block to be skipped, got %+v", all)
+	}
+}
+
+func TestSearchAndUsers(t *testing.T) {
+	t.Parallel()
+
+	srv, root := newServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+
+	db := srv.AddDatabase(root.ID, notion.Database{
+		Title:      text("Task list"),
+		Properties: notion.DatabaseProperties{"Name": {Type: notion.DBPropTypeTitle}},
+	})
+	task := srv.AddPage(notion.Page{
+		Parent:     notion.PageParent{DatabaseID: &db.ID},
+		Properties: notion.DatabasePageProperties{"Name": {Title: text("First task")}},
+	})
+
+	res, err := client.Search(ctx, &notion.SearchOpts{Query: "TASK"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", res.Results)
+	}
+	if got, ok := res.Results[0].(*notion.Database); !ok || got.ID != db.ID {
+		t.Fatalf("expected database, got %+v", res.Results[0])
+	}
+	if got, ok := res.Results[1].(*notion.Page); !ok || got.ID != task.ID {
+		t.Fatalf("expected page, got %+v", res.Results[1])
+	}
+
+	res, err = client.Search(ctx, &notion.SearchOpts{Filter: &notion.SearchFilter{Property: "object", Value: "page"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Results) != 2 || res.Results[0].(*notion.Page).ID != root.ID {
+		t.Fatalf("expected root and task pages, got %+v", res.Results)
+	}
+
+	ann := srv.AddUser(notion.User{Name: "Ann", Person: &notion.Person{Email: "ann@example.com"}})
+	srv.AddUser(notion.User{Name: "Bot", Type: "bot", Bot: &notion.Bot{}})
+	users, err := client.ListUsersIterator(&notion.PaginationQuery{PageSize: 1}).All(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "Ann" || users[1].Type != "bot" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+	user, err := client.GetUser(ctx, ann.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Person == nil || user.Person.Email != "ann@example.com" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	srv, root := newServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+
+	db := srv.AddDatabase(root.ID, notion.Database{
+		Properties: notion.DatabaseProperties{"Name": {Type: notion.DBPropTypeTitle}},
+	})
+
+	_, err := client.GetPage(ctx, "5e2b7ebd-cd3c-4e3f-9cbb-0c1ef5ad9bb1")
+	if !errors.Is(err, notion.ErrObjectNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	_, err = client.CreatePage(ctx, notion.CreatePageParams{
+		ParentType: notion.ParentTypeDatabase,
+		ParentID:   db.ID,
+		DatabasePageProperties: &notion.DatabasePageProperties{
+			"Nope": {Type: notion.DBPropTypeRichText, RichText: text("a")},
+		},
+	})
+	exp := "notion: failed to create page: Nope is not a property that exists. (code: validation_error, status: 400)"
+	if err == nil || err.Error() != exp {
+		t.Errorf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+
+	// nothing is created if a child block is invalid
+	_, err = client.CreatePage(ctx, notion.CreatePageParams{
+		ParentType: notion.ParentTypeDatabase,
+		ParentID:   db.ID,
+		DatabasePageProperties: &notion.DatabasePageProperties{
+			"Name": {Type: notion.DBPropTypeTitle, Title: text("a")},
+		},
+		Children: []notion.Block{
+			{Type: notion.BlockTypeParagraph, Paragraph: &notion.RichTextBlock{Text: text("ok")}},
+			{Type: notion.BlockTypeParagraph, Paragraph: &notion.RichTextBlock{
+				Text:     text("parent"),
+				Children: []notion.Block{{}},
+			}},
+		},
+	})
+	exp = "notion: failed to create page: body.children[1].paragraph.children[0].type should be defined. (code: validation_error, status: 400)"
+	if err == nil || err.Error() != exp {
+		t.Errorf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+	res, err := client.QueryDatabase(ctx, db.ID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Results) != 0 {
+		t.Errorf("expected no pages, got %v", pageIDs(res.Results))
+	}
+
+	_, err = client.UpdateDatabase(ctx, db.ID, notion.UpdateDatabaseParams{
+		Properties: map[string]*notion.DatabaseProperty{"Name": nil},
+	})
+	if !errors.Is(err, notion.ErrValidation) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	unauthorized := notion.NewClient("", srv.ClientOptions())
+	_, err = unauthorized.GetDatabase(ctx, db.ID)
+	if !errors.Is(err, notion.ErrUnauthorized) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
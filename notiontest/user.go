@@ -0,0 +1,24 @@
+package notiontest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (s *Server) getUser(r *http.Request, ids []string) (interface{}, error) {
+	user, ok := s.users[key(ids[0])]
+	if !ok {
+		return nil, notFound(ids[0])
+	}
+	return withObject("user", user)
+}
+
+func (s *Server) listUsers(r *http.Request, ids []string) (interface{}, error) {
+	cursor, pageSize, err := paginationQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(s.userIDs, cursor, pageSize, func(i int) (json.RawMessage, error) {
+		return withObject("user", s.users[key(s.userIDs[i])])
+	})
+}
@@ -30,6 +30,8 @@ type PageParent struct {
 
 	PageID     *string `json:"page_id,omitempty"`
 	DatabaseID *string `json:"database_id,omitempty"`
+	// BlockID is set for pages in a block, since Notion-Version 2022-06-28.
+	BlockID *string `json:"block_id,omitempty"`
 }
 
 // PageProperties are properties of a page whose parent is a page or a workspace.
@@ -59,11 +61,32 @@ type FormulaProperty struct {
 	Type FormulaType `json:"type"`
 	// one of those depending on Type
 	String  string     `json:"string"`
-	Number  float64    `json:"number"`
+	Number  *float64   `json:"number"`
 	Boolean bool       `json:"boolean"`
 	Date    *time.Time `json:"date,omitempty"`
 }
 
+// https://developers.notion.com/reference/page#rollup-property-values
+type RollupType string
+
+const (
+	RollupTypeNumber RollupType = "number"
+	RollupTypeDate   RollupType = "date"
+	RollupTypeArray  RollupType = "array"
+)
+
+// RollupProperty is the value of a rollup property of a page.
+//
+// DatabasePageProperty.Rollup used to be a *RollupMetadata, which describes
+// a rollup property of a database and never had the value.
+type RollupProperty struct {
+	Type RollupType `json:"type"`
+	// one of those depending on Type
+	Number *float64               `json:"number,omitempty"`
+	Date   *Date                  `json:"date,omitempty"`
+	Array  []DatabasePageProperty `json:"array,omitempty"`
+}
+
 // DatabasePageProperties are properties of a page whose parent is a database.
 type DatabasePageProperties map[string]DatabasePageProperty
 
@@ -73,18 +96,82 @@ type DatabasePageProperty struct {
 
 	Title       []RichText         `json:"title,omitempty"`
 	RichText    []RichText         `json:"rich_text,omitempty"`
-	Number      float64            `json:"number,omitempty"`
+	Number      *float64           `json:"number,omitempty"`
 	Select      *SelectOptions     `json:"select,omitempty"`
 	MultiSelect []SelectOptions    `json:"multi_select,omitempty"`
 	Date        *Date              `json:"date,omitempty"`
 	Formula     *FormulaProperty   `json:"formula,omitempty"`
 	Relation    []RelationProperty `json:"relation,omitempty"`
-	Rollup      *RollupMetadata    `json:"rollup,omitempty"`
+	Rollup      *RollupProperty    `json:"rollup,omitempty"`
+
+	Checkbox       *bool        `json:"checkbox,omitempty"`
+	URL            *string      `json:"url,omitempty"`
+	Email          *string      `json:"email,omitempty"`
+	PhoneNumber    *string      `json:"phone_number,omitempty"`
+	People         []User       `json:"people,omitempty"`
+	Files          []FileObject `json:"files,omitempty"`
+	CreatedTime    *time.Time   `json:"created_time,omitempty"`
+	CreatedBy      *User        `json:"created_by,omitempty"`
+	LastEditedTime *time.Time   `json:"last_edited_time,omitempty"`
+	LastEditedBy   *User        `json:"last_edited_by,omitempty"`
 
 	// RawJSON is for debugging, shows JSON response from the server
 	RawJSON []byte `json:"-"`
 }
 
+// MarshalJSON implements json.Marshaler. Number properties with nil Number
+// are encoded as "number": null and multi_select, relation, people and files
+// properties without values as an empty array, which clears the property
+// when updating a page.
+func (prop DatabasePageProperty) MarshalJSON() ([]byte, error) {
+	type dbPageProp DatabasePageProperty
+	p := dbPageProp(prop)
+	switch {
+	case prop.Type == DBPropTypeNumber && prop.Number == nil:
+		return json.Marshal(struct {
+			dbPageProp
+			Number *float64 `json:"number"`
+		}{dbPageProp: p})
+	case prop.Type == DBPropTypeMultiSelect && len(prop.MultiSelect) == 0:
+		return json.Marshal(struct {
+			dbPageProp
+			MultiSelect []SelectOptions `json:"multi_select"`
+		}{p, []SelectOptions{}})
+	case prop.Type == DBPropTypeRelation && len(prop.Relation) == 0:
+		return json.Marshal(struct {
+			dbPageProp
+			Relation []RelationProperty `json:"relation"`
+		}{p, []RelationProperty{}})
+	case prop.Type == DBPropTypePeople && len(prop.People) == 0:
+		return json.Marshal(struct {
+			dbPageProp
+			People []User `json:"people"`
+		}{p, []User{}})
+	case prop.Type == DBPropTypeFiles && len(prop.Files) == 0:
+		return json.Marshal(struct {
+			dbPageProp
+			Files []FileObject `json:"files"`
+		}{p, []FileObject{}})
+	}
+	return json.Marshal(p)
+}
+
+// Title returns the title of the page. For pages in a database, it's the
+// value of the title property.
+func (p *Page) Title() []RichText {
+	switch props := p.Properties.(type) {
+	case PageProperties:
+		return props.Title.Title
+	case DatabasePageProperties:
+		for _, prop := range props {
+			if prop.Type == DBPropTypeTitle {
+				return prop.Title
+			}
+		}
+	}
+	return nil
+}
+
 // CreatePageParams are the params used for creating a page.
 type CreatePageParams struct {
 	ParentType ParentType
@@ -109,6 +196,7 @@ type ParentType string
 const (
 	ParentTypeDatabase ParentType = "database_id"
 	ParentTypePage     ParentType = "page_id"
+	ParentTypeBlock    ParentType = "block_id"
 )
 
 func (p CreatePageParams) Validate() error {
@@ -162,8 +250,8 @@ func (p CreatePageParams) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON implements json.Unmarshaler.
 //
 // Pages get a different Properties type based on the parent of the page.
-// If parent type is `workspace` or `page_id`, PageProperties is used. Else if
-// parent type is `database_id`, DatabasePageProperties is used.
+// If parent type is `workspace`, `page_id` or `block_id`, PageProperties is
+// used. Else if parent type is `database_id`, DatabasePageProperties is used.
 func (p *Page) UnmarshalJSON(b []byte) error {
 	type (
 		PageAlias Page
@@ -183,9 +271,7 @@ func (p *Page) UnmarshalJSON(b []byte) error {
 	page := dto.PageAlias
 
 	switch dto.Parent.Type {
-	case "workspace":
-		fallthrough
-	case "page_id":
+	case "workspace", "page_id", "block_id":
 		var props PageProperties
 		err := json.Unmarshal(dto.Properties, &props)
 		if err != nil {
@@ -232,3 +318,153 @@ func (p UpdatePageParams) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(dto)
 }
+
+// Getters of property values by property name. The second return value
+// reports whether the property exists and is of the expected type. Empty
+// values (e.g. a URL that is not set) are returned as zero values.
+
+// property returns property with a given name if it is of type typ.
+func (props DatabasePageProperties) property(name string, typ DatabasePropertyType) (DatabasePageProperty, bool) {
+	prop, ok := props[name]
+	if !ok || prop.Type != typ {
+		return DatabasePageProperty{}, false
+	}
+	return prop, true
+}
+
+// Title returns the value of a title property.
+func (props DatabasePageProperties) Title(name string) ([]RichText, bool) {
+	prop, ok := props.property(name, DBPropTypeTitle)
+	return prop.Title, ok
+}
+
+// RichText returns the value of a rich_text property.
+func (props DatabasePageProperties) RichText(name string) ([]RichText, bool) {
+	prop, ok := props.property(name, DBPropTypeRichText)
+	return prop.RichText, ok
+}
+
+// Number returns the value of a number property. Empty numbers are returned
+// as 0, use the Number field of the property to tell them apart.
+func (props DatabasePageProperties) Number(name string) (float64, bool) {
+	prop, ok := props.property(name, DBPropTypeNumber)
+	return floatValue(prop.Number), ok
+}
+
+func floatValue(n *float64) float64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// Select returns the selected option of a select property or nil if
+// no option is selected.
+func (props DatabasePageProperties) Select(name string) (*SelectOptions, bool) {
+	prop, ok := props.property(name, DBPropTypeSelect)
+	return prop.Select, ok
+}
+
+// MultiSelect returns the selected options of a multi_select property.
+func (props DatabasePageProperties) MultiSelect(name string) ([]SelectOptions, bool) {
+	prop, ok := props.property(name, DBPropTypeMultiSelect)
+	return prop.MultiSelect, ok
+}
+
+// Date returns the value of a date property or nil if the date is not set.
+func (props DatabasePageProperties) Date(name string) (*Date, bool) {
+	prop, ok := props.property(name, DBPropTypeDate)
+	return prop.Date, ok
+}
+
+// Formula returns the result of a formula property.
+func (props DatabasePageProperties) Formula(name string) (*FormulaProperty, bool) {
+	prop, ok := props.property(name, DBPropTypeFormula)
+	return prop.Formula, ok
+}
+
+// Relation returns the related pages of a relation property.
+func (props DatabasePageProperties) Relation(name string) ([]RelationProperty, bool) {
+	prop, ok := props.property(name, DBPropTypeRelation)
+	return prop.Relation, ok
+}
+
+// Rollup returns the value of a rollup property.
+func (props DatabasePageProperties) Rollup(name string) (*RollupProperty, bool) {
+	prop, ok := props.property(name, DBPropTypeRollup)
+	return prop.Rollup, ok
+}
+
+// Checkbox returns the value of a checkbox property.
+func (props DatabasePageProperties) Checkbox(name string) (bool, bool) {
+	prop, ok := props.property(name, DBPropTypeCheckbox)
+	return prop.Checkbox != nil && *prop.Checkbox, ok
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// URL returns the value of a url property.
+func (props DatabasePageProperties) URL(name string) (string, bool) {
+	prop, ok := props.property(name, DBPropTypeURL)
+	return stringValue(prop.URL), ok
+}
+
+// Email returns the value of an email property.
+func (props DatabasePageProperties) Email(name string) (string, bool) {
+	prop, ok := props.property(name, DBPropTypeEmail)
+	return stringValue(prop.Email), ok
+}
+
+// PhoneNumber returns the value of a phone_number property.
+func (props DatabasePageProperties) PhoneNumber(name string) (string, bool) {
+	prop, ok := props.property(name, DBPropTypePhoneNumber)
+	return stringValue(prop.PhoneNumber), ok
+}
+
+// People returns the users of a people property.
+func (props DatabasePageProperties) People(name string) ([]User, bool) {
+	prop, ok := props.property(name, DBPropTypePeople)
+	return prop.People, ok
+}
+
+// Files returns the files of a files property.
+func (props DatabasePageProperties) Files(name string) ([]FileObject, bool) {
+	prop, ok := props.property(name, DBPropTypeFiles)
+	return prop.Files, ok
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// CreatedTime returns the value of a created_time property.
+func (props DatabasePageProperties) CreatedTime(name string) (time.Time, bool) {
+	prop, ok := props.property(name, DBPropTypeCreatedTime)
+	return timeValue(prop.CreatedTime), ok
+}
+
+// CreatedBy returns the value of a created_by property.
+func (props DatabasePageProperties) CreatedBy(name string) (*User, bool) {
+	prop, ok := props.property(name, DBPropTypeCreatedBy)
+	return prop.CreatedBy, ok
+}
+
+// LastEditedTime returns the value of a last_edited_time property.
+func (props DatabasePageProperties) LastEditedTime(name string) (time.Time, bool) {
+	prop, ok := props.property(name, DBPropTypeLastEditedTime)
+	return timeValue(prop.LastEditedTime), ok
+}
+
+// LastEditedBy returns the value of a last_edited_by property.
+func (props DatabasePageProperties) LastEditedBy(name string) (*User, bool) {
+	prop, ok := props.property(name, DBPropTypeLastEditedBy)
+	return prop.LastEditedBy, ok
+}
@@ -0,0 +1,295 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+func TestDatabasePagePropertiesGetters(t *testing.T) {
+	t.Parallel()
+
+	data := `{
+		"object": "page",
+		"id": "606ed832-7d79-46de-bbed-5b4896e7bc02",
+		"created_time": "2021-05-19T18:34:00.000Z",
+		"last_edited_time": "2021-05-19T18:34:00.000Z",
+		"parent": {
+			"type": "database_id",
+			"database_id": "39ddf6b8-e8a0-4d2a-98d4-de7f2f0e5b38"
+		},
+		"archived": false,
+		"properties": {
+			"Done": {"id": "a", "type": "checkbox", "checkbox": true},
+			"Website": {"id": "b", "type": "url", "url": "https://example.com"},
+			"Email": {"id": "c", "type": "email", "email": "john@example.com"},
+			"Phone": {"id": "d", "type": "phone_number", "phone_number": null},
+			"Owners": {
+				"id": "e",
+				"type": "people",
+				"people": [
+					{
+						"object": "user",
+						"id": "be32e790-8292-46df-a248-b784fdf483cf",
+						"name": "Jane",
+						"avatar_url": null,
+						"type": "person",
+						"person": {"email": "jane@example.com"}
+					}
+				]
+			},
+			"Attachments": {
+				"id": "f",
+				"type": "files",
+				"files": [
+					{
+						"name": "spec.pdf",
+						"type": "external",
+						"external": {"url": "https://example.com/spec.pdf"}
+					}
+				]
+			},
+			"Created": {"id": "g", "type": "created_time", "created_time": "2021-05-19T18:34:00.000Z"},
+			"Creator": {
+				"id": "h",
+				"type": "created_by",
+				"created_by": {"object": "user", "id": "be32e790-8292-46df-a248-b784fdf483cf"}
+			},
+			"Edited": {"id": "i", "type": "last_edited_time", "last_edited_time": "2021-05-20T10:00:00.000Z"},
+			"Editor": {
+				"id": "j",
+				"type": "last_edited_by",
+				"last_edited_by": {"object": "user", "id": "be32e790-8292-46df-a248-b784fdf483cf"}
+			},
+			"Total": {"id": "k", "type": "rollup", "rollup": {"type": "number", "number": 42}},
+			"Due": {"id": "l", "type": "rollup", "rollup": {"type": "date", "date": {"start": "2021-05-20"}}},
+			"Names": {
+				"id": "m",
+				"type": "rollup",
+				"rollup": {
+					"type": "array",
+					"array": [
+						{"type": "title", "title": [{"type": "text", "text": {"content": "Foo"}, "plain_text": "Foo"}]},
+						{"type": "title", "title": [{"type": "text", "text": {"content": "Bar"}, "plain_text": "Bar"}]}
+					]
+				}
+			}
+		}
+	}`
+
+	var page notion.Page
+	if err := json.Unmarshal([]byte(data), &page); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	props, ok := page.Properties.(notion.DatabasePageProperties)
+	if !ok {
+		t.Fatalf("expected DatabasePageProperties, got %T", page.Properties)
+	}
+
+	if v, ok := props.Checkbox("Done"); !ok || !v {
+		t.Errorf("Checkbox: got (%v, %v)", v, ok)
+	}
+	if v, ok := props.URL("Website"); !ok || v != "https://example.com" {
+		t.Errorf("URL: got (%q, %v)", v, ok)
+	}
+	if v, ok := props.Email("Email"); !ok || v != "john@example.com" {
+		t.Errorf("Email: got (%q, %v)", v, ok)
+	}
+	if v, ok := props.PhoneNumber("Phone"); !ok || v != "" {
+		t.Errorf("PhoneNumber: got (%q, %v)", v, ok)
+	}
+
+	people, ok := props.People("Owners")
+	expPeople := []notion.User{
+		{
+			ID:     "be32e790-8292-46df-a248-b784fdf483cf",
+			Type:   "person",
+			Name:   "Jane",
+			Person: &notion.Person{Email: "jane@example.com"},
+		},
+	}
+	if diff := cmp.Diff(expPeople, people); !ok || diff != "" {
+		t.Errorf("People not equal (-exp, +got):\n%v", diff)
+	}
+
+	files, ok := props.Files("Attachments")
+	expFiles := []notion.FileObject{
+		{
+			Name:     "spec.pdf",
+			Type:     notion.FileTypeExternal,
+			External: &notion.ExternalFile{URL: "https://example.com/spec.pdf"},
+		},
+	}
+	if diff := cmp.Diff(expFiles, files); !ok || diff != "" {
+		t.Errorf("Files not equal (-exp, +got):\n%v", diff)
+	}
+
+	if v, ok := props.CreatedTime("Created"); !ok || !v.Equal(mustParseTime(time.RFC3339Nano, "2021-05-19T18:34:00.000Z")) {
+		t.Errorf("CreatedTime: got (%v, %v)", v, ok)
+	}
+	if v, ok := props.LastEditedTime("Edited"); !ok || !v.Equal(mustParseTime(time.RFC3339Nano, "2021-05-20T10:00:00.000Z")) {
+		t.Errorf("LastEditedTime: got (%v, %v)", v, ok)
+	}
+	if v, ok := props.CreatedBy("Creator"); !ok || v == nil || v.ID != "be32e790-8292-46df-a248-b784fdf483cf" {
+		t.Errorf("CreatedBy: got (%v, %v)", v, ok)
+	}
+	if v, ok := props.LastEditedBy("Editor"); !ok || v == nil || v.ID != "be32e790-8292-46df-a248-b784fdf483cf" {
+		t.Errorf("LastEditedBy: got (%v, %v)", v, ok)
+	}
+
+	if v, ok := props.Rollup("Total"); !ok || v == nil || v.Type != notion.RollupTypeNumber || v.Number == nil || *v.Number != 42 {
+		t.Errorf("Rollup number: got (%v, %v)", v, ok)
+	}
+	if v, ok := props.Rollup("Due"); !ok || v == nil || v.Type != notion.RollupTypeDate || v.Date == nil ||
+		!v.Date.Start.Equal(notion.Time(mustParseTime("2006-01-02", "2021-05-20"))) {
+		t.Errorf("Rollup date: got (%v, %v)", v, ok)
+	}
+	names, ok := props.Rollup("Names")
+	if !ok || names == nil || names.Type != notion.RollupTypeArray || len(names.Array) != 2 {
+		t.Fatalf("Rollup array: got (%v, %v)", names, ok)
+	}
+	if got := notion.PlainText(names.Array[1].Title); got != "Bar" {
+		t.Errorf("Rollup array: expected Bar, got %q", got)
+	}
+
+	// wrong type or missing property
+	if _, ok := props.Checkbox("Website"); ok {
+		t.Errorf("Checkbox of a url property: expected not ok")
+	}
+	if _, ok := props.URL("Missing"); ok {
+		t.Errorf("URL of a missing property: expected not ok")
+	}
+}
+
+func TestDatabasePagePropertyNumber(t *testing.T) {
+	t.Parallel()
+
+	var props notion.DatabasePageProperties
+	data := `{
+		"Price": {"id": "a", "type": "number", "number": 19.99},
+		"Discount": {"id": "b", "type": "number", "number": 0},
+		"Stock": {"id": "c", "type": "number", "number": null}
+	}`
+	if err := json.Unmarshal([]byte(data), &props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := props["Price"].Number; n == nil || *n != 19.99 {
+		t.Errorf("Price: got %v", n)
+	}
+	if n := props["Discount"].Number; n == nil || *n != 0 {
+		t.Errorf("Discount: expected 0, got %v", n)
+	}
+	if n := props["Stock"].Number; n != nil {
+		t.Errorf("Stock: expected nil, got %v", *n)
+	}
+	if v, ok := props.Number("Stock"); !ok || v != 0 {
+		t.Errorf("Number: got (%v, %v)", v, ok)
+	}
+
+	tests := []struct {
+		name    string
+		prop    notion.DatabasePageProperty
+		expJSON string
+	}{
+		{
+			name:    "zero",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeNumber, Number: floatPtr(0)},
+			expJSON: `{"type":"number","number":0}`,
+		},
+		{
+			name:    "clear",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeNumber},
+			expJSON: `{"type":"number","number":null}`,
+		},
+		{
+			name:    "other type",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeCheckbox, Checkbox: new(bool)},
+			expJSON: `{"type":"checkbox","checkbox":false}`,
+		},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.prop)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if string(b) != tt.expJSON {
+			t.Errorf("%s: JSON not equal (expected: %s, got: %s)", tt.name, tt.expJSON, b)
+		}
+	}
+}
+
+func TestPageBlockParent(t *testing.T) {
+	t.Parallel()
+
+	data := `{
+		"object": "page",
+		"id": "606ed832-7d79-46de-bbed-5b4896e7bc02",
+		"parent": {"type": "block_id", "block_id": "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113"},
+		"properties": {
+			"title": {"id": "title", "type": "title", "title": [{"type": "text", "plain_text": "Notes", "text": {"content": "Notes"}}]}
+		}
+	}`
+	var page notion.Page
+	if err := json.Unmarshal([]byte(data), &page); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Parent.BlockID == nil || *page.Parent.BlockID != "ae9c9a31-1c1e-4ae2-a5ee-c539a2d43113" {
+		t.Errorf("unexpected parent: %+v", page.Parent)
+	}
+	if title := notion.PlainText(page.Title()); title != "Notes" {
+		t.Errorf("title not equal (expected: Notes, got: %s)", title)
+	}
+}
+
+func TestDatabasePagePropertyMarshalEmpty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		prop    notion.DatabasePageProperty
+		expJSON string
+	}{
+		{
+			name:    "clear multi_select",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeMultiSelect},
+			expJSON: `{"type":"multi_select","multi_select":[]}`,
+		},
+		{
+			name:    "clear relation",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeRelation, Relation: []notion.RelationProperty{}},
+			expJSON: `{"type":"relation","relation":[]}`,
+		},
+		{
+			name:    "clear people",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypePeople},
+			expJSON: `{"type":"people","people":[]}`,
+		},
+		{
+			name:    "clear files",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeFiles},
+			expJSON: `{"type":"files","files":[]}`,
+		},
+		{
+			name:    "multi_select",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeMultiSelect, MultiSelect: []notion.SelectOptions{{Name: "a"}}},
+			expJSON: `{"type":"multi_select","multi_select":[{"name":"a"}]}`,
+		},
+		{
+			name:    "other type",
+			prop:    notion.DatabasePageProperty{Type: notion.DBPropTypeCheckbox, Checkbox: new(bool)},
+			expJSON: `{"type":"checkbox","checkbox":false}`,
+		},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.prop)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if string(b) != tt.expJSON {
+			t.Errorf("%s: JSON not equal (expected: %s, got: %s)", tt.name, tt.expJSON, b)
+		}
+	}
+}
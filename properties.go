@@ -0,0 +1,665 @@
+package notion
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// UnmarshalPage stores properties of a database page in the struct pointed
+// to by v. Struct fields are mapped to properties with a "notion" tag:
+//
+//	type Task struct {
+//		Name     string    `notion:"Name,title"`
+//		Status   string    `notion:"Status,select"`
+//		Tags     []string  `notion:"Tags,multi_select"`
+//		Estimate float64   `notion:"Estimate,number"`
+//		Due      time.Time `notion:"Due,date"`
+//		Blockers []string  `notion:"Blocked by,relation"`
+//		Ignored  string    `notion:"-"`
+//	}
+//
+// Property name defaults to the field name. Property type is optional, if
+// it's set it must match the type of the property.
+//
+// Text properties can be decoded into string (plain text) or []RichText,
+// numbers into any int, uint or float type, select into string (option
+// name), multi_select into []string, dates into time.Time or Time (start of
+// the date range) or Date, relations into []string (page IDs), people into
+// []string (user IDs) or []User, files into []string (URLs) or []FileObject
+// and users into string (user ID) or User. Formulas can be decoded into
+// FormulaProperty or a Go type matching the formula result and rollups into
+// RollupProperty or a Go type matching a number or date rollup result.
+// Pointer fields are set to nil for empty values.
+// Properties missing in the page are left untouched.
+func UnmarshalPage(page *Page, v interface{}) error {
+	props, ok := page.Properties.(DatabasePageProperties)
+	if !ok {
+		return errors.New("notion: can't unmarshal page, parent is not a database")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("notion: can't unmarshal page into %T, expected a non-nil pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	for _, f := range structFields(rv.Type()) {
+		prop, ok := props[f.name]
+		if !ok {
+			continue
+		}
+		if f.typ != "" && f.typ != prop.Type {
+			return fmt.Errorf("notion: property %q is of type %s, not %s", f.name, prop.Type, f.typ)
+		}
+		fv := rv.FieldByIndex(f.index)
+		err := decodeProperty(prop, fv)
+		if err == errTypeMismatch {
+			return &PropertyTypeError{Property: f.name, Type: prop.Type, Field: goFieldName(rv.Type(), f), GoType: fv.Type()}
+		}
+		if err != nil {
+			return fmt.Errorf("notion: can't unmarshal property %q into Go field %s: %w", f.name, goFieldName(rv.Type(), f), err)
+		}
+	}
+	return nil
+}
+
+// MarshalProperties returns properties of a database page, for creating or
+// updating a page, from a struct tagged like for UnmarshalPage.
+//
+// If property type is not set in the tag, it's inferred from the Go type:
+// string is rich_text, numbers are number, bool is checkbox, time.Time,
+// Time and Date are date, []string is multi_select.
+//
+// Read-only properties (formula, rollup, created_time, created_by,
+// last_edited_time, last_edited_by), nil pointers and empty values that
+// Notion doesn't accept (empty select, URL, email, phone number and zero
+// time) are skipped.
+func MarshalProperties(v interface{}) (DatabasePageProperties, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("notion: can't marshal %T as properties, expected a struct", v)
+	}
+
+	props := DatabasePageProperties{}
+	for _, f := range structFields(rv.Type()) {
+		fv := rv.FieldByIndex(f.index)
+		typ := f.typ
+		if typ == "" {
+			typ = inferPropertyType(fv.Type())
+			if typ == "" {
+				return nil, fmt.Errorf("notion: can't infer property type of Go field %s of type %s, set it in the tag", goFieldName(rv.Type(), f), fv.Type())
+			}
+		}
+		if isReadOnlyProperty(typ) {
+			continue
+		}
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		prop, ok, err := encodeProperty(typ, fv)
+		if err == errTypeMismatch {
+			return nil, &PropertyTypeError{Property: f.name, Type: typ, Field: goFieldName(rv.Type(), f), GoType: fv.Type()}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("notion: can't marshal Go field %s as property %q: %w", goFieldName(rv.Type(), f), f.name, err)
+		}
+		if ok {
+			props[f.name] = prop
+		}
+	}
+	return props, nil
+}
+
+// PropertyTypeError describes a Go struct field whose type can't hold the
+// value of a database property.
+type PropertyTypeError struct {
+	Property string
+	Type     DatabasePropertyType
+	Field    string // Go struct field, e.g. "Task.Status"
+	GoType   reflect.Type
+}
+
+func (e *PropertyTypeError) Error() string {
+	return fmt.Sprintf("notion: %s property %q doesn't match Go field %s of type %s", e.Type, e.Property, e.Field, e.GoType)
+}
+
+var errTypeMismatch = errors.New("type mismatch")
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	notionTimeType = reflect.TypeOf(Time{})
+	dateType       = reflect.TypeOf(Date{})
+	richTextsType  = reflect.TypeOf([]RichText(nil))
+	stringsType    = reflect.TypeOf([]string(nil))
+	selectType     = reflect.TypeOf(SelectOptions{})
+	selectsType    = reflect.TypeOf([]SelectOptions(nil))
+	relationsType  = reflect.TypeOf([]RelationProperty(nil))
+	userType       = reflect.TypeOf(User{})
+	usersType      = reflect.TypeOf([]User(nil))
+	filesType      = reflect.TypeOf([]FileObject(nil))
+	formulaType    = reflect.TypeOf(FormulaProperty{})
+	rollupType     = reflect.TypeOf(RollupProperty{})
+)
+
+type structField struct {
+	index     []int
+	fieldName string
+	name      string
+	typ       DatabasePropertyType
+}
+
+// goFieldName returns name of the field for error messages e.g. "Task.Status".
+func goFieldName(t reflect.Type, f structField) string {
+	if t.Name() == "" {
+		return f.fieldName
+	}
+	return t.Name() + "." + f.fieldName
+}
+
+// structFields returns fields of a struct mapped to properties, including
+// fields of embedded structs.
+func structFields(t reflect.Type) []structField {
+	var res []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("notion")
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && !hasTag && sf.Type.Kind() == reflect.Struct {
+			for _, f := range structFields(sf.Type) {
+				f.index = append([]int{i}, f.index...)
+				res = append(res, f)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name, typ := tag, ""
+		// property names can contain commas, types can't
+		if i := strings.LastIndex(tag, ","); i >= 0 {
+			name, typ = tag[:i], tag[i+1:]
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		res = append(res, structField{
+			index:     []int{i},
+			fieldName: sf.Name,
+			name:      name,
+			typ:       DatabasePropertyType(typ),
+		})
+	}
+	return res
+}
+
+func inferPropertyType(t reflect.Type) DatabasePropertyType {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType, notionTimeType, dateType:
+		return DBPropTypeDate
+	case richTextsType:
+		return DBPropTypeRichText
+	case stringsType, selectsType:
+		return DBPropTypeMultiSelect
+	case selectType:
+		return DBPropTypeSelect
+	case relationsType:
+		return DBPropTypeRelation
+	case usersType:
+		return DBPropTypePeople
+	case filesType:
+		return DBPropTypeFiles
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return DBPropTypeRichText
+	case reflect.Bool:
+		return DBPropTypeCheckbox
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return DBPropTypeNumber
+	}
+	return ""
+}
+
+func isReadOnlyProperty(typ DatabasePropertyType) bool {
+	switch typ {
+	case DBPropTypeFormula, DBPropTypeRollup,
+		DBPropTypeCreatedTime, DBPropTypeCreatedBy,
+		DBPropTypeLastEditedTime, DBPropTypeLastEditedBy:
+		return true
+	}
+	return false
+}
+
+// isEmptyProperty returns true if property has no value e.g. a select
+// without a selected option.
+func isEmptyProperty(prop DatabasePageProperty) bool {
+	switch prop.Type {
+	case DBPropTypeNumber:
+		return prop.Number == nil
+	case DBPropTypeSelect:
+		return prop.Select == nil
+	case DBPropTypeDate:
+		return prop.Date == nil
+	case DBPropTypeFormula:
+		return prop.Formula == nil
+	case DBPropTypeCheckbox:
+		return prop.Checkbox == nil
+	case DBPropTypeURL:
+		return prop.URL == nil
+	case DBPropTypeEmail:
+		return prop.Email == nil
+	case DBPropTypePhoneNumber:
+		return prop.PhoneNumber == nil
+	case DBPropTypeCreatedTime:
+		return prop.CreatedTime == nil
+	case DBPropTypeCreatedBy:
+		return prop.CreatedBy == nil
+	case DBPropTypeLastEditedTime:
+		return prop.LastEditedTime == nil
+	case DBPropTypeLastEditedBy:
+		return prop.LastEditedBy == nil
+	}
+	return false
+}
+
+func decodeProperty(prop DatabasePageProperty, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if isEmptyProperty(prop) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeProperty(prop, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch prop.Type {
+	case DBPropTypeTitle:
+		return decodeRichText(prop.Title, v)
+	case DBPropTypeRichText:
+		return decodeRichText(prop.RichText, v)
+	case DBPropTypeNumber:
+		return decodeNumber(floatValue(prop.Number), v)
+	case DBPropTypeSelect:
+		if v.Type() == selectType {
+			if prop.Select != nil {
+				v.Set(reflect.ValueOf(*prop.Select))
+			}
+			return nil
+		}
+		if v.Kind() == reflect.String {
+			name := ""
+			if prop.Select != nil {
+				name = prop.Select.Name
+			}
+			v.SetString(name)
+			return nil
+		}
+	case DBPropTypeMultiSelect:
+		if v.Type() == selectsType {
+			v.Set(reflect.ValueOf(prop.MultiSelect))
+			return nil
+		}
+		names := make([]string, len(prop.MultiSelect))
+		for i, o := range prop.MultiSelect {
+			names[i] = o.Name
+		}
+		return decodeStrings(names, v)
+	case DBPropTypeDate:
+		if v.Type() == dateType {
+			if prop.Date != nil {
+				v.Set(reflect.ValueOf(*prop.Date))
+			}
+			return nil
+		}
+		var t time.Time
+		if prop.Date != nil {
+			t = time.Time(prop.Date.Start)
+		}
+		return decodeTime(t, v)
+	case DBPropTypeRelation:
+		if v.Type() == relationsType {
+			v.Set(reflect.ValueOf(prop.Relation))
+			return nil
+		}
+		ids := make([]string, len(prop.Relation))
+		for i, r := range prop.Relation {
+			ids[i] = r.ID
+		}
+		return decodeStrings(ids, v)
+	case DBPropTypeCheckbox:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(prop.Checkbox != nil && *prop.Checkbox)
+			return nil
+		}
+	case DBPropTypeURL:
+		return decodeString(stringValue(prop.URL), v)
+	case DBPropTypeEmail:
+		return decodeString(stringValue(prop.Email), v)
+	case DBPropTypePhoneNumber:
+		return decodeString(stringValue(prop.PhoneNumber), v)
+	case DBPropTypePeople:
+		if v.Type() == usersType {
+			v.Set(reflect.ValueOf(prop.People))
+			return nil
+		}
+		ids := make([]string, len(prop.People))
+		for i, u := range prop.People {
+			ids[i] = u.ID
+		}
+		return decodeStrings(ids, v)
+	case DBPropTypeFiles:
+		if v.Type() == filesType {
+			v.Set(reflect.ValueOf(prop.Files))
+			return nil
+		}
+		urls := make([]string, len(prop.Files))
+		for i := range prop.Files {
+			urls[i] = prop.Files[i].URL()
+		}
+		return decodeStrings(urls, v)
+	case DBPropTypeCreatedTime:
+		return decodeTime(timeValue(prop.CreatedTime), v)
+	case DBPropTypeLastEditedTime:
+		return decodeTime(timeValue(prop.LastEditedTime), v)
+	case DBPropTypeCreatedBy:
+		return decodeUser(prop.CreatedBy, v)
+	case DBPropTypeLastEditedBy:
+		return decodeUser(prop.LastEditedBy, v)
+	case DBPropTypeRollup:
+		r := prop.Rollup
+		if v.Type() == rollupType {
+			if r != nil {
+				v.Set(reflect.ValueOf(*r))
+			}
+			return nil
+		}
+		if r == nil {
+			return nil
+		}
+		switch r.Type {
+		case RollupTypeNumber:
+			return decodeNumber(floatValue(r.Number), v)
+		case RollupTypeDate:
+			var start time.Time
+			if r.Date != nil {
+				start = time.Time(r.Date.Start)
+			}
+			return decodeTime(start, v)
+		}
+	case DBPropTypeFormula:
+		f := prop.Formula
+		if v.Type() == formulaType {
+			if f != nil {
+				v.Set(reflect.ValueOf(*f))
+			}
+			return nil
+		}
+		if f == nil {
+			return nil
+		}
+		switch f.Type {
+		case FormulaTypeString:
+			return decodeString(f.String, v)
+		case FormulaTypeNumber:
+			return decodeNumber(floatValue(f.Number), v)
+		case FormulaTypeBoolean:
+			if v.Kind() == reflect.Bool {
+				v.SetBool(f.Boolean)
+				return nil
+			}
+		case FormulaTypeDate:
+			return decodeTime(timeValue(f.Date), v)
+		}
+	}
+	return errTypeMismatch
+}
+
+func decodeRichText(rts []RichText, v reflect.Value) error {
+	if v.Type() == richTextsType {
+		v.Set(reflect.ValueOf(rts))
+		return nil
+	}
+	return decodeString(PlainText(rts), v)
+}
+
+func decodeString(s string, v reflect.Value) error {
+	if v.Kind() != reflect.String {
+		return errTypeMismatch
+	}
+	v.SetString(s)
+	return nil
+}
+
+func decodeStrings(s []string, v reflect.Value) error {
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.String {
+		return errTypeMismatch
+	}
+	res := reflect.MakeSlice(v.Type(), len(s), len(s))
+	for i := range s {
+		res.Index(i).SetString(s[i])
+	}
+	v.Set(res)
+	return nil
+}
+
+func decodeNumber(n float64, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n != math.Trunc(n) || v.OverflowInt(int64(n)) {
+			return fmt.Errorf("number %v doesn't fit in %s", n, v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n != math.Trunc(n) || n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("number %v doesn't fit in %s", n, v.Type())
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(n)
+	default:
+		return errTypeMismatch
+	}
+	return nil
+}
+
+func decodeTime(t time.Time, v reflect.Value) error {
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(t))
+	case notionTimeType:
+		v.Set(reflect.ValueOf(Time(t)))
+	default:
+		return errTypeMismatch
+	}
+	return nil
+}
+
+func decodeUser(u *User, v reflect.Value) error {
+	if v.Type() == userType {
+		if u != nil {
+			v.Set(reflect.ValueOf(*u))
+		}
+		return nil
+	}
+	id := ""
+	if u != nil {
+		id = u.ID
+	}
+	return decodeString(id, v)
+}
+
+// encodeProperty returns property of type typ with value v. It returns false
+// if the value is empty and should be skipped.
+func encodeProperty(typ DatabasePropertyType, v reflect.Value) (DatabasePageProperty, bool, error) {
+	prop := DatabasePageProperty{Type: typ}
+	switch typ {
+	case DBPropTypeTitle, DBPropTypeRichText:
+		var rts []RichText
+		switch {
+		case v.Type() == richTextsType:
+			rts = v.Interface().([]RichText)
+		case v.Kind() == reflect.String:
+			rts = []RichText{{Type: RichTextTypeText, Text: &Text{Content: v.String()}}}
+		default:
+			return prop, false, errTypeMismatch
+		}
+		if typ == DBPropTypeTitle {
+			prop.Title = rts
+		} else {
+			prop.RichText = rts
+		}
+	case DBPropTypeNumber:
+		var n float64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		default:
+			return prop, false, errTypeMismatch
+		}
+		prop.Number = &n
+	case DBPropTypeSelect:
+		switch {
+		case v.Type() == selectType:
+			o := v.Interface().(SelectOptions)
+			prop.Select = &o
+		case v.Kind() == reflect.String:
+			if v.String() == "" {
+				return prop, false, nil
+			}
+			prop.Select = &SelectOptions{Name: v.String()}
+		default:
+			return prop, false, errTypeMismatch
+		}
+	case DBPropTypeMultiSelect:
+		if v.Type() == selectsType {
+			prop.MultiSelect = v.Interface().([]SelectOptions)
+			break
+		}
+		names, err := encodeStrings(v)
+		if err != nil {
+			return prop, false, err
+		}
+		prop.MultiSelect = make([]SelectOptions, len(names))
+		for i, name := range names {
+			prop.MultiSelect[i] = SelectOptions{Name: name}
+		}
+	case DBPropTypeDate:
+		var t time.Time
+		switch v.Type() {
+		case dateType:
+			d := v.Interface().(Date)
+			prop.Date = &d
+			return prop, true, nil
+		case timeType:
+			t = v.Interface().(time.Time)
+		case notionTimeType:
+			t = time.Time(v.Interface().(Time))
+		default:
+			return prop, false, errTypeMismatch
+		}
+		if t.IsZero() {
+			return prop, false, nil
+		}
+		prop.Date = &Date{Start: Time(t)}
+	case DBPropTypeRelation:
+		if v.Type() == relationsType {
+			prop.Relation = v.Interface().([]RelationProperty)
+			break
+		}
+		ids, err := encodeStrings(v)
+		if err != nil {
+			return prop, false, err
+		}
+		prop.Relation = make([]RelationProperty, len(ids))
+		for i, id := range ids {
+			prop.Relation[i] = RelationProperty{ID: id}
+		}
+	case DBPropTypeCheckbox:
+		if v.Kind() != reflect.Bool {
+			return prop, false, errTypeMismatch
+		}
+		b := v.Bool()
+		prop.Checkbox = &b
+	case DBPropTypeURL, DBPropTypeEmail, DBPropTypePhoneNumber:
+		if v.Kind() != reflect.String {
+			return prop, false, errTypeMismatch
+		}
+		s := v.String()
+		if s == "" {
+			return prop, false, nil
+		}
+		switch typ {
+		case DBPropTypeURL:
+			prop.URL = &s
+		case DBPropTypeEmail:
+			prop.Email = &s
+		default:
+			prop.PhoneNumber = &s
+		}
+	case DBPropTypePeople:
+		if v.Type() == usersType {
+			prop.People = v.Interface().([]User)
+			break
+		}
+		ids, err := encodeStrings(v)
+		if err != nil {
+			return prop, false, err
+		}
+		prop.People = make([]User, len(ids))
+		for i, id := range ids {
+			prop.People[i] = User{ID: id}
+		}
+	case DBPropTypeFiles:
+		if v.Type() == filesType {
+			prop.Files = v.Interface().([]FileObject)
+			break
+		}
+		urls, err := encodeStrings(v)
+		if err != nil {
+			return prop, false, err
+		}
+		prop.Files = make([]FileObject, len(urls))
+		for i, u := range urls {
+			prop.Files[i] = FileObject{Type: FileTypeExternal, Name: u, External: &ExternalFile{URL: u}}
+		}
+	default:
+		return prop, false, fmt.Errorf("unsupported property type %q", typ)
+	}
+	return prop, true, nil
+}
+
+func encodeStrings(v reflect.Value) ([]string, error) {
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.String {
+		return nil, errTypeMismatch
+	}
+	res := make([]string, v.Len())
+	for i := range res {
+		res[i] = v.Index(i).String()
+	}
+	return res, nil
+}
@@ -0,0 +1,259 @@
+package notion_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+)
+
+type task struct {
+	Name      string     `notion:"Name,title"`
+	Status    string     `notion:"Status,select"`
+	Tags      []string   `notion:"Tags,multi_select"`
+	Estimate  int        `notion:"Estimate,number"`
+	Due       time.Time  `notion:"Due,date"`
+	Blockers  []string   `notion:"Blocked by,relation"`
+	Done      bool       `notion:"Done,checkbox"`
+	Website   string     `notion:"Website,url"`
+	Owners    []string   `notion:"Owners,people"`
+	Reviewed  *time.Time `notion:"Reviewed,date"`
+	CreatedBy string     `notion:"Created by,created_by"`
+	Ignored   string     `notion:"-"`
+}
+
+const taskPageJSON = `{
+	"object": "page",
+	"id": "606ed832-7d79-46de-bbed-5b4896e7bc02",
+	"created_time": "2021-05-19T18:34:00.000Z",
+	"last_edited_time": "2021-05-19T18:34:00.000Z",
+	"parent": {
+		"type": "database_id",
+		"database_id": "39ddf6b8-e8a0-4d2a-98d4-de7f2f0e5b38"
+	},
+	"archived": false,
+	"properties": {
+		"Name": {
+			"id": "title",
+			"type": "title",
+			"title": [
+				{"type": "text", "text": {"content": "Ship "}, "plain_text": "Ship "},
+				{"type": "text", "text": {"content": "it"}, "plain_text": "it"}
+			]
+		},
+		"Status": {"id": "a", "type": "select", "select": {"id": "1", "name": "In progress", "color": "blue"}},
+		"Tags": {"id": "b", "type": "multi_select", "multi_select": [{"name": "backend"}, {"name": "urgent"}]},
+		"Estimate": {"id": "c", "type": "number", "number": 3},
+		"Due": {"id": "d", "type": "date", "date": {"start": "2021-06-01"}},
+		"Blocked by": {"id": "e", "type": "relation", "relation": [{"id": "7e0a3f46-6a4b-4f32-9a19-5b8ef4a7d1c5"}]},
+		"Done": {"id": "f", "type": "checkbox", "checkbox": true},
+		"Website": {"id": "g", "type": "url", "url": null},
+		"Owners": {"id": "h", "type": "people", "people": [{"object": "user", "id": "be32e790-8292-46df-a248-b784fdf483cf"}]},
+		"Reviewed": {"id": "i", "type": "date", "date": null},
+		"Created by": {"id": "j", "type": "created_by", "created_by": {"object": "user", "id": "be32e790-8292-46df-a248-b784fdf483cf"}}
+	}
+}`
+
+func TestUnmarshalPage(t *testing.T) {
+	t.Parallel()
+
+	var page notion.Page
+	if err := json.Unmarshal([]byte(taskPageJSON), &page); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := task{Ignored: "keep", Reviewed: &time.Time{}}
+	if err := notion.UnmarshalPage(&page, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := task{
+		Name:      "Ship it",
+		Status:    "In progress",
+		Tags:      []string{"backend", "urgent"},
+		Estimate:  3,
+		Due:       time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		Blockers:  []string{"7e0a3f46-6a4b-4f32-9a19-5b8ef4a7d1c5"},
+		Done:      true,
+		Owners:    []string{"be32e790-8292-46df-a248-b784fdf483cf"},
+		CreatedBy: "be32e790-8292-46df-a248-b784fdf483cf",
+		Ignored:   "keep",
+	}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("struct not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestUnmarshalPageRollup(t *testing.T) {
+	t.Parallel()
+
+	data := `{
+		"object": "page",
+		"id": "606ed832-7d79-46de-bbed-5b4896e7bc02",
+		"parent": {"type": "database_id", "database_id": "39ddf6b8-e8a0-4d2a-98d4-de7f2f0e5b38"},
+		"properties": {
+			"Blockers": {"id": "a", "type": "rollup", "rollup": {"type": "number", "number": 2}},
+			"Next due": {"id": "b", "type": "rollup", "rollup": {"type": "date", "date": {"start": "2021-06-01"}}},
+			"Names": {
+				"id": "c",
+				"type": "rollup",
+				"rollup": {"type": "array", "array": [{"type": "rich_text", "rich_text": []}]}
+			}
+		}
+	}`
+	var page notion.Page
+	if err := json.Unmarshal([]byte(data), &page); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got struct {
+		Blockers int                    `notion:"Blockers,rollup"`
+		NextDue  time.Time              `notion:"Next due,rollup"`
+		Names    *notion.RollupProperty `notion:"Names,rollup"`
+	}
+	if err := notion.UnmarshalPage(&page, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Blockers != 2 {
+		t.Errorf("expected 2 blockers, got %d", got.Blockers)
+	}
+	if exp := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC); !got.NextDue.Equal(exp) {
+		t.Errorf("expected next due %v, got %v", exp, got.NextDue)
+	}
+	if got.Names == nil || got.Names.Type != notion.RollupTypeArray || len(got.Names.Array) != 1 ||
+		got.Names.Array[0].Type != notion.DBPropTypeRichText {
+		t.Errorf("unexpected names rollup %+v", got.Names)
+	}
+}
+
+func TestUnmarshalPageErrors(t *testing.T) {
+	t.Parallel()
+
+	var page notion.Page
+	if err := json.Unmarshal([]byte(taskPageJSON), &page); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wrongGoType struct {
+		Status int `notion:"Status"`
+	}
+	err := notion.UnmarshalPage(&page, &wrongGoType)
+	var typeErr *notion.PropertyTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected PropertyTypeError, got: %v", err)
+	}
+	exp := `notion: select property "Status" doesn't match Go field Status of type int`
+	if err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+
+	var wrongPropType struct {
+		Status []string `notion:"Status,multi_select"`
+	}
+	err = notion.UnmarshalPage(&page, &wrongPropType)
+	exp = `notion: property "Status" is of type select, not multi_select`
+	if err == nil || err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+
+	err = notion.UnmarshalPage(&page, task{})
+	exp = "notion: can't unmarshal page into notion_test.task, expected a non-nil pointer to a struct"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("error not equal (expected: %v, got: %v)", exp, err)
+	}
+}
+
+func TestMarshalProperties(t *testing.T) {
+	t.Parallel()
+
+	in := task{
+		Name:      "Ship it",
+		Status:    "Done",
+		Tags:      []string{"backend"},
+		Estimate:  5,
+		Due:       time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		Done:      true,
+		CreatedBy: "ignored, read-only",
+	}
+	got, err := notion.MarshalProperties(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := true
+	exp := notion.DatabasePageProperties{
+		"Name": {
+			Type:  notion.DBPropTypeTitle,
+			Title: []notion.RichText{{Type: notion.RichTextTypeText, Text: &notion.Text{Content: "Ship it"}}},
+		},
+		"Status":     {Type: notion.DBPropTypeSelect, Select: &notion.SelectOptions{Name: "Done"}},
+		"Tags":       {Type: notion.DBPropTypeMultiSelect, MultiSelect: []notion.SelectOptions{{Name: "backend"}}},
+		"Estimate":   {Type: notion.DBPropTypeNumber, Number: floatPtr(5)},
+		"Due":        {Type: notion.DBPropTypeDate, Date: &notion.Date{Start: notion.Time(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC))}},
+		"Blocked by": {Type: notion.DBPropTypeRelation, Relation: []notion.RelationProperty{}},
+		"Done":       {Type: notion.DBPropTypeCheckbox, Checkbox: &done},
+		"Owners":     {Type: notion.DBPropTypePeople, People: []notion.User{}},
+	}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("properties not equal (-exp, +got):\n%v", diff)
+	}
+}
+
+func TestMarshalPropertiesClear(t *testing.T) {
+	t.Parallel()
+
+	var in struct {
+		Tags     []string `notion:"Tags,multi_select"`
+		Blockers []string `notion:"Blocked by,relation"`
+		Owners   []string `notion:"Owners,people"`
+		Files    []string `notion:"Files,files"`
+	}
+	props, err := notion.MarshalProperties(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := json.Marshal(props)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := `{"Blocked by":{"type":"relation","relation":[]},"Files":{"type":"files","files":[]},` +
+		`"Owners":{"type":"people","people":[]},"Tags":{"type":"multi_select","multi_select":[]}}`
+	if string(got) != exp {
+		t.Fatalf("JSON not equal (expected: %s, got: %s)", exp, got)
+	}
+}
+
+func TestMarshalPropertiesInferredTypes(t *testing.T) {
+	t.Parallel()
+
+	in := struct {
+		Notes  string
+		Count  float64
+		Active bool
+	}{"hello", 1.5, false}
+	got, err := notion.MarshalProperties(&in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	active := false
+	exp := notion.DatabasePageProperties{
+		"Notes":  {Type: notion.DBPropTypeRichText, RichText: []notion.RichText{{Type: notion.RichTextTypeText, Text: &notion.Text{Content: "hello"}}}},
+		"Count":  {Type: notion.DBPropTypeNumber, Number: floatPtr(1.5)},
+		"Active": {Type: notion.DBPropTypeCheckbox, Checkbox: &active},
+	}
+	if diff := cmp.Diff(exp, got); diff != "" {
+		t.Fatalf("properties not equal (-exp, +got):\n%v", diff)
+	}
+
+	_, err = notion.MarshalProperties(struct {
+		Count string `notion:"Count,number"`
+	}{})
+	var typeErr *notion.PropertyTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected PropertyTypeError, got: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of query"
+	case tokIdent:
+		return "identifier"
+	case tokString:
+		return "string"
+	case tokNumber:
+		return "number"
+	}
+	return "punctuation"
+}
+
+type token struct {
+	kind tokenKind
+	// text is the unquoted value of strings and source text of other tokens
+	text string
+	// pos is 1-based column of the token, in runes
+	pos int
+}
+
+// is returns true if the token is a given keyword or punctuation.
+// Keywords are case-insensitive.
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokIdent:
+		return strings.EqualFold(t.text, s)
+	case tokPunct:
+		return t.text == s
+	}
+	return false
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+// lex splits src into tokens. The last token is always tokEOF.
+func lex(src string) ([]token, error) {
+	var res []token
+	col := 1
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		start, startCol := i, col
+		advance := func() {
+			i += size
+			col++
+			if i < len(src) {
+				r, size = utf8.DecodeRuneInString(src[i:])
+			}
+		}
+		switch {
+		case unicode.IsSpace(r):
+			advance()
+		case r == '"':
+			advance()
+			escaped := false
+			for {
+				if i >= len(src) {
+					return nil, &Error{Pos: startCol, Msg: "unterminated string"}
+				}
+				if r == '"' && !escaped {
+					break
+				}
+				escaped = r == '\\' && !escaped
+				advance()
+			}
+			advance()
+			s, err := strconv.Unquote(src[start:i])
+			if err != nil {
+				return nil, &Error{Pos: startCol, Msg: "invalid string " + src[start:i]}
+			}
+			res = append(res, token{kind: tokString, text: s, pos: startCol})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9'):
+			advance()
+			for i < len(src) && (unicode.IsDigit(r) || r == '.') {
+				advance()
+			}
+			res = append(res, token{kind: tokNumber, text: src[start:i], pos: startCol})
+		case isIdentRune(r):
+			for i < len(src) && isIdentRune(r) {
+				advance()
+			}
+			res = append(res, token{kind: tokIdent, text: src[start:i], pos: startCol})
+		case strings.ContainsRune("()=,", r):
+			advance()
+			res = append(res, token{kind: tokPunct, text: src[start:i], pos: startCol})
+		case r == '!' || r == '<' || r == '>':
+			advance()
+			if i < len(src) && r == '=' {
+				advance()
+			}
+			text := src[start:i]
+			if text == "!" {
+				return nil, &Error{Pos: startCol, Msg: "unexpected '!', did you mean '!='?"}
+			}
+			res = append(res, token{kind: tokPunct, text: text, pos: startCol})
+		default:
+			return nil, &Error{Pos: startCol, Msg: "unexpected character " + strconv.QuoteRune(r)}
+		}
+	}
+	res = append(res, token{kind: tokEOF, pos: col})
+	return res, nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,737 @@
+// Package query compiles a textual query language into notion.DatabaseQuery.
+//
+// A query is a filter expression optionally followed by sorts:
+//
+//	Status = "Done" and (Priority > 2 or Tags contains "urgent") sort by Due desc
+//
+// Conditions have the form: property operator value. Property names that
+// are not a single word must be quoted e.g. "Due date". Supported operators:
+//
+//	=  !=  >  <  >=  <=
+//	contains, does not contain, starts with, ends with
+//	is empty, is not empty
+//	before, after, on or before, on or after
+//	in past week, in past month, in past year
+//	in next week, in next month, in next year
+//
+// Values are strings in double quotes, numbers and true or false. Dates are
+// strings in "2006-01-02" or RFC 3339 format.
+//
+// Conditions on rollup properties of arrays follow the property name with
+// any, every or none, e.g. Tasks any = "Done". Conditions on unquoted
+// created_time and last_edited_time filter by the page timestamps, e.g.
+// created_time in past week.
+//
+// Conditions are combined with and, or and parentheses. and binds tighter
+// than or.
+//
+// Sorts are a comma separated list of property names, each optionally
+// followed by asc or desc. created_time and last_edited_time sort by the
+// page timestamps, unless the database has properties with those names.
+//
+// Without a schema (see Parse) the type of filter is inferred from the
+// operator and the value: number for numbers, checkbox for true and false,
+// date for date operators and dates, and text for other strings. The API
+// only accepts text filters for title, rich_text, url, email and
+// phone_number properties, so e.g. Status = "Done" on a select property
+// parses, but the query fails. With a schema (see Compile) property names
+// are checked and the filter matches the property type. Conditions on
+// formula properties use the inferred type of the formula result.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kjk/notion"
+)
+
+// Error is a syntax or type error in a query.
+type Error struct {
+	// Pos is 1-based column of the error, in characters.
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("query: column %d: %s", e.Pos, e.Msg)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse compiles a query without checking it against a database schema.
+//
+// Types of filters are inferred from values and strings always compile to
+// text filters. Conditions on strings of select, multi_select, people,
+// files and relation properties, e.g. Status = "Done", parse but are
+// rejected by the API. Use Compile with the database for them.
+func Parse(src string) (*notion.DatabaseQuery, error) {
+	return Compile(src, nil)
+}
+
+// Compile compiles a query. If db is not nil, property names and operators
+// are checked against properties of the database, as returned by
+// Client.GetDatabase.
+func Compile(src string, db *notion.Database) (*notion.DatabaseQuery, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, db: db}
+	res := &notion.DatabaseQuery{}
+
+	if !p.peek().is("sort") && p.peek().kind != tokEOF {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if res.Filter, err = n.filter(0); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().is("sort") {
+		p.next()
+		if err := p.expect("by"); err != nil {
+			return nil, err
+		}
+		if res.Sorts, err = p.parseSorts(); err != nil {
+			return nil, err
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %s", t)
+	}
+	return res, nil
+}
+
+type parser struct {
+	toks []token
+	i    int
+	db   *notion.Database
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) expect(s string) error {
+	t := p.next()
+	if !t.is(s) {
+		return errorf(t.pos, "expected '%s', got %s", s, t)
+	}
+	return nil
+}
+
+// node is a parsed filter expression.
+type node struct {
+	pos int
+	// op is "and" or "or" for compound filters, "" for conditions
+	op       string
+	children []*node
+	cond     notion.DatabaseQueryFilter
+}
+
+func (p *parser) parseOr() (*node, error) {
+	return p.parseCompound("or", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*node, error) {
+	return p.parseCompound("and", p.parseUnary)
+}
+
+func (p *parser) parseCompound(op string, parseChild func() (*node, error)) (*node, error) {
+	pos := p.peek().pos
+	n, err := parseChild()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peek().is(op) {
+		return n, nil
+	}
+	res := &node{pos: pos, op: op}
+	res.add(n)
+	for p.peek().is(op) {
+		p.next()
+		n, err := parseChild()
+		if err != nil {
+			return nil, err
+		}
+		res.add(n)
+	}
+	return res, nil
+}
+
+// add adds a child, flattening children of the same kind e.g. a and (b and c)
+// is a and b and c.
+func (n *node) add(child *node) {
+	if child.op == n.op {
+		n.children = append(n.children, child.children...)
+		return
+	}
+	n.children = append(n.children, child)
+}
+
+func (p *parser) parseUnary() (*node, error) {
+	if p.peek().is("(") {
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return p.parseCondition()
+}
+
+// filter converts the node to a filter, checking nesting depth of compound
+// filters.
+func (n *node) filter(depth int) (*notion.DatabaseQueryFilter, error) {
+	if n.op == "" {
+		return &n.cond, nil
+	}
+	depth++
+	if depth > notion.MaxFilterDepth {
+		return nil, errorf(n.pos, "and/or can't be nested more than %d levels deep", notion.MaxFilterDepth)
+	}
+	filters := make([]notion.DatabaseQueryFilter, len(n.children))
+	for i, c := range n.children {
+		f, err := c.filter(depth)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = *f
+	}
+	if n.op == "and" {
+		return &notion.DatabaseQueryFilter{And: filters}, nil
+	}
+	return &notion.DatabaseQueryFilter{Or: filters}, nil
+}
+
+// operator is a comparison in a condition.
+type operator struct {
+	name string
+	pos  int
+	// hasValue is false for operators like "is empty"
+	hasValue bool
+}
+
+func (p *parser) parsePropName() (token, error) {
+	t := p.next()
+	if t.kind != tokIdent && t.kind != tokString {
+		return t, errorf(t.pos, "expected property name, got %s", t)
+	}
+	return t, nil
+}
+
+// words parses a sequence of keywords. It returns false without consuming
+// tokens if they don't match.
+func (p *parser) words(words ...string) bool {
+	for i, w := range words {
+		if p.i+i >= len(p.toks) || !p.toks[p.i+i].is(w) {
+			return false
+		}
+	}
+	p.i += len(words)
+	return true
+}
+
+var multiWordOps = [][]string{
+	{"does", "not", "contain"},
+	{"not", "contains"},
+	{"starts", "with"},
+	{"ends", "with"},
+	{"is", "not", "empty"},
+	{"is", "empty"},
+	{"on", "or", "before"},
+	{"on", "or", "after"},
+	{"in", "past", "week"},
+	{"in", "past", "month"},
+	{"in", "past", "year"},
+	{"in", "next", "week"},
+	{"in", "next", "month"},
+	{"in", "next", "year"},
+}
+
+func (p *parser) parseOperator() (operator, error) {
+	t := p.peek()
+	op := operator{pos: t.pos, hasValue: true}
+	switch {
+	case t.kind == tokPunct && t.text != "(" && t.text != ")" && t.text != ",":
+		p.next()
+		op.name = t.text
+		return op, nil
+	case t.is("contains"), t.is("before"), t.is("after"):
+		p.next()
+		op.name = strings.ToLower(t.text)
+		return op, nil
+	}
+	for _, words := range multiWordOps {
+		if p.words(words...) {
+			op.name = strings.Join(words, " ")
+			switch op.name {
+			case "not contains":
+				op.name = "does not contain"
+			case "is empty", "is not empty":
+				op.hasValue = false
+			}
+			if words[0] == "in" {
+				op.name = op.name[len("in "):]
+				op.hasValue = false
+			}
+			return op, nil
+		}
+	}
+	return op, errorf(t.pos, "expected operator, got %s", t)
+}
+
+// value is a literal in a condition.
+type value struct {
+	tok token
+	num float64
+	b   bool
+}
+
+func (p *parser) parseValue() (value, error) {
+	t := p.next()
+	v := value{tok: t}
+	switch {
+	case t.kind == tokString:
+		return v, nil
+	case t.kind == tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return v, errorf(t.pos, "invalid number %s", t.text)
+		}
+		v.num = n
+		return v, nil
+	case t.is("true"), t.is("false"):
+		v.b = t.is("true")
+		return v, nil
+	}
+	return v, errorf(t.pos, "expected value, got %s", t)
+}
+
+// filter types
+const (
+	kindText        = "text"
+	kindNumber      = "number"
+	kindCheckbox    = "checkbox"
+	kindSelect      = "select"
+	kindMultiSelect = "multi_select"
+	kindDate        = "date"
+	kindPeople      = "people"
+	kindFiles       = "files"
+	kindRelation    = "relation"
+)
+
+// filterKind returns the type of filter for a property type.
+func filterKind(typ notion.DatabasePropertyType) string {
+	switch typ {
+	case notion.DBPropTypeTitle, notion.DBPropTypeRichText,
+		notion.DBPropTypeURL, notion.DBPropTypeEmail, notion.DBPropTypePhoneNumber:
+		return kindText
+	case notion.DBPropTypeNumber:
+		return kindNumber
+	case notion.DBPropTypeCheckbox:
+		return kindCheckbox
+	case notion.DBPropTypeSelect:
+		return kindSelect
+	case notion.DBPropTypeMultiSelect:
+		return kindMultiSelect
+	case notion.DBPropTypeDate, notion.DBPropTypeCreatedTime, notion.DBPropTypeLastEditedTime:
+		return kindDate
+	case notion.DBPropTypePeople, notion.DBPropTypeCreatedBy, notion.DBPropTypeLastEditedBy:
+		return kindPeople
+	case notion.DBPropTypeFiles:
+		return kindFiles
+	case notion.DBPropTypeRelation:
+		return kindRelation
+	}
+	return ""
+}
+
+// inferKind guesses the type of filter from operator and value when there's
+// no schema. Text is returned when the type is unknown.
+func inferKind(op operator, v value) string {
+	switch op.name {
+	case "before", "after", "on or before", "on or after",
+		"past week", "past month", "past year", "next week", "next month", "next year":
+		return kindDate
+	}
+	switch v.tok.kind {
+	case tokNumber:
+		return kindNumber
+	case tokIdent:
+		return kindCheckbox
+	case tokString:
+		if _, err := parseDate(v.tok.text); err == nil && op.name != "contains" && op.name != "does not contain" {
+			return kindDate
+		}
+	}
+	return kindText
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+	}
+	return t, err
+}
+
+func (p *parser) parseCondition() (*node, error) {
+	nameTok, err := p.parsePropName()
+	if err != nil {
+		return nil, err
+	}
+	name := nameTok.text
+
+	var kind string
+	var propType notion.DatabasePropertyType
+	timestamp := timestampName(nameTok)
+	if p.db != nil {
+		prop, ok := p.db.Properties[name]
+		switch {
+		case ok:
+			timestamp = ""
+			propType = prop.Type
+			kind = filterKind(prop.Type)
+			if kind == "" && prop.Type != notion.DBPropTypeFormula && prop.Type != notion.DBPropTypeRollup {
+				return nil, errorf(nameTok.pos, "filtering on %s property %q is not supported", prop.Type, name)
+			}
+		case timestamp == "":
+			return nil, errorf(nameTok.pos, "unknown property %q", name)
+		}
+	}
+
+	var quantifier token
+	if t := p.peek(); t.is("any") || t.is("every") || t.is("none") {
+		quantifier = p.next()
+		if propType != "" && propType != notion.DBPropTypeRollup {
+			return nil, errorf(t.pos, "'%s' is only supported for rollup properties, %q is %s property", t.text, name, propType)
+		}
+		timestamp = ""
+		kind = ""
+	}
+
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	var v value
+	if op.hasValue {
+		if v, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case timestamp != "":
+		kind = kindDate
+	case kind == "":
+		kind = inferKind(op, v)
+	}
+
+	cond, err := buildCondition(name, kind, op, v)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case timestamp != "":
+		date := cond.Date
+		cond = notion.DatabaseQueryFilter{Timestamp: timestamp, CreatedTime: date}
+		if timestamp == notion.SortTimeStampLastEditedTime {
+			cond = notion.DatabaseQueryFilter{Timestamp: timestamp, LastEditedTime: date}
+		}
+	case quantifier.kind != tokEOF:
+		item := cond
+		item.Property = ""
+		c := &notion.RollupDatabaseQueryFilter{}
+		switch strings.ToLower(quantifier.text) {
+		case "any":
+			c.Any = &item
+		case "every":
+			c.Every = &item
+		default:
+			c.None = &item
+		}
+		cond = notion.DatabaseQueryFilter{Property: name, Rollup: c}
+	case propType == notion.DBPropTypeRollup:
+		if kind != kindNumber && kind != kindDate {
+			return nil, errorf(op.pos, "rollup property %q only supports number and date conditions, use any, every or none for %s conditions", name, kind)
+		}
+		cond = notion.DatabaseQueryFilter{Property: name, Rollup: &notion.RollupDatabaseQueryFilter{
+			Number: cond.Number,
+			Date:   cond.Date,
+		}}
+	case propType == notion.DBPropTypeFormula:
+		cond = notion.DatabaseQueryFilter{Property: name, Formula: &notion.FormulaDatabaseQueryFilter{
+			Text:     cond.Text,
+			Number:   cond.Number,
+			Checkbox: cond.Checkbox,
+			Date:     cond.Date,
+		}}
+	}
+	return &node{pos: nameTok.pos, cond: cond}, nil
+}
+
+// timestampName returns the page timestamp named by an unquoted
+// created_time or last_edited_time, or "" for other tokens.
+func timestampName(t token) notion.SortTimestamp {
+	if t.kind == tokIdent &&
+		(t.text == string(notion.SortTimeStampCreatedTime) || t.text == string(notion.SortTimeStampLastEditedTime)) {
+		return notion.SortTimestamp(t.text)
+	}
+	return ""
+}
+
+func buildCondition(name, kind string, op operator, v value) (notion.DatabaseQueryFilter, error) {
+	f := notion.DatabaseQueryFilter{Property: name}
+	unsupported := func() error {
+		return errorf(op.pos, "operator '%s' is not supported for %s property %q", op.name, kind, name)
+	}
+	str := func() (string, error) {
+		if v.tok.kind != tokString {
+			return "", errorf(v.tok.pos, "expected string value for %s property %q, got %s", kind, name, v.tok)
+		}
+		return v.tok.text, nil
+	}
+
+	switch kind {
+	case kindText:
+		c := &notion.TextDatabaseQueryFilter{}
+		f.Text = c
+		if !op.hasValue {
+			return f, setEmpty(op, &c.IsEmpty, &c.IsNotEmpty, unsupported)
+		}
+		s, err := str()
+		if err != nil {
+			return f, err
+		}
+		switch op.name {
+		case "=":
+			c.Equals = s
+		case "!=":
+			c.DoesNotEqual = s
+		case "contains":
+			c.Contains = s
+		case "does not contain":
+			c.DoesNotContain = s
+		case "starts with":
+			c.StartsWith = s
+		case "ends with":
+			c.EndsWith = s
+		default:
+			return f, unsupported()
+		}
+	case kindNumber:
+		c := &notion.NumberDatabaseQueryFilter{}
+		f.Number = c
+		if !op.hasValue {
+			return f, setEmpty(op, &c.IsEmpty, &c.IsNotEmpty, unsupported)
+		}
+		if v.tok.kind != tokNumber {
+			return f, errorf(v.tok.pos, "expected number value for number property %q, got %s", name, v.tok)
+		}
+		n := v.num
+		switch op.name {
+		case "=":
+			c.Equals = &n
+		case "!=":
+			c.DoesNotEqual = &n
+		case ">":
+			c.GreaterThan = &n
+		case "<":
+			c.LessThan = &n
+		case ">=":
+			c.GreaterThanOrEqualTo = &n
+		case "<=":
+			c.LessThanOrEqualTo = &n
+		default:
+			return f, unsupported()
+		}
+	case kindCheckbox:
+		c := &notion.CheckboxDatabaseQueryFilter{}
+		f.Checkbox = c
+		if !op.hasValue {
+			return f, unsupported()
+		}
+		if !v.tok.is("true") && !v.tok.is("false") {
+			return f, errorf(v.tok.pos, "expected true or false for checkbox property %q, got %s", name, v.tok)
+		}
+		b := v.b
+		switch op.name {
+		case "=":
+			c.Equals = &b
+		case "!=":
+			c.DoesNotEqual = &b
+		default:
+			return f, unsupported()
+		}
+	case kindSelect:
+		c := &notion.SelectDatabaseQueryFilter{}
+		f.Select = c
+		if !op.hasValue {
+			return f, setEmpty(op, &c.IsEmpty, &c.IsNotEmpty, unsupported)
+		}
+		s, err := str()
+		if err != nil {
+			return f, err
+		}
+		switch op.name {
+		case "=":
+			c.Equals = s
+		case "!=":
+			c.DoesNotEqual = s
+		default:
+			return f, unsupported()
+		}
+	case kindMultiSelect, kindPeople, kindRelation:
+		var contains, doesNotContain *string
+		var isEmpty, isNotEmpty *bool
+		switch kind {
+		case kindMultiSelect:
+			c := &notion.MultiSelectDatabaseQueryFilter{}
+			f.MultiSelect = c
+			contains, doesNotContain, isEmpty, isNotEmpty = &c.Contains, &c.DoesNotContain, &c.IsEmpty, &c.IsNotEmpty
+		case kindPeople:
+			c := &notion.PeopleDatabaseQueryFilter{}
+			f.People = c
+			contains, doesNotContain, isEmpty, isNotEmpty = &c.Contains, &c.DoesNotContain, &c.IsEmpty, &c.IsNotEmpty
+		default:
+			c := &notion.RelationDatabaseQueryFilter{}
+			f.Relation = c
+			contains, doesNotContain, isEmpty, isNotEmpty = &c.Contains, &c.DoesNotContain, &c.IsEmpty, &c.IsNotEmpty
+		}
+		if !op.hasValue {
+			return f, setEmpty(op, isEmpty, isNotEmpty, unsupported)
+		}
+		s, err := str()
+		if err != nil {
+			return f, err
+		}
+		switch op.name {
+		case "contains":
+			*contains = s
+		case "does not contain":
+			*doesNotContain = s
+		default:
+			return f, unsupported()
+		}
+	case kindFiles:
+		c := &notion.FilesDatabaseQueryFilter{}
+		f.Files = c
+		return f, setEmpty(op, &c.IsEmpty, &c.IsNotEmpty, unsupported)
+	case kindDate:
+		c := &notion.DateDatabaseQueryFilter{}
+		f.Date = c
+		if !op.hasValue {
+			switch op.name {
+			case "past week":
+				c.PastWeek = &struct{}{}
+			case "past month":
+				c.PastMonth = &struct{}{}
+			case "past year":
+				c.PastYear = &struct{}{}
+			case "next week":
+				c.NextWeek = &struct{}{}
+			case "next month":
+				c.NextMonth = &struct{}{}
+			case "next year":
+				c.NextYear = &struct{}{}
+			default:
+				return f, setEmpty(op, &c.IsEmpty, &c.IsNotEmpty, unsupported)
+			}
+			return f, nil
+		}
+		s, err := str()
+		if err != nil {
+			return f, err
+		}
+		t, err := parseDate(s)
+		if err != nil {
+			return f, errorf(v.tok.pos, "invalid date %q, expected YYYY-MM-DD or RFC 3339 format", s)
+		}
+		switch op.name {
+		case "=":
+			c.Equals = &t
+		case "<", "before":
+			c.Before = &t
+		case ">", "after":
+			c.After = &t
+		case "<=", "on or before":
+			c.OnOrBefore = &t
+		case ">=", "on or after":
+			c.OnOrAfter = &t
+		default:
+			return f, unsupported()
+		}
+	}
+	return f, nil
+}
+
+func setEmpty(op operator, isEmpty, isNotEmpty *bool, unsupported func() error) error {
+	switch op.name {
+	case "is empty":
+		*isEmpty = true
+	case "is not empty":
+		*isNotEmpty = true
+	default:
+		return unsupported()
+	}
+	return nil
+}
+
+func (p *parser) parseSorts() ([]notion.DatabaseQuerySort, error) {
+	var res []notion.DatabaseQuerySort
+	for {
+		nameTok, err := p.parsePropName()
+		if err != nil {
+			return nil, err
+		}
+		var s notion.DatabaseQuerySort
+		name := nameTok.text
+		isTimestamp := timestampName(nameTok) != ""
+		if p.db != nil {
+			if _, ok := p.db.Properties[name]; ok {
+				isTimestamp = false
+			} else if !isTimestamp {
+				return nil, errorf(nameTok.pos, "unknown property %q", name)
+			}
+		}
+		if isTimestamp {
+			s.Timestamp = notion.SortTimestamp(name)
+		} else {
+			s.Property = name
+		}
+
+		s.Direction = notion.SortDirAsc
+		switch t := p.peek(); {
+		case t.is("asc"):
+			p.next()
+		case t.is("desc"):
+			p.next()
+			s.Direction = notion.SortDirDesc
+		}
+		res = append(res, s)
+
+		if !p.peek().is(",") {
+			return res, nil
+		}
+		p.next()
+	}
+}
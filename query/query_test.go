@@ -0,0 +1,257 @@
+package query_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kjk/notion"
+	"github.com/kjk/notion/query"
+)
+
+func floatPtr(n float64) *float64 {
+	return &n
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+var schema = &notion.Database{
+	ID: "39ddf6b8-e8a0-4d2a-98d4-de7f2f0e5b38",
+	Properties: notion.DatabaseProperties{
+		"Name":     {Type: notion.DBPropTypeTitle},
+		"Status":   {Type: notion.DBPropTypeSelect},
+		"Priority": {Type: notion.DBPropTypeNumber},
+		"Tags":     {Type: notion.DBPropTypeMultiSelect},
+		"Due date": {Type: notion.DBPropTypeDate},
+		"Done":     {Type: notion.DBPropTypeCheckbox},
+		"Owner":    {Type: notion.DBPropTypePeople},
+		"Days":     {Type: notion.DBPropTypeFormula},
+		"Subtasks": {Type: notion.DBPropTypeRollup},
+	},
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	due := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		src  string
+		db   *notion.Database
+		exp  *notion.DatabaseQuery
+	}{
+		{
+			name: "example from the docs",
+			src:  `Status = "Done" and (Priority > 2 or Tags contains "urgent") sort by "Due date" desc`,
+			db:   schema,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					And: []notion.DatabaseQueryFilter{
+						{Property: "Status", Select: &notion.SelectDatabaseQueryFilter{Equals: "Done"}},
+						{
+							Or: []notion.DatabaseQueryFilter{
+								{Property: "Priority", Number: &notion.NumberDatabaseQueryFilter{GreaterThan: floatPtr(2)}},
+								{Property: "Tags", MultiSelect: &notion.MultiSelectDatabaseQueryFilter{Contains: "urgent"}},
+							},
+						},
+					},
+				},
+				Sorts: []notion.DatabaseQuerySort{
+					{Property: "Due date", Direction: notion.SortDirDesc},
+				},
+			},
+		},
+		{
+			name: "without schema types are inferred",
+			src:  `Name starts with "Ship" AND Priority >= 2 and Price < 19.99 and Done != false and Due before "2021-06-01"`,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					And: []notion.DatabaseQueryFilter{
+						{Property: "Name", Text: &notion.TextDatabaseQueryFilter{StartsWith: "Ship"}},
+						{Property: "Priority", Number: &notion.NumberDatabaseQueryFilter{GreaterThanOrEqualTo: floatPtr(2)}},
+						{Property: "Price", Number: &notion.NumberDatabaseQueryFilter{LessThan: floatPtr(19.99)}},
+						{Property: "Done", Checkbox: &notion.CheckboxDatabaseQueryFilter{DoesNotEqual: boolPtr(false)}},
+						{Property: "Due", Date: &notion.DateDatabaseQueryFilter{Before: &due}},
+					},
+				},
+			},
+		},
+		{
+			name: "without schema strings are text",
+			src:  `Status = "Done" and (Priority > 2 or Tags contains "urgent") sort by Due desc`,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					And: []notion.DatabaseQueryFilter{
+						{Property: "Status", Text: &notion.TextDatabaseQueryFilter{Equals: "Done"}},
+						{Or: []notion.DatabaseQueryFilter{
+							{Property: "Priority", Number: &notion.NumberDatabaseQueryFilter{GreaterThan: floatPtr(2)}},
+							{Property: "Tags", Text: &notion.TextDatabaseQueryFilter{Contains: "urgent"}},
+						}},
+					},
+				},
+				Sorts: []notion.DatabaseQuerySort{
+					{Property: "Due", Direction: notion.SortDirDesc},
+				},
+			},
+		},
+		{
+			name: "multi-word operators",
+			src:  `Name starts with "a" or Name does not contain "b" or Owner is not empty or "Due date" in past week`,
+			db:   schema,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					Or: []notion.DatabaseQueryFilter{
+						{Property: "Name", Text: &notion.TextDatabaseQueryFilter{StartsWith: "a"}},
+						{Property: "Name", Text: &notion.TextDatabaseQueryFilter{DoesNotContain: "b"}},
+						{Property: "Owner", People: &notion.PeopleDatabaseQueryFilter{IsNotEmpty: true}},
+						{Property: "Due date", Date: &notion.DateDatabaseQueryFilter{PastWeek: &struct{}{}}},
+					},
+				},
+			},
+		},
+		{
+			name: "nested groups of the same kind are flattened",
+			src:  `(Done = true and (Priority = 1 and Priority != 2))`,
+			db:   schema,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					And: []notion.DatabaseQueryFilter{
+						{Property: "Done", Checkbox: &notion.CheckboxDatabaseQueryFilter{Equals: boolPtr(true)}},
+						{Property: "Priority", Number: &notion.NumberDatabaseQueryFilter{Equals: floatPtr(1)}},
+						{Property: "Priority", Number: &notion.NumberDatabaseQueryFilter{DoesNotEqual: floatPtr(2)}},
+					},
+				},
+			},
+		},
+		{
+			name: "formula, rollup and timestamp conditions",
+			src:  `Days > 3 and Subtasks every = "Done" and Subtasks < 10 and last_edited_time in past week`,
+			db:   schema,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					And: []notion.DatabaseQueryFilter{
+						{
+							Property: "Days",
+							Formula:  &notion.FormulaDatabaseQueryFilter{Number: &notion.NumberDatabaseQueryFilter{GreaterThan: floatPtr(3)}},
+						},
+						{
+							Property: "Subtasks",
+							Rollup: &notion.RollupDatabaseQueryFilter{
+								Every: &notion.DatabaseQueryFilter{Text: &notion.TextDatabaseQueryFilter{Equals: "Done"}},
+							},
+						},
+						{
+							Property: "Subtasks",
+							Rollup:   &notion.RollupDatabaseQueryFilter{Number: &notion.NumberDatabaseQueryFilter{LessThan: floatPtr(10)}},
+						},
+						{
+							Timestamp:      notion.SortTimeStampLastEditedTime,
+							LastEditedTime: &notion.DateDatabaseQueryFilter{PastWeek: &struct{}{}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "rollup without schema",
+			src:  `Subtasks none ends with "bug"`,
+			exp: &notion.DatabaseQuery{
+				Filter: &notion.DatabaseQueryFilter{
+					Property: "Subtasks",
+					Rollup: &notion.RollupDatabaseQueryFilter{
+						None: &notion.DatabaseQueryFilter{Text: &notion.TextDatabaseQueryFilter{EndsWith: "bug"}},
+					},
+				},
+			},
+		},
+		{
+			name: "sort only",
+			src:  `sort by Priority, created_time desc`,
+			db:   schema,
+			exp: &notion.DatabaseQuery{
+				Sorts: []notion.DatabaseQuerySort{
+					{Property: "Priority", Direction: notion.SortDirAsc},
+					{Timestamp: notion.SortTimeStampCreatedTime, Direction: notion.SortDirDesc},
+				},
+			},
+		},
+		{
+			name: "empty query",
+			src:  "  ",
+			exp:  &notion.DatabaseQuery{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := query.Compile(tt.src, tt.db)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.exp, got); diff != "" {
+				t.Fatalf("query not equal (-exp, +got):\n%v", diff)
+			}
+			if got.Filter != nil {
+				if err := got.Filter.Validate(); err != nil {
+					t.Fatalf("invalid filter: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		src    string
+		db     *notion.Database
+		expErr string
+	}{
+		{src: `Status = "Done`, expErr: "query: column 10: unterminated string"},
+		{src: `Status = "Done" and`, db: schema, expErr: "query: column 20: expected property name, got end of query"},
+		{src: `Status ~ "Done"`, expErr: "query: column 8: unexpected character '~'"},
+		{src: `Status is "Done"`, expErr: `query: column 8: expected operator, got 'is'`},
+		{src: `(Status = "Done"`, db: schema, expErr: "query: column 17: expected ')', got end of query"},
+		{src: `Status = "Done")`, db: schema, expErr: "query: column 16: unexpected ')'"},
+		{src: `Statu = "Done"`, db: schema, expErr: `query: column 1: unknown property "Statu"`},
+		{src: `Status > "Done"`, db: schema, expErr: `query: column 8: operator '>' is not supported for select property "Status"`},
+		{src: `Priority = "high"`, db: schema, expErr: `query: column 12: expected number value for number property "Priority", got "high"`},
+		{src: `"Due date" = "June"`, db: schema, expErr: `query: column 14: invalid date "June", expected YYYY-MM-DD or RFC 3339 format`},
+		{
+			src:    `Subtasks = "Done"`,
+			db:     schema,
+			expErr: `query: column 10: rollup property "Subtasks" only supports number and date conditions, use any, every or none for text conditions`,
+		},
+		{src: `Status any = "Done"`, db: schema, expErr: `query: column 8: 'any' is only supported for rollup properties, "Status" is select property`},
+		{src: `created_time = 1`, expErr: `query: column 16: expected string value for date property "created_time", got '1'`},
+		{src: `Done = true sort by Nope`, db: schema, expErr: `query: column 21: unknown property "Nope"`},
+		{src: `Done = true sort Name`, expErr: "query: column 18: expected 'by', got 'Name'"},
+		{
+			src:    `Done = true and (Done = false or (Done = true and Done = false))`,
+			expErr: "query: column 35: and/or can't be nested more than 2 levels deep",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.src, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := query.Compile(tt.src, tt.db)
+			if err == nil || err.Error() != tt.expErr {
+				t.Fatalf("error not equal (expected: %v, got: %v)", tt.expErr, err)
+			}
+			var qerr *query.Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("expected *query.Error, got %T", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package notion_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kjk/notion"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	t.Parallel()
+
+	limiter := notion.NewRateLimiter(50, 1)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// first token is available immediately, the next 3 take 20ms each
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("rate limit not applied, 4 requests took %v", elapsed)
+	}
+}
+
+func TestRateLimiterContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	limiter := notion.NewRateLimiter(0.1, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error not equal (expected: %v, got: %v)", context.DeadlineExceeded, err)
+	}
+}
+
+func TestRateLimiterSharedByClients(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var times []time.Time
+	httpClient := &http.Client{
+		Transport: &mockRoundtripper{fn: func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     http.StatusText(http.StatusOK),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"id": "foo", "name": "bar"}`)),
+			}, nil
+		}},
+	}
+	opts := notion.ClientOptions{
+		HTTPClient:  httpClient,
+		RateLimiter: notion.NewRateLimiter(100, 1),
+	}
+	clients := []*notion.Client{
+		notion.NewClient("secret-api-key", &opts),
+		notion.NewClient("secret-api-key", &opts),
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(c *notion.Client) {
+				defer wg.Done()
+				_, err := c.GetUser(context.Background(), "foo")
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(c)
+		}
+	}
+	wg.Wait()
+
+	if len(times) != 6 {
+		t.Fatalf("expected 6 requests, got %d", len(times))
+	}
+	first, last := times[0], times[0]
+	for _, tm := range times {
+		if tm.Before(first) {
+			first = tm
+		}
+		if tm.After(last) {
+			last = tm
+		}
+	}
+	// 6 requests at 100 req/sec with burst of 1 take at least 50ms
+	if elapsed := last.Sub(first); elapsed < 45*time.Millisecond {
+		t.Fatalf("rate limit not shared, 6 requests took %v", elapsed)
+	}
+}
@@ -0,0 +1,177 @@
+package notion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how requests that failed with a transient error
+// are retried.
+// See: https://developers.notion.com/reference/errors
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with every
+	// following attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff and Retry-After sent by the
+	// server. 0 means no cap.
+	MaxDelay time.Duration
+	// Jitter is a fraction (0..1) of the delay that is randomized, to avoid
+	// many clients retrying at the same time.
+	Jitter float64
+	// RetryableCodes are the APIError.Code values that are retried
+	// e.g. "rate_limited".
+	RetryableCodes []string
+}
+
+// DefaultRetryPolicy returns a policy that retries rate limited, conflicting
+// and server errors up to 5 times.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		RetryableCodes: []string{
+			"rate_limited",
+			"conflict_error",
+			"internal_server_error",
+			"service_unavailable",
+		},
+	}
+}
+
+func (p *RetryPolicy) shouldRetry(err error, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range p.RetryableCodes {
+		if code == apiErr.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before the next attempt. Retry-After sent
+// by the server takes precedence over the exponential backoff. Both are
+// capped by MaxDelay, or by the largest time.Duration if it's 0.
+func (p *RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	max := p.MaxDelay
+	if max <= 0 {
+		max = math.MaxInt64
+	}
+	if retryAfter > 0 {
+		if retryAfter > max {
+			return max
+		}
+		return retryAfter
+	}
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < max; i++ {
+		if d > max/2 {
+			d = max
+			break
+		}
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	if p.Jitter > 0 {
+		// spread the delay over [d - d*jitter, d + d*jitter]
+		spread := float64(d) * p.Jitter
+		f := float64(d) - spread + rand.Float64()*2*spread
+		if f >= float64(max) {
+			return max
+		}
+		d = time.Duration(f)
+	}
+	return d
+}
+
+// retryCanceledError is returned when ctx is done while waiting to retry
+// a request that failed with err. errors.As finds err, errors.Is also
+// matches ctxErr.
+type retryCanceledError struct {
+	err    error
+	ctxErr error
+}
+
+func (e *retryCanceledError) Error() string {
+	return fmt.Sprintf("%v: retry canceled: %v", e.err, e.ctxErr)
+}
+
+func (e *retryCanceledError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryCanceledError) Is(target error) bool {
+	return errors.Is(e.ctxErr, target)
+}
+
+// parseRetryAfter parses Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepCtx waits for d or until ctx is done, whichever comes first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rewindRequest returns a copy of req that can be sent again. The body of
+// POST / PATCH requests is re-created with req.GetBody.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body can't be re-sent")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = body
+	return r, nil
+}
@@ -1,5 +1,7 @@
 package notion
 
+import "strings"
+
 type RichText struct {
 	Type        RichTextType `json:"type,omitempty"`
 	Annotations *Annotations `json:"annotations,omitempty"`
@@ -11,6 +13,30 @@ type RichText struct {
 	Equation  *Equation `json:"equation,omitempty"`
 }
 
+// PlainText returns text of rich text runs, without formatting.
+func PlainText(rts []RichText) string {
+	var sb strings.Builder
+	for _, rt := range rts {
+		sb.WriteString(rt.plainText())
+	}
+	return sb.String()
+}
+
+// plainText returns PlainText, which is only set in API responses,
+// or text of the run.
+func (rt *RichText) plainText() string {
+	if rt.PlainText != "" {
+		return rt.PlainText
+	}
+	switch {
+	case rt.Text != nil:
+		return rt.Text.Content
+	case rt.Equation != nil:
+		return rt.Equation.Expression
+	}
+	return ""
+}
+
 type Equation struct {
 	Expression string `json:"expression"`
 }
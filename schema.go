@@ -0,0 +1,178 @@
+package notion
+
+import "fmt"
+
+// SchemaBuilder builds properties of a database for CreateDatabase and
+// UpdateDatabase e.g.:
+//
+//	props, err := notion.NewSchema().
+//		Title("Name").
+//		Select("Status",
+//			notion.SelectOptions{Name: "To do", Color: notion.ColorRed},
+//			notion.SelectOptions{Name: "Done", Color: notion.ColorGreen}).
+//		Number("Price", notion.NumberFormatDollar).
+//		Relation("Project", projectsDatabaseID).
+//		Build()
+type SchemaBuilder struct {
+	props DatabaseProperties
+	err   error
+}
+
+// NewSchema returns an empty SchemaBuilder.
+func NewSchema() *SchemaBuilder {
+	return &SchemaBuilder{props: DatabaseProperties{}}
+}
+
+// Property adds a property. Use the methods for a given property type
+// instead, when possible.
+func (b *SchemaBuilder) Property(name string, prop DatabaseProperty) *SchemaBuilder {
+	if _, ok := b.props[name]; ok && b.err == nil {
+		b.err = fmt.Errorf("property %q is defined more than once", name)
+	}
+	b.props[name] = prop
+	return b
+}
+
+// Build returns the properties after validating them.
+func (b *SchemaBuilder) Build() (DatabaseProperties, error) {
+	if b.err != nil {
+		return nil, fmt.Errorf("notion: invalid schema: %w", b.err)
+	}
+	for _, name := range sortedPropertyNames(b.props) {
+		if err := b.props[name].Validate(); err != nil {
+			return nil, fmt.Errorf("notion: invalid schema: property %q: %w", name, err)
+		}
+	}
+	return b.props, nil
+}
+
+func (b *SchemaBuilder) typ(name string, typ DatabasePropertyType) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{Type: typ})
+}
+
+// Title adds a title property. A database must have exactly one.
+func (b *SchemaBuilder) Title(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeTitle)
+}
+
+// RichText adds a rich_text property.
+func (b *SchemaBuilder) RichText(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeRichText)
+}
+
+// Date adds a date property.
+func (b *SchemaBuilder) Date(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeDate)
+}
+
+// People adds a people property.
+func (b *SchemaBuilder) People(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypePeople)
+}
+
+// Files adds a files property.
+func (b *SchemaBuilder) Files(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeFiles)
+}
+
+// Checkbox adds a checkbox property.
+func (b *SchemaBuilder) Checkbox(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeCheckbox)
+}
+
+// URL adds a url property.
+func (b *SchemaBuilder) URL(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeURL)
+}
+
+// Email adds an email property.
+func (b *SchemaBuilder) Email(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeEmail)
+}
+
+// PhoneNumber adds a phone_number property.
+func (b *SchemaBuilder) PhoneNumber(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypePhoneNumber)
+}
+
+// CreatedTime adds a created_time property, set by Notion.
+func (b *SchemaBuilder) CreatedTime(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeCreatedTime)
+}
+
+// CreatedBy adds a created_by property, set by Notion.
+func (b *SchemaBuilder) CreatedBy(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeCreatedBy)
+}
+
+// LastEditedTime adds a last_edited_time property, set by Notion.
+func (b *SchemaBuilder) LastEditedTime(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeLastEditedTime)
+}
+
+// LastEditedBy adds a last_edited_by property, set by Notion.
+func (b *SchemaBuilder) LastEditedBy(name string) *SchemaBuilder {
+	return b.typ(name, DBPropTypeLastEditedBy)
+}
+
+// Number adds a number property displayed in a given format.
+func (b *SchemaBuilder) Number(name string, format NumberFormat) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{
+		Type:   DBPropTypeNumber,
+		Number: &NumberMetadata{Format: format},
+	})
+}
+
+// Select adds a select property with options.
+func (b *SchemaBuilder) Select(name string, options ...SelectOptions) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{
+		Type:   DBPropTypeSelect,
+		Select: &SelectMetadata{Options: selectOptions(options)},
+	})
+}
+
+// MultiSelect adds a multi_select property with options.
+func (b *SchemaBuilder) MultiSelect(name string, options ...SelectOptions) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{
+		Type:        DBPropTypeMultiSelect,
+		MultiSelect: &SelectMetadata{Options: selectOptions(options)},
+	})
+}
+
+// selectOptions returns options, or an empty slice for no options because
+// Notion rejects "options": null.
+func selectOptions(options []SelectOptions) []SelectOptions {
+	if options == nil {
+		return []SelectOptions{}
+	}
+	return options
+}
+
+// Formula adds a formula property.
+func (b *SchemaBuilder) Formula(name, expression string) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{
+		Type:    DBPropTypeFormula,
+		Formula: &FormulaMetadata{Expression: expression},
+	})
+}
+
+// Relation adds a relation property to pages of database with a given ID.
+func (b *SchemaBuilder) Relation(name, databaseID string) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{
+		Type:     DBPropTypeRelation,
+		Relation: &RelationMetadata{DatabaseID: databaseID},
+	})
+}
+
+// Rollup adds a rollup property, calculating function (e.g. "sum") of
+// rollupProp of pages related by relationProp.
+func (b *SchemaBuilder) Rollup(name, relationProp, rollupProp, function string) *SchemaBuilder {
+	return b.Property(name, DatabaseProperty{
+		Type: DBPropTypeRollup,
+		Rollup: &RollupMetadata{
+			RelationPropName: relationProp,
+			RollupPropName:   rollupProp,
+			Function:         function,
+		},
+	})
+}